@@ -0,0 +1,110 @@
+package log
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+)
+
+// AdminHandler is a http.Handler that exposes the runtime log configuration.
+//
+//	GET  returns the current level and per-module levels, e.g. {"level":"info","levels":{"db.sql":"debug"}}
+//	PUT  changes the configuration by JSON body or form values, e.g.
+//	     curl -X PUT -d '{"level":"debug","levels":{"db.*":"trace"},"rotate":true}' http://localhost/log
+//	     curl -X PUT -d level=debug http://localhost/log
+type AdminHandler struct {
+	// Logger specifies the logger to configure, uses the Default logger if nil.
+	Logger *Logger
+
+	// Registry specifies the level registry of modules, uses DefaultLevelRegistry if nil.
+	Registry *LevelRegistry
+}
+
+// Admin returns an AdminHandler of DefaultLogger and DefaultLevelRegistry.
+func Admin() http.Handler {
+	return &AdminHandler{}
+}
+
+type adminPayload struct {
+	Level  *Level           `json:"level,omitempty"`
+	Levels map[string]Level `json:"levels,omitempty"`
+	Rotate bool             `json:"rotate,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *AdminHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger, registry := h.Logger, h.Registry
+	if logger == nil {
+		logger = Default()
+	}
+	if registry == nil {
+		registry = DefaultLevelRegistry
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(rw)
+
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var payload adminPayload
+		if err := h.decode(req, &payload); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			enc.Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		// validates all patterns before applying any of them
+		for pattern := range payload.Levels {
+			if _, err := path.Match(pattern, ""); err != nil {
+				rw.WriteHeader(http.StatusBadRequest)
+				enc.Encode(map[string]string{"error": err.Error()})
+				return
+			}
+		}
+		if payload.Level != nil {
+			logger.SetLevel(*payload.Level)
+		}
+		for pattern, level := range payload.Levels {
+			registry.SetLevel(pattern, level)
+		}
+		if payload.Rotate {
+			if r, ok := logger.Writer.(interface{ Rotate() error }); ok {
+				if err := r.Rotate(); err != nil {
+					rw.WriteHeader(http.StatusInternalServerError)
+					enc.Encode(map[string]string{"error": err.Error()})
+					return
+				}
+			}
+		}
+	default:
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		enc.Encode(map[string]string{"error": "only GET and PUT are supported"})
+		return
+	}
+
+	level := logger.GetLevel()
+	enc.Encode(adminPayload{
+		Level:  &level,
+		Levels: registry.Levels(),
+	})
+}
+
+func (h *AdminHandler) decode(req *http.Request, payload *adminPayload) error {
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != "application/x-www-form-urlencoded" {
+		return json.NewDecoder(req.Body).Decode(payload)
+	}
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	if s := req.Form.Get("level"); s != "" {
+		payload.Level = new(Level)
+		if err := payload.Level.UnmarshalText([]byte(s)); err != nil {
+			return err
+		}
+	}
+	payload.Rotate = req.Form.Get("rotate") == "true"
+	return nil
+}
+
+var _ http.Handler = (*AdminHandler)(nil)
@@ -0,0 +1,69 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testRotateWriter struct {
+	IOWriter
+	rotated int
+}
+
+func (w *testRotateWriter) Rotate() error {
+	w.rotated++
+	return nil
+}
+
+func TestAdminHandler(t *testing.T) {
+	w := &testRotateWriter{}
+	logger := Logger{Level: InfoLevel, Writer: w}
+	registry := &LevelRegistry{}
+	registry.Register(logger.Named("db"))
+
+	h := &AdminHandler{Logger: &logger, Registry: registry}
+
+	serve := func(method, contentType, body string) (int, string) {
+		req := httptest.NewRequest(method, "http://example.com/log", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code, strings.TrimSpace(rec.Body.String())
+	}
+
+	if code, body := serve(http.MethodGet, "", ""); code != http.StatusOK || body != `{"level":"info","levels":{"db":"info"}}` {
+		t.Errorf("admin handler get mismatch: %d %s", code, body)
+	}
+
+	code, body := serve(http.MethodPut, "application/json", `{"level":"debug","levels":{"d*":"trace"},"rotate":true}`)
+	if code != http.StatusOK || body != `{"level":"debug","levels":{"db":"trace"}}` {
+		t.Errorf("admin handler put mismatch: %d %s", code, body)
+	}
+	if w.rotated != 1 {
+		t.Errorf("admin handler should rotate writer")
+	}
+
+	if code, body := serve(http.MethodPut, "application/x-www-form-urlencoded; charset=utf-8", "level=warn"); code != http.StatusOK || !strings.Contains(body, `"level":"warn"`) {
+		t.Errorf("admin handler put form mismatch: %d %s", code, body)
+	}
+
+	if code, _ := serve(http.MethodPut, "application/json", `{"level":"verbose"}`); code != http.StatusBadRequest {
+		t.Errorf("admin handler put bad level should fail: %d", code)
+	}
+
+	code, body = serve(http.MethodPut, "application/json", `{"level":"error","levels":{"db":"debug","[":"info"}}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("admin handler put bad pattern should fail: %d %s", code, body)
+	}
+	if code, body := serve(http.MethodGet, "", ""); body != `{"level":"warn","levels":{"db":"trace"}}` {
+		t.Errorf("admin handler should not apply the levels of bad request: %d %s", code, body)
+	}
+
+	if code, _ := serve(http.MethodDelete, "", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("admin handler delete should fail: %d", code)
+	}
+}
@@ -0,0 +1,234 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// AlertFuncs is the template functions of AlertWriter, "json" quotes a string as json.
+var AlertFuncs = template.FuncMap{
+	"json": alertJSON,
+}
+
+// AlertTemplate is the default body template of AlertWriter, which is compatible with
+// the incoming webhooks of Slack and Microsoft Teams.
+var AlertTemplate = template.Must(template.New("alert").Funcs(AlertFuncs).Parse(`{"text":{{json (printf "[%s] %s %s" .Level .Caller .Message)}}}`))
+
+// AlertWriter is an Writer that posts the entries matching Level and Filter to a webhook,
+// e.g. Slack, Teams or PagerDuty Events API, so that fatal events page humans directly.
+// The entries are posted in background by a bounded queue, and the pending ones are posted on
+// Close, so keep Level high and Throttle long enough.
+type AlertWriter struct {
+	// URL is the webhook url.
+	URL string
+
+	// Level is the minimum level of entries to alert, the default is ErrorLevel.
+	Level Level
+
+	// Filter specifies an optional predicate of the entries to alert, e.g. by fields.
+	Filter func(args *FormatterArgs) bool
+
+	// Template specifies the template of request body executed with *FormatterArgs, see
+	// AlertFuncs for the template functions. The default is AlertTemplate.
+	Template *template.Template
+
+	// Headers specifies the extra http headers of requests, e.g. authorization.
+	Headers map[string]string
+
+	// Throttle is the min interval of alerts with the same message, the default is 1 minute.
+	Throttle time.Duration
+
+	// Client specifies the http client of posting, using a client with 5s timeout if nil.
+	Client *http.Client
+
+	// QueueSize is the max number of alerts waiting for posting, the default size is 64.
+	// The alerts beyond it are dropped.
+	QueueSize int
+
+	mu      sync.Mutex
+	once    sync.Once
+	alerts  map[string]time.Time
+	queue   chan []byte
+	closed  chan struct{}
+	closing bool
+	pending int
+	dropped int64
+	err     error
+}
+
+// Healthy implements HealthChecker, returns the error of last posting.
+func (w *AlertWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Dropped returns the number of alerts dropped by the full queue.
+func (w *AlertWriter) Dropped() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// WaitFlush waits until the queued alerts are posted.
+func (w *AlertWriter) WaitFlush() {
+	for {
+		w.mu.Lock()
+		pending := w.pending
+		w.mu.Unlock()
+		if pending == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Close implements io.Closer, posts the queued alerts and stops the posting goroutine. The
+// alerts written after Close are posted synchronously.
+func (w *AlertWriter) Close() (err error) {
+	w.init()
+	unregisterWriter(w)
+	w.mu.Lock()
+	if !w.closing {
+		w.closing = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.closed
+	return w.Healthy()
+}
+
+// WriteEntry implements Writer.
+func (w *AlertWriter) WriteEntry(e *Entry) (n int, err error) {
+	level := w.Level
+	if level == 0 {
+		level = ErrorLevel
+	}
+	if e.Level < level {
+		return
+	}
+
+	b := bbget()
+	defer bbput(b)
+	b.B = append(b.B, e.buf...)
+
+	var args FormatterArgs
+	parseFormatterArgs(b.B, &args)
+	if w.Filter != nil && !w.Filter(&args) {
+		return
+	}
+
+	throttle := w.Throttle
+	if throttle <= 0 {
+		throttle = time.Minute
+	}
+
+	w.mu.Lock()
+	now := timeNow()
+	if now.Sub(w.alerts[args.Message]) < throttle {
+		w.mu.Unlock()
+		return
+	}
+	if w.alerts == nil {
+		w.alerts = make(map[string]time.Time)
+	}
+	for message, t := range w.alerts {
+		if now.Sub(t) >= throttle {
+			delete(w.alerts, message)
+		}
+	}
+	w.alerts[string([]byte(args.Message))] = now
+	w.mu.Unlock()
+
+	tmpl := w.Template
+	if tmpl == nil {
+		tmpl = AlertTemplate
+	}
+	var body bytes.Buffer
+	if err = tmpl.Execute(&body, &args); err != nil {
+		return
+	}
+
+	w.init()
+	w.mu.Lock()
+	if w.closing {
+		w.mu.Unlock()
+		err = w.post(body.Bytes())
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+		return len(e.buf), err
+	}
+	select {
+	case w.queue <- body.Bytes():
+		w.pending++
+	default:
+		w.dropped++
+	}
+	w.mu.Unlock()
+
+	return len(e.buf), nil
+}
+
+func (w *AlertWriter) init() {
+	w.once.Do(func() {
+		size := w.QueueSize
+		if size <= 0 {
+			size = 64
+		}
+		w.queue = make(chan []byte, size)
+		w.closed = make(chan struct{})
+		go func() {
+			defer close(w.closed)
+			for body := range w.queue {
+				err := w.post(body)
+				w.mu.Lock()
+				w.err = err
+				w.pending--
+				w.mu.Unlock()
+			}
+		}()
+	})
+}
+
+func (w *AlertWriter) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return errors.New("log: alert post failed with status " + resp.Status)
+	}
+	return nil
+}
+
+func alertJSON(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+var _ Writer = (*AlertWriter)(nil)
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestAlertWriter(t *testing.T) {
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("alert writer request mismatch: %+v", req.Header)
+		}
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies <- string(b)
+	}))
+	defer server.Close()
+
+	w := &AlertWriter{
+		URL:      server.URL,
+		Throttle: time.Hour,
+		Filter: func(args *FormatterArgs) bool {
+			return args.Get("page") != "false"
+		},
+	}
+
+	logger := Logger{Writer: w, ExitFunc: func(int) {}}
+	logger.Warn().Msg("hello warn")
+	logger.Error().Str("page", "false").Msg("hello filtered")
+	logger.Error().Msg("hello \"alert\"")
+	logger.Error().Msg("hello \"alert\"")
+	logger.Fatal().Msg("hello fatal")
+	w.Close()
+
+	if err := w.Healthy(); err != nil {
+		t.Errorf("alert writer should be healthy: %+v", err)
+	}
+	close(bodies)
+
+	var got []string
+	for body := range bodies {
+		got = append(got, body)
+	}
+	if len(got) != 2 || got[0] != `{"text":"[error]  hello \"alert\""}` || got[1] != `{"text":"[fatal]  hello fatal"}` {
+		t.Errorf("alert writer bodies mismatch: %q", got)
+	}
+
+	w = &AlertWriter{
+		URL:      server.URL + "/404",
+		Template: template.Must(template.New("pagerduty").Funcs(AlertFuncs).Parse(`{"event_action":"trigger","payload":{"summary":{{json .Message}}}}`)),
+	}
+	server.Config.Handler = http.NotFoundHandler()
+	logger.Writer = w
+	logger.Error().Msg("hello 404")
+	w.Close()
+	if w.Healthy() == nil {
+		t.Errorf("alert writer should report the error of posting")
+	}
+}
+
+func TestAlertWriterQueue(t *testing.T) {
+	received := make(chan string, 10)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		received <- string(b)
+		<-release
+	}))
+	defer server.Close()
+
+	w := &AlertWriter{
+		URL:       server.URL,
+		QueueSize: 1,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Error().Msg("hello posting")
+	if body := <-received; body != `{"text":"[error]  hello posting"}` {
+		t.Errorf("alert writer body mismatch: %s", body)
+	}
+	logger.Error().Msg("hello queued")
+	logger.Error().Msg("hello dropped")
+	if n := w.Dropped(); n != 1 {
+		t.Errorf("alert writer should drop the alerts beyond the queue: %d", n)
+	}
+
+	close(release)
+	if err := w.Close(); err != nil {
+		t.Errorf("alert writer close error: %+v", err)
+	}
+	if body := <-received; body != `{"text":"[error]  hello queued"}` {
+		t.Errorf("alert writer should post the queued alerts on close: %s", body)
+	}
+
+	logger.Error().Msg("hello closed")
+	select {
+	case body := <-received:
+		if body != `{"text":"[error]  hello closed"}` {
+			t.Errorf("alert writer body mismatch: %s", body)
+		}
+	default:
+		t.Errorf("alert writer should post the alerts after close synchronously")
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("alert writer close again error: %+v", err)
+	}
+}
@@ -2,6 +2,8 @@ package log
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // AsyncWriter is an Writer that writes asynchronously.
@@ -15,41 +17,116 @@ type AsyncWriter struct {
 	once    sync.Once
 	ch      chan *Entry
 	chClose chan error
+	pending int64
+	closing sync.Once
 }
 
-// Close implements io.Closer, and closes the underlying Writer.
+// WaitFlush waits until the queued entries are written to the underlying Writer.
+func (w *AsyncWriter) WaitFlush() {
+	for atomic.LoadInt64(&w.pending) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Flush waits until the queued entries are written, and flushes the underlying Writer.
+func (w *AsyncWriter) Flush() (err error) {
+	w.WaitFlush()
+	if f, ok := w.Writer.(interface{ Flush() error }); ok {
+		err = f.Flush()
+	}
+	return
+}
+
+// Rotate waits until the queued entries are written, and rotates the underlying Writer,
+// e.g. FileWriter.
+func (w *AsyncWriter) Rotate() (err error) {
+	w.WaitFlush()
+	if r, ok := w.Writer.(interface{ Rotate() error }); ok {
+		err = r.Rotate()
+	}
+	return
+}
+
+func (w *AsyncWriter) underlying() interface{} {
+	return w.Writer
+}
+
+// Close implements io.Closer, and stops the goroutine after the queued entries are written.
+// The underlying Writer is not closed, e.g. it may be os.Stdout or shared by other loggers.
 func (w *AsyncWriter) Close() (err error) {
-	w.ch <- nil
-	err = <-w.chClose
+	w.init()
+	w.closing.Do(func() {
+		unregisterWriter(w)
+		w.ch <- nil
+		err = <-w.chClose
+	})
 	return
 }
 
 // WriteEntry implements Writer.
 func (w *AsyncWriter) WriteEntry(e *Entry) (int, error) {
+	w.init()
+
+	// cheating to logger pool
+	entry := epool.Get().(*Entry)
+	entry.Level = e.Level
+	entry.loggerFiles = append(entry.loggerFiles[:0], e.loggerFiles...)
+	entry.buf, e.buf = e.buf, entry.buf
+	entry.humans, e.humans = e.humans, entry.humans
+	n := len(entry.buf)
+
+	atomic.AddInt64(&w.pending, 1)
+	w.ch <- entry
+	return n, nil
+}
+
+func (w *AsyncWriter) init() {
 	w.once.Do(func() {
 		// channels
 		w.ch = make(chan *Entry, w.ChannelSize)
 		w.chClose = make(chan error)
 		go func() {
 			var err error
+			batcher, _ := w.Writer.(EntryBatchWriter)
+			batch := make([]*Entry, 0, 64)
 			for entry := range w.ch {
 				if entry == nil {
 					break
 				}
-				_, err = w.Writer.WriteEntry(entry)
-				epool.Put(entry)
+				if batcher == nil {
+					_, err = w.Writer.WriteEntry(entry)
+					putEntry(entry)
+					atomic.AddInt64(&w.pending, -1)
+					continue
+				}
+				// drain the queued entries to write them in a batch
+				batch = append(batch[:0], entry)
+				closing := false
+			drain:
+				for len(batch) < cap(batch) {
+					select {
+					case entry = <-w.ch:
+						if entry == nil {
+							closing = true
+							break drain
+						}
+						batch = append(batch, entry)
+					default:
+						break drain
+					}
+				}
+				_, err = batcher.WriteEntries(batch)
+				for _, entry := range batch {
+					putEntry(entry)
+				}
+				atomic.AddInt64(&w.pending, -int64(len(batch)))
+				if closing {
+					break
+				}
 			}
 			w.chClose <- err
 		}()
 	})
-
-	// cheating to logger pool
-	entry := epool.Get().(*Entry)
-	entry.Level = e.Level
-	entry.buf, e.buf = e.buf, entry.buf
-
-	w.ch <- entry
-	return len(entry.buf), nil
 }
 
 var _ Writer = (*AsyncWriter)(nil)
@@ -1,9 +1,13 @@
 package log
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestAsyncWriterZero(t *testing.T) {
@@ -48,3 +52,125 @@ func BenchmarkAsyncWriter(b *testing.B) {
 		}
 	})
 }
+
+func TestAsyncWriterLoggerFiles(t *testing.T) {
+	var heartbeat, other bytes.Buffer
+
+	w := &AsyncWriter{
+		ChannelSize: 16,
+		Writer: &MultiFileWriter{
+			Writes: map[string]Writer{
+				"heartbeat": IOWriter{&heartbeat},
+				"default":   IOWriter{&other},
+			},
+		},
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().To("heartbeat").Msg("hello heartbeat")
+	logger.Info().Msg("hello default")
+	w.Close()
+
+	if s := heartbeat.String(); !strings.Contains(s, "hello heartbeat") || strings.Contains(s, "hello default") {
+		t.Errorf("async writer should keep the destinations of entry: %s", s)
+	}
+}
+
+type testBatchWriter struct {
+	mu      sync.Mutex
+	batches []int
+	buf     bytes.Buffer
+}
+
+func (w *testBatchWriter) WriteEntry(e *Entry) (int, error) {
+	return w.WriteEntries([]*Entry{e})
+}
+
+func (w *testBatchWriter) WriteEntries(entries []*Entry) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches = append(w.batches, len(entries))
+	for _, e := range entries {
+		m, _ := w.buf.Write(e.buf)
+		n += m
+	}
+	return
+}
+
+func TestAsyncWriterBatch(t *testing.T) {
+	bw := &testBatchWriter{}
+	bw.mu.Lock()
+
+	w := &AsyncWriter{
+		ChannelSize: 128,
+		Writer:      bw,
+	}
+
+	logger := Logger{Writer: w}
+	for i := 0; i < 100; i++ {
+		logger.Info().Int("i", i).Msg("hello batch")
+	}
+	// let the entries queue up behind the blocked writer
+	time.Sleep(50 * time.Millisecond)
+	bw.mu.Unlock()
+	w.Close()
+
+	if n := strings.Count(bw.buf.String(), "hello batch"); n != 100 {
+		t.Errorf("async writer batch count mismatch: %d", n)
+	}
+	if len(bw.batches) >= 100 {
+		t.Errorf("async writer should write the entries in batches: %v", bw.batches)
+	}
+	for _, n := range bw.batches {
+		if n > 64 {
+			t.Errorf("async writer batch too large: %v", bw.batches)
+		}
+	}
+}
+
+func TestIOWriterWriteEntries(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := IOWriter{&buf}.WriteEntries([]*Entry{{buf: []byte("a\n")}, {buf: []byte("b\n")}})
+	if err != nil || n != 4 || buf.String() != "a\nb\n" {
+		t.Errorf("io writer write entries mismatch: %d, %+v, %q", n, err, buf.String())
+	}
+}
+
+func TestAsyncWriterCloseUnused(t *testing.T) {
+	resetWriters()
+	defer resetWriters()
+
+	w := &AsyncWriter{Writer: IOWriter{ioutil.Discard}}
+	RegisterWriter(w)
+
+	done := make(chan error, 1)
+	go func() {
+		err := Close()
+		if err1 := w.Close(); err == nil {
+			err = err1
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("async close error: %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("async writer close should not block before the first write")
+	}
+}
+
+func TestAsyncWriterCloseUnderlying(t *testing.T) {
+	w := &testShutdownWriter{name: "underlying"}
+	async := &AsyncWriter{Writer: w}
+	logger := Logger{Writer: async}
+	logger.Info().Msg("hello async")
+	if err := async.Close(); err != nil {
+		t.Errorf("async close error: %+v", err)
+	}
+	if w.closed != 0 {
+		t.Errorf("async writer should not close the underlying writer")
+	}
+}
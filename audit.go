@@ -0,0 +1,166 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrAuditMismatch is returned by VerifyAudit if an entry was modified, inserted or removed.
+var ErrAuditMismatch = errors.New("log: audit hash mismatch")
+
+// AuditWriter is an Writer that appends a "hash" field to each entry for tamper evidence,
+// the hash is the SHA-256 (or HMAC-SHA256 if Key is set) of the previous hash and the entry,
+// e.g. {"time":"2019-07-10T05:35:54.277Z","level":"info","message":"hello","hash":"9f86d0..."}.
+// Use VerifyAudit to verify the hash chain of an audit log file.
+type AuditWriter struct {
+	// Key specifies an optional HMAC key, so that the chain can not be recomputed without it.
+	Key []byte
+
+	// Prev specifies the hash of the last entry written before, e.g. returned by VerifyAudit,
+	// to continue the chain of an existing audit log file. The default is starting a new chain.
+	Prev []byte
+
+	// Writer is the output destination. using os.Stderr if empty.
+	Writer io.Writer
+
+	mu   sync.Mutex
+	hash hash.Hash
+	sum  []byte
+}
+
+// Close implements io.Closer, will closes the underlying Writer if not empty.
+func (w *AuditWriter) Close() (err error) {
+	if w.Writer != nil {
+		if closer, ok := w.Writer.(io.Closer); ok {
+			err = closer.Close()
+		}
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *AuditWriter) WriteEntry(e *Entry) (int, error) {
+	return w.Write(e.buf)
+}
+
+// Write implements io.Writer, p must be a json object ending with a newline.
+func (w *AuditWriter) Write(p []byte) (n int, err error) {
+	out := w.Writer
+	if out == nil {
+		out = os.Stderr
+	}
+
+	if len(p) < 3 || p[len(p)-2] != '}' || p[len(p)-1] != '\n' {
+		return out.Write(p)
+	}
+
+	b := bbget()
+	defer bbput(b)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.hash == nil {
+		w.hash = newAuditHash(w.Key)
+		w.sum = append(w.sum[:0], w.Prev...)
+	}
+
+	b.B = append(b.B, p[:len(p)-2]...)
+	sum := auditSum(w.hash, w.sum, b.B)
+	if c := bytes.TrimRight(b.B, " \t\r\n"); len(c) == 0 || c[len(c)-1] != '{' {
+		b.B = append(b.B, ',')
+	}
+	b.B = append(b.B, "\"hash\":\""...)
+	for _, c := range sum {
+		b.B = append(b.B, hex[c>>4], hex[c&0xf])
+	}
+	b.B = append(b.B, '"', '}', '\n')
+
+	if _, err = out.Write(b.B); err != nil {
+		return 0, err
+	}
+	w.sum = append(w.sum[:0], sum...)
+
+	return len(p), nil
+}
+
+func newAuditHash(key []byte) hash.Hash {
+	if key != nil {
+		return hmac.New(sha256.New, key)
+	}
+	return sha256.New()
+}
+
+func auditSum(h hash.Hash, prev, data []byte) []byte {
+	h.Reset()
+	h.Write(prev)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// VerifyAudit verifies the hash chain of the audit log file written by AuditWriter with key,
+// and returns the hash of the last entry. The error wraps ErrAuditMismatch with the line number
+// of the first entry which was modified, inserted or removed.
+//
+// The prev specifies the hash of the last entry before the file, i.e. the counterpart of
+// AuditWriter.Prev, it is nil for the first file of chain. The chain continues across the
+// rotations of FileWriter, so the rotated files are verified in order, e.g.
+//
+//	var prev []byte
+//	for _, filename := range filenames {
+//		if prev, err = log.VerifyAudit(filename, key, prev); err != nil {
+//			return err
+//		}
+//	}
+func VerifyAudit(filename string, key, prev []byte) (last []byte, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	h := newAuditHash(key)
+	last = prev
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(data) == 0 && err == io.EOF {
+			return last, nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		data = bytes.TrimRight(data, "\r\n")
+		i := bytes.LastIndex(data, []byte("\"hash\":\""))
+		if i < 1 || len(data)-i != len("\"hash\":\"\"}")+2*sha256.Size {
+			return nil, fmt.Errorf("%w at line %d", ErrAuditMismatch, line)
+		}
+		// the hash field follows a comma, or the brace of an empty object.
+		j := i
+		if data[i-1] == ',' {
+			j = i - 1
+		} else if c := bytes.TrimRight(data[:i], " \t\r\n"); c[len(c)-1] != '{' {
+			return nil, fmt.Errorf("%w at line %d", ErrAuditMismatch, line)
+		}
+
+		sum := auditSum(h, last, data[:j])
+		hexsum := data[i+len("\"hash\":\"") : len(data)-2]
+		for j, c := range sum {
+			if hexsum[2*j] != hex[c>>4] || hexsum[2*j+1] != hex[c&0xf] {
+				return nil, fmt.Errorf("%w at line %d", ErrAuditMismatch, line)
+			}
+		}
+		last = sum
+	}
+}
+
+var _ Writer = (*AuditWriter)(nil)
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-audit")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "audit.log")
+	key := []byte("secret")
+
+	file, _ := os.Create(filename)
+	logger := Logger{Writer: &AuditWriter{Key: key, Writer: file}}
+	logger.Info().Str("user", "alice").Msg("login")
+	logger.Info().Str("user", "bob").Msg("login")
+	file.Close()
+
+	last, err := VerifyAudit(filename, key, nil)
+	if err != nil || len(last) != 32 {
+		t.Fatalf("verify audit error: %+v", err)
+	}
+
+	// continue the chain of the existing file
+	file, _ = os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	logger = Logger{Writer: &AuditWriter{Key: key, Prev: last, Writer: file}}
+	logger.Warn().Str("user", "eve").Msg("logout")
+	file.Close()
+
+	if _, err := VerifyAudit(filename, key, nil); err != nil {
+		t.Fatalf("verify continued audit error: %+v", err)
+	}
+	if _, err := VerifyAudit(filename, []byte("wrong"), nil); !errors.Is(err, ErrAuditMismatch) {
+		t.Errorf("verify audit with wrong key should fail: %+v", err)
+	}
+
+	data, _ := ioutil.ReadFile(filename)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, tampered := range [][]byte{
+		bytes.Replace(data, []byte("bob"), []byte("eve"), 1),
+		bytes.Join([][]byte{lines[0], lines[2]}, nil),
+		append(append([]byte{}, lines[1]...), lines[0]...),
+	} {
+		ioutil.WriteFile(filename, tampered, 0644)
+		if _, err := VerifyAudit(filename, key, nil); !errors.Is(err, ErrAuditMismatch) || !strings.HasSuffix(err.Error(), "line 1") && !strings.HasSuffix(err.Error(), "line 2") {
+			t.Errorf("verify tampered audit %d should fail: %+v", i, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	w := &AuditWriter{Writer: &buf}
+	w.Write([]byte("not json\n"))
+	if buf.String() != "not json\n" {
+		t.Errorf("audit writer should write non-json as is: %s", buf.String())
+	}
+}
+
+func TestAuditWriterEmptyObject(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-audit")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "audit.log")
+	file, _ := os.Create(filename)
+	w := &AuditWriter{Writer: file}
+	for _, s := range []string{"{}\n", `{"a":1}` + "\n"} {
+		if n, err := io.Copy(w, strings.NewReader(s)); err != nil || n != int64(len(s)) {
+			t.Errorf("audit writer copy error: %d, %+v", n, err)
+		}
+	}
+	file.Close()
+
+	data, _ := ioutil.ReadFile(filename)
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		if !json.Valid(line) {
+			t.Errorf("audit writer should write valid json: %s", line)
+		}
+	}
+	if _, err := VerifyAudit(filename, nil, nil); err != nil {
+		t.Errorf("verify audit error: %+v", err)
+	}
+}
+
+func TestAuditWriterRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-audit")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	key := []byte("secret")
+	var filenames []string
+	file := &FileWriter{
+		Filename: filepath.Join(dir, "audit.log"),
+		BackupName: func(time.Time) string {
+			filenames = append(filenames, filepath.Join(dir, fmt.Sprintf("audit.%d.log", len(filenames)+1)))
+			return filenames[len(filenames)-1]
+		},
+	}
+	logger := Logger{Writer: &AuditWriter{Key: key, Writer: file}}
+	for i := 0; i < 3; i++ {
+		logger.Info().Int("n", i).Msg("before rotation")
+		logger.Info().Int("n", i).Msg("after rotation")
+		file.Rotate()
+	}
+	file.Close()
+
+	if len(filenames) < 3 {
+		t.Fatalf("audit writer should rotate files: %v", filenames)
+	}
+	var prev []byte
+	for _, filename := range filenames {
+		data, _ := ioutil.ReadFile(filename)
+		if len(data) == 0 {
+			continue
+		}
+		if prev, err = VerifyAudit(filename, key, prev); err != nil {
+			t.Fatalf("verify rotated audit %s error: %+v", filename, err)
+		}
+	}
+	if _, err := VerifyAudit(filenames[1], key, nil); !errors.Is(err, ErrAuditMismatch) {
+		t.Errorf("verify rotated audit without prev should fail: %+v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// coarseClockInterval is the update interval of the coarse clock.
+const coarseClockInterval = time.Millisecond
+
+// coarseClock holds the cached wall time in unix nanoseconds, nanos is the first field to be
+// 64-bit aligned for the atomic operations on 386 and ARM.
+var coarseClock struct {
+	nanos int64
+	once  sync.Once
+}
+
+// coarseWalltime returns the cached wall time which is updated every millisecond by a
+// background goroutine, the goroutine starts on first call and runs for the process lifetime.
+func coarseWalltime() (sec int64, nsec int32) {
+	coarseClock.once.Do(func() {
+		sec, nsec := walltime()
+		atomic.StoreInt64(&coarseClock.nanos, sec*1e9+int64(nsec))
+		go func() {
+			ticker := time.NewTicker(coarseClockInterval)
+			for range ticker.C {
+				sec, nsec := walltime()
+				atomic.StoreInt64(&coarseClock.nanos, sec*1e9+int64(nsec))
+			}
+		}()
+	})
+	nanos := atomic.LoadInt64(&coarseClock.nanos)
+	return nanos / 1e9, int32(nanos % 1e9)
+}
+
+// walltime returns the wall time of logger, it is cached by the coarse clock if CoarseClock is set.
+func (l *Logger) walltime() (sec int64, nsec int32) {
+	if l.CoarseClock {
+		return coarseWalltime()
+	}
+	return walltime()
+}
+
+// now returns the current local time of logger, see walltime.
+func (l *Logger) now() time.Time {
+	if l.CoarseClock {
+		sec, nsec := coarseWalltime()
+		return time.Unix(sec, int64(nsec))
+	}
+	return timeNow()
+}
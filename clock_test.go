@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestLoggerCoarseClock(t *testing.T) {
+	for _, format := range []string{"", TimeFormatUnixMs, time.RFC3339Nano} {
+		var buf bytes.Buffer
+		logger := Logger{
+			TimeFormat:  format,
+			CoarseClock: true,
+			Writer:      IOWriter{&buf},
+		}
+
+		before := time.Now().Add(-10 * time.Millisecond)
+		logger.Info().Msg("hello coarse clock")
+		after := time.Now().Add(10 * time.Millisecond)
+
+		var entry struct {
+			Time json.RawMessage `json:"time"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("json unmarshal error: %+v, %s", err, buf.Bytes())
+		}
+		typ := byte('n')
+		if entry.Time[0] == '"' {
+			typ = 's'
+		}
+		ts, ok := jsonParseTime(entry.Time, typ)
+		if !ok {
+			t.Fatalf("parse time %s error", entry.Time)
+		}
+		if ts.Before(before) || ts.After(after) {
+			t.Errorf("coarse clock time %s out of range [%s, %s]", ts, before, after)
+		}
+	}
+}
+
+func TestCoarseWalltime(t *testing.T) {
+	sec, nsec := coarseWalltime()
+	start := time.Unix(sec, int64(nsec))
+	time.Sleep(20 * time.Millisecond)
+	sec, nsec = coarseWalltime()
+	if d := time.Unix(sec, int64(nsec)).Sub(start); d < 10*time.Millisecond {
+		t.Errorf("coarse clock should advance: %s", d)
+	}
+}
+
+func BenchmarkLoggerCoarseClock(b *testing.B) {
+	logger := Logger{
+		CoarseClock: true,
+		Writer:      IOWriter{ioutil.Discard},
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Msg("hello coarse clock")
+	}
+}
@@ -0,0 +1,331 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Config is the json configuration of a Logger, e.g.
+//
+//	{
+//	  "level": "info",
+//	  "caller": 1,
+//	  "writers": {
+//	    "default": {"type": "file", "filename": "logs/main.log", "max_size": 104857600, "max_backups": 7},
+//	    "heartbeat": {"type": "file", "level": "info", "filename": "logs/heartbeat.log"},
+//	    "console": {"type": "console", "color": true}
+//	  }
+//	}
+//
+// A single "default" writer is used directly, the others are wired into a MultiFileWriter
+// and routed by logger names, see Logger.Named.
+type Config struct {
+	// Level is the level of logger, e.g. "info".
+	Level string `json:"level"`
+
+	// Caller is the caller depth of logger, 0 to disable.
+	Caller int `json:"caller"`
+
+	// TimeField is the time field name of logger.
+	TimeField string `json:"time_field"`
+
+	// TimeFormat is the time format of logger.
+	TimeFormat string `json:"time_format"`
+
+	// Writers is the named writers of logger.
+	Writers map[string]WriterConfig `json:"writers"`
+}
+
+// WriterConfig is the json configuration of a Writer.
+type WriterConfig struct {
+	// Type is one of "file", "console", "stdout", "stderr", "syslog" and "otlp".
+	Type string `json:"type"`
+
+	// Level is the minimum level of writer in MultiFileWriter, see MultiFileWriter.Levels.
+	Level string `json:"level"`
+
+	// Format is the encoding of "file", "stdout" and "stderr" types, one of "json" (default),
+	// "console" and "logfmt", so each writer can use its own format.
+	Format string `json:"format"`
+
+	// Filename, MaxSize, MaxBackups, LocalTime, HostName, ProcessID and EnsureFolder
+	// configure the FileWriter of "file" type.
+	Filename     string `json:"filename"`
+	MaxSize      int64  `json:"max_size"`
+	MaxBackups   int    `json:"max_backups"`
+	LocalTime    bool   `json:"local_time"`
+	HostName     bool   `json:"hostname"`
+	ProcessID    bool   `json:"pid"`
+	EnsureFolder bool   `json:"ensure_folder"`
+
+	// Color configures the ConsoleWriter of "console" type.
+	Color bool `json:"color"`
+
+	// Network, Address and Tag configure the SyslogWriter of "syslog" type.
+	Network string `json:"network"`
+	Address string `json:"address"`
+	Tag     string `json:"tag"`
+
+	// Endpoint configures the OTLPWriter of "otlp" type.
+	Endpoint string `json:"endpoint"`
+}
+
+// NewFromConfig returns a Logger configured by the json config file, see Config.
+// Use WatchConfig to reload it on changes.
+func NewFromConfig(filename string) (*Logger, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	level := InfoLevel
+	if config.Level != "" {
+		if level, err = ParseLevelStrict(config.Level); err != nil {
+			return nil, err
+		}
+	}
+
+	writer, err := config.writer()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := &Logger{
+		Level:      level,
+		Caller:     config.Caller,
+		TimeField:  config.TimeField,
+		TimeFormat: config.TimeFormat,
+		Writer:     &configWriter{},
+	}
+	logger.Writer.(*configWriter).set(writer)
+
+	return logger, nil
+}
+
+// WatchConfig polls the json config file of logger which is returned by NewFromConfig,
+// and reloads its level and writers on changes. The replaced writers are closed.
+func WatchConfig(logger *Logger, filename string, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		var modTime time.Time
+		if fi, err := os.Stat(filename); err == nil {
+			modTime = fi.ModTime()
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			fi, err := os.Stat(filename)
+			if err != nil || fi.ModTime().Equal(modTime) {
+				continue
+			}
+			modTime = fi.ModTime()
+			l, err := NewFromConfig(filename)
+			if err != nil {
+				continue
+			}
+			w, ok := logger.Writer.(*configWriter)
+			if !ok {
+				continue
+			}
+			logger.SetLevel(l.Level)
+			w.set(l.Writer.(*configWriter).get())
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// NewFromEnv returns a Logger configured by environment variables, e.g.
+//
+//	LOG_LEVEL=debug           the level of logger, "info" if empty.
+//	LOG_FORMAT=console        one of "json", "console" and "logfmt", "json" if empty.
+//	LOG_FILE=logs/main.log    the output file, "stderr" and "stdout" are accepted, "stderr" if empty.
+//	LOG_MAX_SIZE=104857600    the max size of output file before rotation.
+//	LOG_MAX_BACKUPS=7         the max backups of output file.
+//	LOG_CALLER=1              the caller depth of logger, 0 to disable.
+//	LOG_TIME_FORMAT=Unix      the time format of logger.
+func NewFromEnv() (*Logger, error) {
+	logger := &Logger{
+		Level:      InfoLevel,
+		TimeFormat: os.Getenv("LOG_TIME_FORMAT"),
+	}
+
+	if s := os.Getenv("LOG_LEVEL"); s != "" {
+		if err := logger.Level.UnmarshalText([]byte(s)); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if s := os.Getenv("LOG_CALLER"); s != "" {
+		if logger.Caller, err = strconv.Atoi(s); err != nil {
+			return nil, errors.New("log: invalid LOG_CALLER: " + s)
+		}
+	}
+
+	var out io.Writer
+	switch s := os.Getenv("LOG_FILE"); s {
+	case "", "stderr":
+		out = os.Stderr
+	case "stdout":
+		out = os.Stdout
+	default:
+		fw := &FileWriter{Filename: s, EnsureFolder: true}
+		if s := os.Getenv("LOG_MAX_SIZE"); s != "" {
+			if fw.MaxSize, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return nil, errors.New("log: invalid LOG_MAX_SIZE: " + s)
+			}
+		}
+		if s := os.Getenv("LOG_MAX_BACKUPS"); s != "" {
+			if fw.MaxBackups, err = strconv.Atoi(s); err != nil {
+				return nil, errors.New("log: invalid LOG_MAX_BACKUPS: " + s)
+			}
+		}
+		out = fw
+	}
+
+	switch s := os.Getenv("LOG_FORMAT"); s {
+	case "", "json":
+		if w, ok := out.(Writer); ok {
+			logger.Writer = w
+		} else {
+			logger.Writer = IOWriter{out}
+		}
+	case "console":
+		logger.Writer = &ConsoleWriter{Writer: out}
+	case "logfmt":
+		logger.Writer = &LogfmtWriter{Writer: out}
+	default:
+		return nil, errors.New("log: invalid LOG_FORMAT: " + s)
+	}
+
+	return logger, nil
+}
+
+func (c *Config) writer() (Writer, error) {
+	if len(c.Writers) == 0 {
+		return IOWriter{os.Stderr}, nil
+	}
+
+	writes := make(map[string]Writer, len(c.Writers))
+	levels := make(map[string]Level)
+	for name, wc := range c.Writers {
+		if wc.Level != "" {
+			level, err := ParseLevelStrict(wc.Level)
+			if err != nil {
+				return nil, errors.New("log: writer " + name + ": " + err.Error())
+			}
+			levels[name] = level
+		}
+	}
+	for name, wc := range c.Writers {
+		w, err := wc.writer()
+		if err != nil {
+			return nil, errors.New("log: writer " + name + ": " + err.Error())
+		}
+		writes[name] = w
+	}
+
+	if w, ok := writes["default"]; ok && len(writes) == 1 && len(levels) == 0 {
+		return w, nil
+	}
+
+	return &MultiFileWriter{Writes: writes, Levels: levels}, nil
+}
+
+func (c *WriterConfig) writer() (Writer, error) {
+	var out io.Writer
+	switch c.Type {
+	case "file":
+		out = &FileWriter{
+			Filename:     c.Filename,
+			MaxSize:      c.MaxSize,
+			MaxBackups:   c.MaxBackups,
+			LocalTime:    c.LocalTime,
+			HostName:     c.HostName,
+			ProcessID:    c.ProcessID,
+			EnsureFolder: c.EnsureFolder,
+		}
+	case "console":
+		return &ConsoleWriter{ColorOutput: c.Color}, nil
+	case "stdout":
+		out = os.Stdout
+	case "stderr", "":
+		out = os.Stderr
+	case "syslog":
+		return &SyslogWriter{Network: c.Network, Address: c.Address, Tag: c.Tag}, nil
+	case "otlp":
+		return &OTLPWriter{Endpoint: c.Endpoint}, nil
+	default:
+		return nil, errors.New("unknown type " + c.Type)
+	}
+
+	switch c.Format {
+	case "", "json":
+		if w, ok := out.(Writer); ok {
+			return w, nil
+		}
+		return IOWriter{out}, nil
+	case "console":
+		return &ConsoleWriter{Writer: out, ColorOutput: c.Color}, nil
+	case "logfmt":
+		return &LogfmtWriter{Writer: out}, nil
+	}
+	return nil, errors.New("unknown format " + c.Format)
+}
+
+// configWriter is an Writer which can be replaced at runtime.
+type configWriter struct {
+	mu sync.RWMutex
+	w  Writer
+}
+
+func (w *configWriter) get() Writer {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.w
+}
+
+func (w *configWriter) set(writer Writer) {
+	w.mu.Lock()
+	old := w.w
+	w.w = writer
+	w.mu.Unlock()
+	if closer, ok := old.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// Close implements io.Closer, and closes the underlying Writer.
+func (w *configWriter) Close() (err error) {
+	if closer, ok := w.get().(io.Closer); ok {
+		err = closer.Close()
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *configWriter) WriteEntry(e *Entry) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.w.WriteEntry(e)
+}
@@ -0,0 +1,155 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-config")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := filepath.Join(dir, "log.json")
+	ioutil.WriteFile(config, []byte(`{
+		"level": "warn",
+		"writers": {
+			"default": {"type": "file", "filename": "`+filepath.Join(dir, "main.log")+`", "max_backups": 3},
+			"heartbeat": {"type": "file", "level": "error", "filename": "`+filepath.Join(dir, "heartbeat.log")+`"}
+		}
+	}`), 0644)
+
+	logger, err := NewFromConfig(config)
+	if err != nil {
+		t.Fatalf("new from config error: %+v", err)
+	}
+
+	if logger.Level != WarnLevel {
+		t.Errorf("config logger level mismatch: %v", logger.Level)
+	}
+
+	logger.Info().Msg("hello info")
+	logger.Warn().Msg("hello main")
+	logger.Named("heartbeat").Warn().Msg("hello info")
+	logger.Named("heartbeat").Error().Msg("hello heartbeat")
+
+	stop := WatchConfig(logger, config, 10*time.Millisecond)
+	defer stop()
+
+	time.Sleep(20 * time.Millisecond)
+	ioutil.WriteFile(config, []byte(`{"level": "debug", "writers": {"default": {"type": "file", "filename": "`+filepath.Join(dir, "reload.log")+`"}}}`), 0644)
+	os.Chtimes(config, time.Now().Add(time.Second), time.Now().Add(time.Second))
+
+	for i := 0; i < 100 && logger.GetLevel() != DebugLevel; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logger.GetLevel() != DebugLevel {
+		t.Fatalf("config logger should be reloaded")
+	}
+	logger.Debug().Msg("hello reload")
+	logger.Writer.(*configWriter).Close()
+
+	for name, expected := range map[string]string{
+		"main.log":      "hello main",
+		"heartbeat.log": "hello heartbeat",
+		"reload.log":    "hello reload",
+	} {
+		data, _ := ioutil.ReadFile(filepath.Join(dir, name))
+		if !strings.Contains(string(data), expected) || strings.Contains(string(data), "hello info") {
+			t.Errorf("config logger %s mismatch: %s", name, data)
+		}
+	}
+
+	ioutil.WriteFile(config, []byte(`{"writers": {"default": {"type": "unknown"}}}`), 0644)
+	if _, err := NewFromConfig(config); err == nil {
+		t.Errorf("config logger should reject unknown writer type")
+	}
+
+	ioutil.WriteFile(config, []byte(`{"level": "debgu", "writers": {"default": {"type": "stderr"}}}`), 0644)
+	if _, err := NewFromConfig(config); err == nil || !strings.Contains(err.Error(), `"debgu"`) {
+		t.Errorf("config logger should reject unknown level: %v", err)
+	}
+
+	ioutil.WriteFile(config, []byte(`{"writers": {"default": {"type": "stderr", "level": "wran"}}}`), 0644)
+	if _, err := NewFromConfig(config); err == nil || !strings.Contains(err.Error(), "writer default") {
+		t.Errorf("config logger should reject unknown writer level: %v", err)
+	}
+}
+
+func TestWriterConfigFormat(t *testing.T) {
+	for format, check := range map[string]func(Writer) bool{
+		"":        func(w Writer) bool { _, ok := w.(*FileWriter); return ok },
+		"json":    func(w Writer) bool { _, ok := w.(*FileWriter); return ok },
+		"console": func(w Writer) bool { cw, ok := w.(*ConsoleWriter); return ok && cw.Writer != nil },
+		"logfmt":  func(w Writer) bool { lw, ok := w.(*LogfmtWriter); return ok && lw.Writer != nil },
+	} {
+		c := WriterConfig{Type: "file", Filename: "config-format.log", Format: format}
+		if w, err := c.writer(); err != nil || !check(w) {
+			t.Errorf("writer config of format %q mismatch: %T %v", format, w, err)
+		}
+	}
+
+	c := WriterConfig{Type: "stdout", Format: "logfmt"}
+	if w, err := c.writer(); err != nil || w.(*LogfmtWriter).Writer != os.Stdout {
+		t.Errorf("writer config of stdout logfmt mismatch: %v", err)
+	}
+
+	c = WriterConfig{Type: "stderr", Format: "xml"}
+	if _, err := c.writer(); err == nil {
+		t.Errorf("writer config should reject unknown format")
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-env")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "env.log")
+	for key, value := range map[string]string{
+		"LOG_LEVEL":       "warn",
+		"LOG_FORMAT":      "logfmt",
+		"LOG_FILE":        filename,
+		"LOG_MAX_SIZE":    "1048576",
+		"LOG_MAX_BACKUPS": "3",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	logger, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("new from env error: %+v", err)
+	}
+
+	if logger.Level != WarnLevel {
+		t.Errorf("env logger level mismatch: %v", logger.Level)
+	}
+
+	w := logger.Writer.(*LogfmtWriter)
+	if fw := w.Writer.(*FileWriter); fw.MaxSize != 1048576 || fw.MaxBackups != 3 {
+		t.Errorf("env logger file writer mismatch: %+v", fw)
+	}
+
+	logger.Info().Msg("hello info")
+	logger.Warn().Str("foo", "bar").Msg("hello env")
+	w.Close()
+
+	data, _ := ioutil.ReadFile(filename)
+	if !strings.Contains(string(data), `foo=bar message="hello env"`) || strings.Contains(string(data), "hello info") {
+		t.Errorf("env logger output mismatch: %s", data)
+	}
+
+	os.Setenv("LOG_FORMAT", "yaml")
+	if _, err := NewFromEnv(); err == nil {
+		t.Errorf("env logger should reject invalid LOG_FORMAT")
+	}
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"crypto/tls"
+	"net"
+	"strconv"
+)
+
+// TLS adds the field key with the version, cipher suite, server name, negotiated protocol and
+// the peer certificate of state as an object, e.g.
+//
+//	{"version":"TLS 1.3","cipher_suite":"TLS_AES_128_GCM_SHA256","server_name":"example.com",
+//	 "negotiated_protocol":"h2","resumed":false,"peer_certificate":{"subject":"CN=example.com",
+//	 "issuer":"CN=R3,O=Let's Encrypt,C=US","not_after":"2026-12-01T00:00:00Z"}}
+func (e *Entry) TLS(key string, state *tls.ConnectionState) *Entry {
+	if e == nil {
+		return nil
+	}
+	if state == nil {
+		e.key(key)
+		e.buf = append(e.buf, "null"...)
+		return e
+	}
+	start := e.objectStart(key)
+	e.Str("version", tlsVersionName(state.Version))
+	e.Str("cipher_suite", tlsCipherSuiteName(state.CipherSuite))
+	e.StrNonEmpty("server_name", state.ServerName)
+	e.StrNonEmpty("negotiated_protocol", state.NegotiatedProtocol)
+	e.Bool("resumed", state.DidResume)
+	if len(state.PeerCertificates) != 0 {
+		cert := state.PeerCertificates[0]
+		start := e.objectStart("peer_certificate")
+		e.Str("subject", cert.Subject.String())
+		e.Str("issuer", cert.Issuer.String())
+		e.Time("not_after", cert.NotAfter.UTC())
+		e.objectEnd(start)
+	}
+	e.objectEnd(start)
+	return e
+}
+
+// Conn adds the field key with the network, local and remote addresses of c as an object, and
+// the connection state of a *tls.Conn after handshake as the field "tls" of object, see TLS.
+func (e *Entry) Conn(key string, c net.Conn) *Entry {
+	if e == nil {
+		return nil
+	}
+	if c == nil {
+		e.key(key)
+		e.buf = append(e.buf, "null"...)
+		return e
+	}
+	start := e.objectStart(key)
+	if addr := c.LocalAddr(); addr != nil {
+		e.Str("network", addr.Network())
+		e.Str("local", addr.String())
+	}
+	if addr := c.RemoteAddr(); addr != nil {
+		e.Str("remote", addr.String())
+	}
+	if tc, ok := c.(*tls.Conn); ok {
+		if state := tc.ConnectionState(); state.HandshakeComplete {
+			e.TLS("tls", &state)
+		}
+	}
+	e.objectEnd(start)
+	return e
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return "0x" + strconv.FormatUint(uint64(version), 16)
+}
+
+// tlsCipherSuites is the names of cipher suites, as tls.CipherSuiteName of Go 1.14.
+var tlsCipherSuites = map[uint16]string{
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA:            "TLS_RSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA:            "TLS_RSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305:    "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305:  "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	tls.TLS_AES_128_GCM_SHA256:                  "TLS_AES_128_GCM_SHA256",
+	tls.TLS_AES_256_GCM_SHA384:                  "TLS_AES_256_GCM_SHA384",
+	tls.TLS_CHACHA20_POLY1305_SHA256:            "TLS_CHACHA20_POLY1305_SHA256",
+}
+
+func tlsCipherSuiteName(id uint16) string {
+	if name, ok := tlsCipherSuites[id]; ok {
+		return name
+	}
+	return "0x" + strconv.FormatUint(uint64(id), 16)
+}
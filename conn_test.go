@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEntryTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	conn, err := tls.Dial("tcp", server.Listener.Addr().String(), &tls.Config{InsecureSkipVerify: true, ServerName: "example.com"})
+	if err != nil {
+		t.Fatalf("tls dial error: %+v", err)
+	}
+	defer conn.Close()
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+
+	state := conn.ConnectionState()
+	logger.Info().TLS("tls", &state).TLS("nil", nil).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"tls":{"version":"TLS 1.`) ||
+		!strings.Contains(s, `"server_name":"example.com","resumed":false,"peer_certificate":{"subject":"O=Acme Co","issuer":"O=Acme Co","not_after":"`) ||
+		!strings.Contains(s, `"nil":null`) || strings.Contains(s, `"cipher_suite":"0x`) {
+		t.Errorf("tls fields mismatch: %s", s)
+	}
+
+	buf.Reset()
+	logger.Info().Conn("conn", conn).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"conn":{"network":"tcp","local":"127.0.0.1:`) || !strings.Contains(s, `,"remote":"`+server.Listener.Addr().String()+`","tls":{"version"`) {
+		t.Errorf("conn fields mismatch: %s", s)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+		t.Errorf("conn fields should be valid json: %v", err)
+	}
+
+	buf.Reset()
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	logger.Info().Conn("conn", c1).Conn("nil", nil).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"conn":{"network":"pipe","local":"pipe","remote":"pipe"},"nil":null`) {
+		t.Errorf("conn fields mismatch: %s", s)
+	}
+
+	if s := tlsVersionName(0x0300); s != "0x300" {
+		t.Errorf("tlsVersionName mismatch: %s", s)
+	}
+}
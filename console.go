@@ -5,8 +5,19 @@ import (
 	"io"
 	"runtime"
 	"strconv"
+	"strings"
 )
 
+var newlineFolder = strings.NewReplacer("\r", `\r`, "\n", `\n`)
+
+// foldNewlines escapes the newlines of s as \n and \r.
+func foldNewlines(s string) string {
+	if strings.IndexAny(s, "\r\n") < 0 {
+		return s
+	}
+	return newlineFolder.Replace(s)
+}
+
 // IsTerminal returns whether the given file descriptor is a terminal.
 func IsTerminal(fd uintptr) bool {
 	return isTerminal(fd, runtime.GOOS, runtime.GOARCH)
@@ -32,6 +43,10 @@ type ConsoleWriter struct {
 	// EndWithMessage determines if output message in the end.
 	EndWithMessage bool
 
+	// SingleLine determines if escapes the newlines of message, values and stack, so that an
+	// entry never becomes multiple lines which confuse the line-based collectors.
+	SingleLine bool
+
 	// Formatter specifies an optional text formatter for creating a customized output,
 	// If it is set, ColorOutput, QuoteString and EndWithMessage will be ignore.
 	Formatter func(w io.Writer, args *FormatterArgs) (n int, err error)
@@ -92,6 +107,13 @@ func (w *ConsoleWriter) format(out io.Writer, args *FormatterArgs) (n int, err e
 		Gray    = "\x1b[90m"
 	)
 
+	if w.SingleLine {
+		args.Message = foldNewlines(args.Message)
+		for i := range args.KeyValues {
+			args.KeyValues[i].Value = foldNewlines(args.KeyValues[i].Value)
+		}
+	}
+
 	// colorful level string
 	var color, three string
 	switch args.Level {
@@ -111,6 +133,13 @@ func (w *ConsoleWriter) format(out io.Writer, args *FormatterArgs) (n int, err e
 		color, three = Red, "PNC"
 	default:
 		color, three = Gray, "???"
+		if level, ok := customLevelByName(args.Level); ok {
+			info, _ := customLevel(level)
+			three = info.Short
+			if info.Color != "" {
+				color = info.Color
+			}
+		}
 	}
 
 	// pretty console writer
@@ -166,7 +195,11 @@ func (w *ConsoleWriter) format(out io.Writer, args *FormatterArgs) (n int, err e
 	}
 
 	// stack
-	if args.Stack != "" {
+	if args.Stack != "" && w.SingleLine {
+		b.B = append(b.B, " stack="...)
+		b.B = append(b.B, foldNewlines(strings.TrimRight(args.Stack, "\n"))...)
+		b.B = append(b.B, '\n')
+	} else if args.Stack != "" {
 		b.B = append(b.B, '\n')
 		b.B = append(b.B, args.Stack...)
 		if args.Stack[len(args.Stack)-1] != '\n' {
@@ -1,10 +1,12 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -247,3 +249,20 @@ func TestConsoleWriterInvaild(t *testing.T) {
 		t.Errorf("test plain text console writer error: %+v", err)
 	}
 }
+
+func TestConsoleWriterSingleLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := &ConsoleWriter{
+		SingleLine: true,
+		Writer:     &buf,
+	}
+
+	_, err := wlprintf(w, InfoLevel, `{"time":"2019-07-10T05:35:54.277Z","level":"info","foo":"a\nb","stack":"stack1\n\tstack2\n","message":"hello\r\nsingle line"}`)
+	if err != nil {
+		t.Errorf("test single line console writer error: %+v", err)
+	}
+
+	if s := buf.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, `hello\r\nsingle line foo=a\nb stack=stack1\n`+"\tstack2\n") {
+		t.Errorf("single line console writer mismatch: %q", s)
+	}
+}
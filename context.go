@@ -0,0 +1,39 @@
+package log
+
+import (
+	"context"
+)
+
+// ContextFieldExtractor extracts values (e.g. request id, user id, trace id) from ctx and
+// appends them as fields to the entry.
+type ContextFieldExtractor func(ctx context.Context, e *Entry)
+
+type loggerContextKey struct{}
+
+// WithContext returns a copy of ctx in which the logger is associated.
+func (l *Logger) WithContext(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := *l
+	logger.ctx = nil
+	return context.WithValue(ctx, loggerContextKey{}, &logger)
+}
+
+// FromContext returns the logger associated with ctx, or a copy of the Default logger if none.
+// The returned logger is bound to ctx, so the ContextExtractor of logger is applied to
+// every entry logged through it.
+func FromContext(ctx context.Context) *Logger {
+	var logger Logger
+	if ctx == nil {
+		logger = *Default()
+		return &logger
+	}
+	if l, ok := ctx.Value(loggerContextKey{}).(*Logger); ok && l != nil {
+		logger = *l
+	} else {
+		logger = *Default()
+	}
+	logger.ctx = ctx
+	return &logger
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type testContextKey string
+
+func TestLoggerWithContext(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+		ContextExtractor: func(ctx context.Context, e *Entry) {
+			if s, ok := ctx.Value(testContextKey("request_id")).(string); ok {
+				e.Str("request_id", s)
+			}
+		},
+	}
+
+	ctx := logger.WithContext(context.Background())
+	ctx = context.WithValue(ctx, testContextKey("request_id"), "abc123")
+
+	FromContext(ctx).Info().Str("foo", "bar").Msg("hello context")
+
+	if s := buf.String(); !strings.Contains(s, `"request_id":"abc123","foo":"bar","message":"hello context"`) {
+		t.Errorf("context logger output mismatch: %s", s)
+	}
+}
+
+func TestLoggerFromContextDefault(t *testing.T) {
+	DefaultLogger.SetLevel(InfoLevel)
+
+	FromContext(context.Background()).Info().Msg("hello from default context logger")
+	FromContext(nil).Info().Msg("hello from nil context logger")
+
+	if FromContext(context.Background()).Level != DefaultLogger.Level {
+		t.Errorf("context logger should use default logger")
+	}
+}
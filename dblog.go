@@ -0,0 +1,129 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"sort"
+)
+
+// PgxLogger implements methods to satisfy the logger of github.com/jackc/pgx/v5/tracelog,
+// by a function adapter because the level of pgx is a named type.
+//
+//	pgx := logger.Pgx(nil)
+//	config.ConnConfig.Tracer = &tracelog.TraceLog{
+//		Logger: tracelog.LoggerFunc(func(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+//			pgx.Log(ctx, int(level), msg, data)
+//		}),
+//		LogLevel: tracelog.LogLevelInfo,
+//	}
+type PgxLogger struct {
+	logger  Logger
+	context Context
+}
+
+// Pgx wraps the Logger to provide a pgx tracelog logger
+func (l *Logger) Pgx(context Context) *PgxLogger {
+	return &PgxLogger{
+		logger:  *l,
+		context: context,
+	}
+}
+
+// pgxLevels maps the levels of pgx tracelog, from LogLevelNone 1 to LogLevelTrace 6.
+var pgxLevels = [...]Level{noLevel, noLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel, TraceLevel}
+
+// Log logs a pgx message with the data sorted by keys. The data keys "time" and "err" are
+// renamed to "duration" and "error", so they are consistent with the other fields.
+func (p *PgxLogger) Log(ctx context.Context, level int, msg string, data map[string]interface{}) {
+	if level < 0 || level >= len(pgxLevels) || pgxLevels[level] == noLevel {
+		return
+	}
+	e := p.logger.header(pgxLevels[level])
+	if e == nil {
+		return
+	}
+	if p.logger.Caller > 0 {
+		e.caller(runtime.Caller(p.logger.Caller))
+	}
+	if ctx != nil && p.logger.ContextExtractor != nil {
+		p.logger.ContextExtractor(ctx, e)
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return pgxKey(keys[i]) < pgxKey(keys[j]) })
+	for _, k := range keys {
+		e.KeysAndValues(pgxKey(k), data[k])
+	}
+	e.Context(p.context).Msg(msg)
+}
+
+func pgxKey(key string) string {
+	switch key {
+	case "time":
+		return "duration"
+	case "err":
+		return "error"
+	}
+	return key
+}
+
+// RedisLogger implements methods to satisfy interface
+// github.com/redis/go-redis/v9/internal.Logging, which is set by redis.SetLogger.
+type RedisLogger struct {
+	logger  Logger
+	context Context
+}
+
+// Redis wraps the Logger to provide a go-redis logger
+func (l *Logger) Redis(context Context) *RedisLogger {
+	return &RedisLogger{
+		logger:  *l,
+		context: context,
+	}
+}
+
+// Printf logs the connection and retry messages of go-redis at warn level.
+// Arguments are handled in the manner of fmt.Printf.
+func (r *RedisLogger) Printf(ctx context.Context, format string, args ...interface{}) {
+	e := r.logger.header(WarnLevel)
+	if e == nil {
+		return
+	}
+	if r.logger.Caller > 0 {
+		e.caller(runtime.Caller(r.logger.Caller))
+	}
+	if ctx != nil && r.logger.ContextExtractor != nil {
+		r.logger.ContextExtractor(ctx, e)
+	}
+	e.Context(r.context).Msgf(format, args...)
+}
+
+// MySQLLogger implements methods to satisfy interface
+// github.com/go-sql-driver/mysql.Logger, which is set by mysql.SetLogger.
+type MySQLLogger struct {
+	logger  Logger
+	context Context
+}
+
+// MySQL wraps the Logger to provide a mysql driver logger
+func (l *Logger) MySQL(context Context) *MySQLLogger {
+	return &MySQLLogger{
+		logger:  *l,
+		context: context,
+	}
+}
+
+// Print logs the messages of mysql driver at error level, which are about the bad connections
+// and the protocol errors. Arguments are handled in the manner of fmt.Print.
+func (m *MySQLLogger) Print(args ...interface{}) {
+	e := m.logger.header(ErrorLevel)
+	if e == nil {
+		return
+	}
+	if m.logger.Caller > 0 {
+		e.caller(runtime.Caller(m.logger.Caller))
+	}
+	e.Context(m.context).Msgs(args...)
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type redisLogging interface {
+	Printf(ctx context.Context, format string, v ...interface{})
+}
+
+type mysqlLogger interface {
+	Print(v ...interface{})
+}
+
+func TestPgxLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+		ContextExtractor: func(ctx context.Context, e *Entry) {
+			if s, ok := ctx.Value("trace_id").(string); ok {
+				e.Str("trace_id", s)
+			}
+		},
+	}
+	pgx := logger.Pgx(NewContext(nil).Str("tag", "pgx").Value())
+
+	ctx := context.WithValue(context.Background(), "trace_id", "a1b2")
+	pgx.Log(ctx, 4, "Query", map[string]interface{}{
+		"sql":  "select 1",
+		"args": []interface{}{},
+		"time": 1500 * time.Microsecond,
+		"pid":  uint32(42),
+	})
+	pgx.Log(ctx, 2, "Query", map[string]interface{}{"err": errors.New("conn closed")})
+	pgx.Log(ctx, 5, "Prepare", nil)
+	pgx.Log(ctx, 1, "None", nil)
+	pgx.Log(ctx, 9, "Invalid", nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("pgx logger should write 2 entries: %s", buf.String())
+	}
+	if s := lines[0]; !strings.Contains(s, `"level":"info","trace_id":"a1b2","args":"[]","duration":"1.5ms","pid":42,"sql":"select 1","tag":"pgx","message":"Query"}`) {
+		t.Errorf("pgx logger output mismatch: %s", s)
+	}
+	if s := lines[1]; !strings.Contains(s, `"level":"error","trace_id":"a1b2","error":"conn closed","tag":"pgx","message":"Query"}`) {
+		t.Errorf("pgx logger error output mismatch: %s", s)
+	}
+}
+
+func TestRedisLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+	}
+
+	var redislog redisLogging = logger.Redis(nil)
+	redislog.Printf(context.Background(), "redis: %s failed: %s", "dial", "connection refused")
+
+	if s := buf.String(); !strings.Contains(s, `"level":"warn","message":"redis: dial failed: connection refused"}`) {
+		t.Errorf("redis logger output mismatch: %s", s)
+	}
+}
+
+func TestMySQLLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+	}
+
+	var mysqllog mysqlLogger = logger.MySQL(NewContext(nil).Str("tag", "mysql").Value())
+	mysqllog.Print("closing bad idle connection: ", errors.New("EOF"))
+
+	if s := buf.String(); !strings.Contains(s, `"level":"error","tag":"mysql","message":"closing bad idle connection: EOF"}`) {
+		t.Errorf("mysql logger output mismatch: %s", s)
+	}
+}
@@ -0,0 +1,260 @@
+package log
+
+import (
+	"io"
+	"strconv"
+	"time"
+)
+
+// LevelEncoding defines the representation of level values.
+type LevelEncoding int
+
+const (
+	// LevelEncodingLower encodes levels as lower case strings, e.g. "info".
+	LevelEncodingLower LevelEncoding = iota
+	// LevelEncodingUpper encodes levels as upper case strings, e.g. "INFO".
+	LevelEncodingUpper
+	// LevelEncodingNumber encodes levels as numbers, e.g. 3.
+	LevelEncodingNumber
+)
+
+// HumanFormat defines the representation of the fields added by Entry.DurHuman and Entry.ByteSize.
+type HumanFormat int
+
+const (
+	// HumanFormatBoth keeps both the numeric and the human-readable fields.
+	HumanFormatBoth HumanFormat = iota
+	// HumanFormatNumber keeps the numeric fields only, e.g. "elapsed_ms":1530.
+	HumanFormatNumber
+	// HumanFormatText keeps the human-readable fields only, e.g. "elapsed":"1.53s".
+	HumanFormatText
+)
+
+// EncoderConfig specifies the key names and value encodings of entries.
+type EncoderConfig struct {
+	// TimeKey specifies the key of time field, keeps the original key if empty.
+	TimeKey string
+
+	// LevelKey specifies the key of level field, uses "level" if empty.
+	LevelKey string
+
+	// MessageKey specifies the key of message field, uses "message" if empty.
+	MessageKey string
+
+	// CallerKey specifies the key of caller field, uses "caller" if empty.
+	CallerKey string
+
+	// ErrorKey specifies the key of error field, uses "error" if empty.
+	ErrorKey string
+
+	// TraceIDKey specifies the key of trace_id field, uses "trace_id" if empty.
+	TraceIDKey string
+
+	// SpanIDKey specifies the key of span_id field, uses "span_id" if empty.
+	SpanIDKey string
+
+	// LevelEncoding specifies the representation of level values.
+	LevelEncoding LevelEncoding
+
+	// HumanFormat specifies the representation of the fields added by Entry.DurHuman and Entry.ByteSize.
+	// The fields are marked when added to entry, so both are kept if the mark is lost, e.g. the fields
+	// in Logger.Context or in entries rewritten by other writers.
+	HumanFormat HumanFormat
+
+	// TimeFormat specifies the time format of time field, keeps the original value if empty.
+	// If set with `TimeFormatUnix`, `TimeFormatUnixMs`, times are formated as UNIX timestamp.
+	TimeFormat string
+
+	// TimeLocation specifies the location of formatted time, uses time.Local if nil.
+	TimeLocation *time.Location
+}
+
+// ECSEncoderConfig is an EncoderConfig renders entries with the Elastic Common Schema field
+// names, e.g.
+//
+//	log.DefaultLogger.Writer = &log.EncoderWriter{
+//		EncoderConfig: log.ECSEncoderConfig,
+//		Writer:        &log.IOWriter{os.Stdout},
+//	}
+var ECSEncoderConfig = EncoderConfig{
+	TimeKey:    "@timestamp",
+	LevelKey:   "log.level",
+	MessageKey: "message",
+	CallerKey:  "log.origin.file.name",
+	ErrorKey:   "error.message",
+	TraceIDKey: "trace.id",
+	SpanIDKey:  "span.id",
+	TimeFormat: time.RFC3339Nano,
+}
+
+// EncoderWriter is an Writer that renames the standard keys and re-encodes the level
+// and time values of entries by EncoderConfig. To apply it package-wide, wraps the
+// Writer of DefaultLogger.
+type EncoderWriter struct {
+	EncoderConfig
+
+	// Writer specifies the writer of output.
+	Writer Writer
+}
+
+// Close implements io.Closer, and closes the underlying Writer.
+func (w *EncoderWriter) Close() (err error) {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		err = closer.Close()
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *EncoderWriter) WriteEntry(e *Entry) (int, error) {
+	e1 := epool.Get().(*Entry)
+	defer putEntry(e1)
+
+	e1.Level = e.Level
+	e1.loggerFiles = append(e1.loggerFiles[:0], e.loggerFiles...)
+	e1.buf = append(e1.buf[:0], '{')
+
+	first := true
+	// skips the human-readable field following the numeric field of DurHuman and ByteSize
+	skip := false
+	n := jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if skip {
+			skip = false
+			return
+		}
+		if w.HumanFormat != HumanFormatBoth && len(e.humans) != 0 && e.human(key) {
+			if w.HumanFormat == HumanFormatText {
+				return
+			}
+			skip = true
+		}
+		if first {
+			e1.buf = append(e1.buf, '"')
+			if w.TimeKey != "" {
+				e1.buf = append(e1.buf, w.TimeKey...)
+			} else {
+				e1.buf = append(e1.buf, key...)
+			}
+			e1.buf = append(e1.buf, '"', ':')
+			e1.buf = w.appendTime(e1.buf, value, typ)
+			first = false
+			return
+		}
+
+		e1.buf = append(e1.buf, ',', '"')
+		switch b2s(key) {
+		case "level":
+			e1.buf = append(e1.buf, w.key(w.LevelKey, "level")...)
+			e1.buf = append(e1.buf, '"', ':')
+			switch w.LevelEncoding {
+			case LevelEncodingUpper:
+				e1.buf = append(e1.buf, '"')
+				for _, c := range value[1 : len(value)-1] {
+					if 'a' <= c && c <= 'z' {
+						c -= 'a' - 'A'
+					}
+					e1.buf = append(e1.buf, c)
+				}
+				e1.buf = append(e1.buf, '"')
+			case LevelEncodingNumber:
+				e1.buf = strconv.AppendInt(e1.buf, int64(ParseLevel(b2s(value[1:len(value)-1]))), 10)
+			default:
+				e1.buf = append(e1.buf, value...)
+			}
+			return
+		case "message":
+			e1.buf = append(e1.buf, w.key(w.MessageKey, "message")...)
+		case "caller":
+			e1.buf = append(e1.buf, w.key(w.CallerKey, "caller")...)
+		case "error":
+			e1.buf = append(e1.buf, w.key(w.ErrorKey, "error")...)
+		case "trace_id":
+			e1.buf = append(e1.buf, w.key(w.TraceIDKey, "trace_id")...)
+		case "span_id":
+			e1.buf = append(e1.buf, w.key(w.SpanIDKey, "span_id")...)
+		default:
+			e1.buf = append(e1.buf, key...)
+		}
+		e1.buf = append(e1.buf, '"', ':')
+		e1.buf = append(e1.buf, value...)
+	})
+	e1.buf = append(e1.buf, '}')
+	e1.buf = append(e1.buf, e.buf[n:]...)
+
+	return w.Writer.WriteEntry(e1)
+}
+
+// human reports whether the key in buf is the numeric key of DurHuman and ByteSize.
+func (e *Entry) human(key []byte) bool {
+	offset := cap(e.buf) - cap(key)
+	for _, n := range e.humans {
+		if n == offset {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *EncoderWriter) key(key, value string) string {
+	if key == "" {
+		return value
+	}
+	return key
+}
+
+func (w *EncoderWriter) appendTime(dst []byte, value []byte, typ byte) []byte {
+	if w.TimeFormat == "" {
+		return append(dst, value...)
+	}
+
+	t, ok := jsonParseTime(value, typ)
+	if !ok {
+		return append(dst, value...)
+	}
+
+	if w.TimeLocation != nil {
+		t = t.In(w.TimeLocation)
+	} else {
+		t = t.Local()
+	}
+
+	switch w.TimeFormat {
+	case TimeFormatUnix:
+		dst = strconv.AppendInt(dst, t.Unix(), 10)
+	case TimeFormatUnixMs:
+		dst = strconv.AppendInt(dst, t.UnixNano()/1000000, 10)
+	default:
+		dst = append(dst, '"')
+		dst = t.AppendFormat(dst, w.TimeFormat)
+		dst = append(dst, '"')
+	}
+	return dst
+}
+
+// jsonParseTime parses the json value of time field, in RFC3339 or UNIX timestamp format.
+func jsonParseTime(value []byte, typ byte) (t time.Time, ok bool) {
+	switch typ {
+	case 's':
+		var err error
+		t, err = time.Parse(time.RFC3339Nano, b2s(value[1:len(value)-1]))
+		if err != nil {
+			return
+		}
+	case 'n':
+		n, err := strconv.ParseInt(b2s(value), 10, 64)
+		if err != nil {
+			return
+		}
+		if len(value) >= 13 {
+			t = time.Unix(n/1000, n%1000*1000000)
+		} else {
+			t = time.Unix(n, 0)
+		}
+	default:
+		return
+	}
+	ok = true
+	return
+}
+
+var _ Writer = (*EncoderWriter)(nil)
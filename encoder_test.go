@@ -0,0 +1,123 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncoderWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &EncoderWriter{
+		EncoderConfig: EncoderConfig{
+			TimeKey:       "ts",
+			LevelKey:      "severity",
+			MessageKey:    "msg",
+			CallerKey:     "source",
+			ErrorKey:      "err",
+			LevelEncoding: LevelEncodingUpper,
+			TimeFormat:    TimeFormatUnixMs,
+		},
+		Writer: IOWriter{&buf},
+	}
+
+	logger := Logger{
+		Caller: 1,
+		Writer: w,
+	}
+
+	logger.Warn().Err(errors.New("an error")).Str("foo", "bar").Msg("hello encoder")
+
+	s := buf.String()
+	if !strings.HasPrefix(s, `{"ts":1`) ||
+		!strings.Contains(s, `,"severity":"WARN","source":"encoder_test.go:`) ||
+		!strings.Contains(s, `"err":"an error","foo":"bar","msg":"hello encoder"}`+"\n") {
+		t.Errorf("encoder writer output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	w.EncoderConfig = EncoderConfig{
+		LevelEncoding: LevelEncodingNumber,
+		TimeFormat:    "2006-01-02",
+		TimeLocation:  time.UTC,
+	}
+	logger.TimeFormat = TimeFormatUnix
+	logger.Info().Msg("hello encoder")
+
+	s = buf.String()
+	if !strings.HasPrefix(s, `{"time":"`+timeNow().UTC().Format("2006-01-02")+`","level":3,`) || !strings.Contains(s, `"message":"hello encoder"}`) {
+		t.Errorf("encoder writer output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	w.EncoderConfig = EncoderConfig{}
+	logger = Logger{Writer: w}
+	logger.Info().Msg("hello encoder")
+
+	var buf1 bytes.Buffer
+	logger.Writer = IOWriter{&buf1}
+	logger.Info().Msg("hello encoder")
+
+	if s, s1 := buf.String(), buf1.String(); s[strings.Index(s, `"level"`):] != s1[strings.Index(s1, `"level"`):] {
+		t.Errorf("encoder writer should keep the entry: %s, %s", buf.String(), buf1.String())
+	}
+}
+
+func TestEncoderWriterECS(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Caller: 1,
+		Writer: &EncoderWriter{
+			EncoderConfig: ECSEncoderConfig,
+			Writer:        IOWriter{&buf},
+		},
+	}
+
+	logger.Error().Err(errors.New("an error")).Str("trace_id", "0af7651916cd43dd8448eb211c80319c").Msg("hello ecs")
+
+	s := buf.String()
+	if !strings.HasPrefix(s, `{"@timestamp":"`) ||
+		!strings.Contains(s, `,"log.level":"error","log.origin.file.name":"encoder_test.go:`) ||
+		!strings.Contains(s, `"error.message":"an error","trace.id":"0af7651916cd43dd8448eb211c80319c","message":"hello ecs"}`+"\n") {
+		t.Errorf("ecs encoder writer output mismatch: %s", s)
+	}
+}
+
+func TestEncoderWriterHumanFormat(t *testing.T) {
+	cases := []struct {
+		Format HumanFormat
+		Output string
+	}{
+		{HumanFormatBoth, `"elapsed_ms":1530,"elapsed":"1.53s","size_bytes":1572864,"size":"1.5 MiB","other_ms":1,"other":"x","message":"hello"}`},
+		{HumanFormatNumber, `"elapsed_ms":1530,"size_bytes":1572864,"other_ms":1,"other":"x","message":"hello"}`},
+		{HumanFormatText, `"elapsed":"1.53s","size":"1.5 MiB","other_ms":1,"other":"x","message":"hello"}`},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		logger := Logger{
+			Writer: &EncoderWriter{
+				EncoderConfig: EncoderConfig{HumanFormat: c.Format},
+				Writer:        IOWriter{&buf},
+			},
+		}
+		logger.Info().DurHuman("elapsed", 1530*time.Millisecond).ByteSize("size", 1572864).Int("other_ms", 1).Str("other", "x").Msg("hello")
+		if !strings.HasSuffix(buf.String(), c.Output+"\n") {
+			t.Errorf("human format %d mismatch, got %s want %s", c.Format, buf.String(), c.Output)
+		}
+
+		// the marks of human-readable fields are kept by AsyncWriter
+		buf.Reset()
+		async := &AsyncWriter{Writer: logger.Writer}
+		logger.Writer = async
+		logger.Info().DurHuman("elapsed", 1530*time.Millisecond).ByteSize("size", 1572864).Int("other_ms", 1).Str("other", "x").Msg("hello")
+		async.Close()
+		if !strings.HasSuffix(buf.String(), c.Output+"\n") {
+			t.Errorf("async human format %d mismatch, got %s want %s", c.Format, buf.String(), c.Output)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// encryptChunkSize is the size of plaintext chunks of encrypted backups.
+const encryptChunkSize = 64 * 1024
+
+// ErrDecryptBackup is returned by DecryptBackup if the data is corrupted, truncated or the key is wrong.
+var ErrDecryptBackup = errors.New("log: invalid encrypted backup")
+
+// encryptBackup encrypts filename to filename.enc by AES-GCM with key, and removes filename.
+//
+// The output is a 12 bytes random nonce, followed by chunks of at most 64KB plaintext
+// sealed with the nonce xor chunk index, the last chunk is shorter than 64KB and sealed
+// with additional data "final" to detect truncation.
+func encryptBackup(filename string, key []byte, perm os.FileMode) (string, error) {
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filename+".enc", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err == nil {
+		_, err = dst.Write(nonce)
+	}
+
+	buf := make([]byte, encryptChunkSize, encryptChunkSize+aead.Overhead())
+	for i := uint64(0); err == nil; i++ {
+		var n int
+		n, err = io.ReadFull(src, buf[:encryptChunkSize])
+		final := n < encryptChunkSize
+		if final {
+			err = nil
+		}
+		if err != nil {
+			break
+		}
+		_, err = dst.Write(aead.Seal(buf[:0], backupNonce(nonce, i), buf[:n], backupAdditional(final)))
+		if final {
+			break
+		}
+	}
+
+	if err1 := dst.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		os.Remove(filename + ".enc")
+		return "", err
+	}
+
+	src.Close()
+	return filename + ".enc", os.Remove(filename)
+}
+
+// DecryptBackup decrypts an encrypted backup of FileWriter from src to dst with key.
+func DecryptBackup(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(src, nonce); err != nil {
+		return ErrDecryptBackup
+	}
+
+	buf := make([]byte, encryptChunkSize+aead.Overhead())
+	for i := uint64(0); ; i++ {
+		n, err := io.ReadFull(src, buf)
+		final := err == io.ErrUnexpectedEOF || err == io.EOF
+		if err != nil && !final {
+			return err
+		}
+		plain, err := aead.Open(buf[:0], backupNonce(nonce, i), buf[:n], backupAdditional(final))
+		if err != nil {
+			return ErrDecryptBackup
+		}
+		if _, err := dst.Write(plain); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+func newBackupAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func backupNonce(nonce []byte, i uint64) []byte {
+	b := make([]byte, len(nonce))
+	copy(b, nonce)
+	binary.BigEndian.PutUint64(b[len(b)-8:], binary.BigEndian.Uint64(b[len(b)-8:])^i)
+	return b
+}
+
+func backupAdditional(final bool) []byte {
+	if final {
+		return []byte("final")
+	}
+	return nil
+}
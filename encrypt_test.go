@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileWriterEncryption(t *testing.T) {
+	filename := "file-encryption.log"
+	key := []byte("0123456789abcdef0123456789abcdef")
+	text := strings.Repeat("hello file writer!\n", 10000)
+
+	var encrypted string
+	w := &FileWriter{
+		Filename:      filename,
+		MaxBackups:    10,
+		EncryptionKey: key,
+		WaitCleanup:   true,
+		OnRotate: func(oldname, newname string) {
+			encrypted = oldname
+		},
+		BackupName: func(now time.Time) string {
+			return fmt.Sprintf("file-encryption.%d.log", now.UnixNano())
+		},
+	}
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+	time.Sleep(10 * time.Millisecond)
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("file writer rotate error: %+v", err)
+	}
+	w.Close()
+
+	defer func() {
+		matches, _ := filepath.Glob("file-encryption.*")
+		for _, name := range matches {
+			os.Remove(name)
+		}
+	}()
+
+	if encrypted != name+".enc" {
+		t.Fatalf("file writer encrypted name mismatch: %s", encrypted)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("file writer should remove the plaintext: %+v", err)
+	}
+
+	data, err := ioutil.ReadFile(encrypted)
+	if err != nil {
+		t.Fatalf("read encrypted file error: %+v", err)
+	}
+	if bytes.Contains(data, []byte("hello")) {
+		t.Errorf("encrypted file contains plaintext")
+	}
+
+	var buf bytes.Buffer
+	if err := DecryptBackup(&buf, bytes.NewReader(data), key); err != nil || buf.String() != text {
+		t.Errorf("decrypt backup mismatch: %d, %+v", buf.Len(), err)
+	}
+
+	if err := DecryptBackup(ioutil.Discard, bytes.NewReader(data[:len(data)-100]), key); err != ErrDecryptBackup {
+		t.Errorf("decrypt backup should detect truncation: %+v", err)
+	}
+	if err := DecryptBackup(ioutil.Discard, bytes.NewReader(data[:12+encryptChunkSize+16]), key); err != ErrDecryptBackup {
+		t.Errorf("decrypt backup should detect truncation at chunk boundary: %+v", err)
+	}
+	if err := DecryptBackup(ioutil.Discard, bytes.NewReader(data), []byte("fedcba9876543210")); err != ErrDecryptBackup {
+		t.Errorf("decrypt backup should fail with wrong key: %+v", err)
+	}
+}
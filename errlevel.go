@@ -0,0 +1,97 @@
+package log
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+var errorLevels struct {
+	sync.RWMutex
+	rules []errorLevelRule
+}
+
+type errorLevelRule struct {
+	target error
+	typ    reflect.Type
+	level  Level
+}
+
+// RegisterErrorLevel registers the level of the errors matching the sentinel target by errors.Is
+// for ErrAuto, e.g. the expected conditions which are not worth an error entry.
+//
+//	log.RegisterErrorLevel(context.Canceled, log.DebugLevel)
+//	log.RegisterErrorLevel(sql.ErrNoRows, log.InfoLevel)
+//
+// The rules are matched in the order registered, registering a target again replaces its level.
+func RegisterErrorLevel(target error, level Level) {
+	registerErrorLevel(errorLevelRule{target: target, level: level})
+}
+
+// RegisterErrorTypeLevel registers the level of the errors which have the same type as sample
+// in their chain of Unwrap for ErrAuto, e.g.
+//
+//	log.RegisterErrorTypeLevel(&net.OpError{}, log.WarnLevel)
+func RegisterErrorTypeLevel(sample error, level Level) {
+	registerErrorLevel(errorLevelRule{typ: reflect.TypeOf(sample), level: level})
+}
+
+func registerErrorLevel(rule errorLevelRule) {
+	errorLevels.Lock()
+	defer errorLevels.Unlock()
+	for i, r := range errorLevels.rules {
+		if r.typ == rule.typ && (rule.typ != nil || r.target == rule.target) {
+			errorLevels.rules[i].level = rule.level
+			return
+		}
+	}
+	errorLevels.rules = append(errorLevels.rules, rule)
+}
+
+// LevelOf returns the registered level of err, or ErrorLevel if none matches.
+func LevelOf(err error) Level {
+	errorLevels.RLock()
+	defer errorLevels.RUnlock()
+	for _, r := range errorLevels.rules {
+		if r.typ == nil {
+			if errors.Is(err, r.target) {
+				return r.level
+			}
+			continue
+		}
+		for x := err; x != nil; x = errors.Unwrap(x) {
+			if reflect.TypeOf(x) == r.typ {
+				return r.level
+			}
+		}
+	}
+	return ErrorLevel
+}
+
+// ErrAuto starts a new message with the level of err by LevelOf and the "error" field of err.
+// It returns nil if err is nil.
+func (l *Logger) ErrAuto(err error) (e *Entry) {
+	if err == nil {
+		return nil
+	}
+	e = l.header(LevelOf(err))
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
+	}
+	return e.Err(err)
+}
+
+// ErrAuto starts a new message with the level of err by LevelOf and the "error" field of err.
+// It returns nil if err is nil.
+func ErrAuto(err error) (e *Entry) {
+	if err == nil {
+		return nil
+	}
+	l := Default()
+	e = l.header(LevelOf(err))
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
+	}
+	return e.Err(err)
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+func TestErrAuto(t *testing.T) {
+	defer func(rules []errorLevelRule) { errorLevels.rules = rules }(errorLevels.rules)
+	errorLevels.rules = nil
+
+	RegisterErrorLevel(context.Canceled, DebugLevel)
+	RegisterErrorLevel(context.DeadlineExceeded, InfoLevel)
+	RegisterErrorLevel(context.DeadlineExceeded, WarnLevel)
+	RegisterErrorTypeLevel(&net.OpError{}, InfoLevel)
+
+	cases := []struct {
+		Err   error
+		Level Level
+	}{
+		{context.Canceled, DebugLevel},
+		{fmt.Errorf("query: %w", context.Canceled), DebugLevel},
+		{context.DeadlineExceeded, WarnLevel},
+		{fmt.Errorf("dial: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), InfoLevel},
+		{errors.New("boom"), ErrorLevel},
+	}
+	for _, c := range cases {
+		if level := LevelOf(c.Err); level != c.Level {
+			t.Errorf("LevelOf(%v) = %v, want %v", c.Err, level, c.Level)
+		}
+	}
+	if n := len(errorLevels.rules); n != 3 {
+		t.Errorf("registering a target again should replace its level: %d rules", n)
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Level: InfoLevel, Writer: IOWriter{&buf}}
+	logger.ErrAuto(fmt.Errorf("query: %w", context.Canceled)).Msg("canceled")
+	if buf.Len() != 0 {
+		t.Errorf("ErrAuto should log canceled at debug level: %s", buf.String())
+	}
+	logger.ErrAuto(errors.New("boom")).Msg("failed")
+	if s := buf.String(); !strings.Contains(s, `"level":"error","error":"boom","message":"failed"`) {
+		t.Errorf("ErrAuto mismatch: %s", s)
+	}
+	if logger.ErrAuto(nil) != nil || ErrAuto(nil) != nil {
+		t.Errorf("ErrAuto should return nil for nil error")
+	}
+
+	buf.Reset()
+	SetDefault(Logger{Writer: IOWriter{&buf}})
+	defer atomic.StorePointer(&defaultLogger, unsafe.Pointer(&DefaultLogger))
+	ErrAuto(context.DeadlineExceeded).Msg("timeout")
+	if s := buf.String(); !strings.Contains(s, `"level":"warn","error":"context deadline exceeded"`) {
+		t.Errorf("ErrAuto mismatch: %s", s)
+	}
+}
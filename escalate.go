@@ -0,0 +1,156 @@
+package log
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EscalationWriter is an Writer that escalates the level of a message which repeats Threshold
+// times in Window, e.g. 100 warnings per minute become one error, so the alerting systems catch
+// the degradations which are expressed only as repeated warnings. The entries are written as is,
+// and the escalated entry is a copy of the Threshold-th entry with its level replaced and the
+// "escalated_from" and "repeats" fields added. A message escalates at most once per window.
+type EscalationWriter struct {
+	// MinLevel specifies the minimum level of entries to count, the default is WarnLevel.
+	MinLevel Level
+
+	// Level specifies the escalated level, the default is ErrorLevel. The entries at or above
+	// Level are not counted.
+	Level Level
+
+	// Threshold specifies the repeats of a message in Window to escalate, the default is 100.
+	Threshold int
+
+	// Window specifies the counting window of messages, the default is 1 minute.
+	Window time.Duration
+
+	// Writer specifies the writer of output.
+	Writer Writer
+
+	mu     sync.Mutex
+	counts map[string]*escalationCount
+}
+
+type escalationCount struct {
+	start time.Time
+	count int
+}
+
+// escalationMaxMessages is the number of messages to prune the expired counts.
+const escalationMaxMessages = 4096
+
+// Close implements io.Closer, and closes the underlying Writer.
+func (w *EscalationWriter) Close() (err error) {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		err = closer.Close()
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *EscalationWriter) WriteEntry(e *Entry) (n int, err error) {
+	// builds the escalated entry before writing e, since the writers like AsyncWriter take
+	// the buffer of e away.
+	e1 := w.escalate(e)
+	n, err = w.Writer.WriteEntry(e)
+	if e1 != nil {
+		_, err1 := w.Writer.WriteEntry(e1)
+		if err == nil {
+			err = err1
+		}
+		putEntry(e1)
+	}
+	return
+}
+
+// escalate counts the entry, and returns the escalated entry if its message reaches the
+// threshold, or nil.
+func (w *EscalationWriter) escalate(e *Entry) *Entry {
+	minLevel, level := w.MinLevel, w.Level
+	if minLevel == 0 {
+		minLevel = WarnLevel
+	}
+	if level == 0 {
+		level = ErrorLevel
+	}
+	if e.Level < minLevel || e.Level >= level {
+		return nil
+	}
+
+	// the offsets of level value and message in e.buf
+	var levelStart, levelEnd int
+	var message []byte
+	end := jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		switch b2s(key) {
+		case "level":
+			levelStart = cap(e.buf) - cap(value)
+			levelEnd = levelStart + len(value)
+		case "message":
+			message = value
+		}
+	})
+	if levelEnd == 0 || end == 0 {
+		return nil
+	}
+
+	repeats := w.count(message)
+	if repeats == 0 {
+		return nil
+	}
+
+	e1 := epool.Get().(*Entry)
+	e1.Level = level
+	e1.loggerFiles = append(e1.loggerFiles[:0], e.loggerFiles...)
+	e1.buf = append(e1.buf[:0], e.buf[:levelStart]...)
+	e1.buf = strconv.AppendQuote(e1.buf, level.String())
+	e1.buf = append(e1.buf, e.buf[levelEnd:end-1]...)
+	e1.buf = append(e1.buf, ",\"escalated_from\":"...)
+	e1.buf = append(e1.buf, e.buf[levelStart:levelEnd]...)
+	e1.buf = append(e1.buf, ",\"repeats\":"...)
+	e1.buf = strconv.AppendInt(e1.buf, int64(repeats), 10)
+	e1.buf = append(e1.buf, '}', '\n')
+	return e1
+}
+
+// count counts the message, and returns the repeats if it reaches the threshold, or zero.
+func (w *EscalationWriter) count(message []byte) int {
+	threshold, window := w.Threshold, w.Window
+	if threshold <= 0 {
+		threshold = 100
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	now := timeNow()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.counts == nil {
+		w.counts = make(map[string]*escalationCount)
+	}
+	c, ok := w.counts[b2s(message)]
+	if !ok {
+		if len(w.counts) >= escalationMaxMessages {
+			for k, v := range w.counts {
+				if now.Sub(v.start) >= window {
+					delete(w.counts, k)
+				}
+			}
+		}
+		c = &escalationCount{start: now}
+		w.counts[string(message)] = c
+	}
+	if now.Sub(c.start) >= window {
+		c.start, c.count = now, 0
+	}
+	c.count++
+	if c.count == threshold {
+		return c.count
+	}
+	return 0
+}
+
+var _ Writer = (*EscalationWriter)(nil)
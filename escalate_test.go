@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscalationWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &EscalationWriter{
+		Threshold: 3,
+		Window:    time.Hour,
+		Writer:    IOWriter{&buf},
+	}
+	logger := Logger{Writer: w}
+
+	for i := 0; i < 5; i++ {
+		logger.Warn().Int("n", i).Msg("disk is slow")
+		logger.Info().Msg("disk is slow")
+	}
+	logger.Warn().Msg("network is slow")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 12 {
+		t.Fatalf("escalation writer should write the entries and one escalated entry: %s", buf.String())
+	}
+	if s := lines[5]; !strings.Contains(s, `"level":"error","n":2,"message":"disk is slow","escalated_from":"warn","repeats":3}`) {
+		t.Errorf("escalation writer escalated entry mismatch: %s", s)
+	}
+	if n := strings.Count(buf.String(), `"level":"error"`); n != 1 {
+		t.Errorf("escalation writer should escalate once per window, got %d", n)
+	}
+}
+
+func TestEscalationWriterWindow(t *testing.T) {
+	var buf bytes.Buffer
+	w := &EscalationWriter{
+		MinLevel:  InfoLevel,
+		Level:     WarnLevel,
+		Threshold: 2,
+		Window:    20 * time.Millisecond,
+		Writer:    IOWriter{&buf},
+	}
+	logger := Logger{Writer: w}
+
+	logger.Info().Msg("retrying")
+	time.Sleep(30 * time.Millisecond)
+	logger.Info().Msg("retrying")
+	if strings.Contains(buf.String(), `"repeats"`) {
+		t.Errorf("escalation writer should reset the count after window: %s", buf.String())
+	}
+	logger.Info().Msg("retrying")
+	logger.Warn().Msg("retrying")
+	if n := strings.Count(buf.String(), `"level":"warn","message":"retrying","escalated_from":"info","repeats":2}`); n != 1 {
+		t.Errorf("escalation writer should escalate info to warn: %s", buf.String())
+	}
+}
+
+func TestEscalationWriterAsync(t *testing.T) {
+	var buf bytes.Buffer
+	async := &AsyncWriter{ChannelSize: 10, Writer: IOWriter{&buf}}
+	logger := Logger{Writer: &EscalationWriter{Threshold: 3, Writer: async}}
+
+	for i := 0; i < 3; i++ {
+		logger.Warn().Int("n", i).Msg("disk is slow")
+		logger.Warn().Int("n", i).Msgf("network is slow %d", i)
+	}
+	async.Close()
+
+	if s := buf.String(); !strings.Contains(s, `"level":"error","n":2,"message":"disk is slow","escalated_from":"warn","repeats":3}`) {
+		t.Errorf("escalation writer should escalate behind async writer: %s", s)
+	}
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"sync"
+)
+
+var exitHandlers struct {
+	sync.Mutex
+	handlers []func()
+}
+
+// RegisterExitHandler appends a handler which runs before a FatalLevel entry exits, or
+// a PanicLevel entry panics, e.g. to flush or close resources.
+func RegisterExitHandler(handler func()) {
+	exitHandlers.Lock()
+	exitHandlers.handlers = append(exitHandlers.handlers, handler)
+	exitHandlers.Unlock()
+}
+
+// runExitHandlers drains the writer, and runs the registered exit handlers.
+func runExitHandlers(w Writer) {
+	switch f := w.(type) {
+	case interface{ WaitFlush() }:
+		f.WaitFlush()
+	case interface{ Flush() error }:
+		f.Flush()
+	}
+
+	exitHandlers.Lock()
+	handlers := exitHandlers.handlers
+	exitHandlers.Unlock()
+
+	for _, handler := range handlers {
+		handler()
+	}
+}
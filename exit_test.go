@@ -0,0 +1,64 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSlowBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *testSlowBuffer) Write(p []byte) (int, error) {
+	time.Sleep(10 * time.Millisecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *testSlowBuffer) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestLoggerExitFunc(t *testing.T) {
+	var out testSlowBuffer
+	var handled int
+	var code int
+
+	RegisterExitHandler(func() { handled++ })
+
+	w := &AsyncWriter{
+		ChannelSize: 10,
+		Writer:      IOWriter{&out},
+	}
+	defer w.Close()
+
+	logger := Logger{
+		Writer: w,
+		ExitFunc: func(c int) {
+			if strings.Count(out.String(), "\n") != 3 {
+				t.Errorf("async writer should be drained before exit: %s", out.String())
+			}
+			code = c
+		},
+	}
+
+	logger.Info().Msg("hello exit 1")
+	logger.Info().Msg("hello exit 2")
+	logger.Fatal().Msg("hello exit 3")
+
+	if code != 255 || handled != 1 {
+		t.Errorf("logger exit mismatch: code=%d handled=%d", code, handled)
+	}
+
+	logger.Panic().Msg("hello panic")
+	if handled != 2 {
+		t.Errorf("logger panic should run exit handlers: handled=%d", handled)
+	}
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// FailoverWriter is an Writer that writes to Fallback when Writer fails, e.g. disk full or
+// permission denied, and retries Writer after RetryInterval.
+type FailoverWriter struct {
+	// failed is the unix nano time of the last failure, it is the first field to be 64-bit
+	// aligned for the atomic operations on 386 and ARM.
+	failed int64
+
+	// Writer specifies the primary writer of output.
+	Writer Writer
+
+	// Fallback specifies the writer of output when the primary writer fails.
+	Fallback Writer
+
+	// RetryInterval specifies the interval of retrying the failed primary writer, the default is 10s.
+	RetryInterval time.Duration
+
+	// ErrorHandler specifies an optional function called with the errors of primary writer.
+	ErrorHandler func(err error)
+}
+
+// Close implements io.Closer, and closes the underlying writers.
+func (w *FailoverWriter) Close() (err error) {
+	for _, writer := range []Writer{w.Writer, w.Fallback} {
+		if closer, ok := writer.(io.Closer); ok {
+			if err1 := closer.Close(); err1 != nil {
+				err = err1
+			}
+		}
+	}
+	return
+}
+
+// Failed reports whether the primary writer is failed and not retried yet.
+func (w *FailoverWriter) Failed() bool {
+	return atomic.LoadInt64(&w.failed) != 0
+}
+
+// WriteEntry implements Writer.
+func (w *FailoverWriter) WriteEntry(e *Entry) (n int, err error) {
+	interval := w.RetryInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	now := timeNow().UnixNano()
+	if failed := atomic.LoadInt64(&w.failed); failed != 0 && now-failed < int64(interval) {
+		return w.Fallback.WriteEntry(e)
+	}
+
+	n, err = w.Writer.WriteEntry(e)
+	if err == nil {
+		atomic.StoreInt64(&w.failed, 0)
+		return
+	}
+
+	atomic.StoreInt64(&w.failed, now)
+	if w.ErrorHandler != nil {
+		w.ErrorHandler(err)
+	}
+
+	return w.Fallback.WriteEntry(e)
+}
+
+var _ Writer = (*FailoverWriter)(nil)
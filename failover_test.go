@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testFailWriter struct {
+	fail  bool
+	count int
+}
+
+func (w *testFailWriter) WriteEntry(e *Entry) (int, error) {
+	w.count++
+	if w.fail {
+		return 0, errors.New("test write error")
+	}
+	return len(e.buf), nil
+}
+
+func TestFailoverWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var errs int
+
+	primary := &testFailWriter{fail: true}
+	w := &FailoverWriter{
+		Writer:        primary,
+		Fallback:      IOWriter{&buf},
+		RetryInterval: time.Hour,
+		ErrorHandler:  func(err error) { errs++ },
+	}
+
+	logger := Logger{Writer: w}
+
+	logger.Info().Msg("hello failover 1")
+	logger.Info().Msg("hello failover 2")
+
+	if !w.Failed() || primary.count != 1 || errs != 1 || strings.Count(buf.String(), "hello failover") != 2 {
+		t.Errorf("failover writer should write to fallback: failed=%v count=%d errs=%d buf=%s", w.Failed(), primary.count, errs, buf.String())
+	}
+
+	// retry primary after interval
+	primary.fail = false
+	w.failed -= int64(2 * time.Hour)
+	buf.Reset()
+	logger.Info().Msg("hello failover 3")
+
+	if w.Failed() || primary.count != 2 || buf.Len() != 0 {
+		t.Errorf("failover writer should re-attach primary: failed=%v count=%d buf=%s", w.Failed(), primary.count, buf.String())
+	}
+}
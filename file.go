@@ -1,7 +1,10 @@
 package log
 
 import (
+	"bufio"
 	"crypto/md5"
+	"crypto/sha256"
+	"errors"
 	"io"
 	"io/ioutil"
 	"os"
@@ -11,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -38,25 +42,48 @@ type FileWriter struct {
 	Filename string
 
 	// MaxSize is the maximum size in bytes of the log file before it gets rotated.
+	// A write larger than MaxSize returns ErrFileTooLarge, unless ChunkOversized is set.
 	MaxSize int64
 
+	// ChunkOversized determines if splitting a write larger than MaxSize across rotations,
+	// instead of returning ErrFileTooLarge.
+	ChunkOversized bool
+
 	// MaxBackups is the maximum number of old log files to retain.  The default
 	// is to retain all old log files
 	MaxBackups int
 
 	// make aligncheck happy
-	mu   sync.Mutex
-	size int64
-	file *os.File
+	mu       sync.Mutex
+	size     int64
+	file     *os.File
+	unsynced int64
+	synced   time.Time
+	buffer   *bufio.Writer
+	timer    *time.Timer
+	pipe     bool
+	watched  time.Time
+	opened   time.Time
+	idle     *time.Timer
+	written  time.Time
+	idlename string
 
 	// FileMode represents the file's mode and permission bits.  The default
 	// mode is 0644
 	FileMode os.FileMode
 
+	// Strategy specifies the rotation strategy, see RotateStrategy.
+	Strategy RotateStrategy
+
 	// TimeFormat specifies the time format of filename, uses `2006-01-02T15-04-05` as default format.
 	// If set with `TimeFormatUnix`, `TimeFormatUnixMs`, times are formated as UNIX timestamp.
 	TimeFormat string
 
+	// BackupName specifies an optional function returns the filename of log files created at now,
+	// instead of the `name.timestamp.hostname-pid.ext` form. To be cleaned up after rotation, the
+	// returned filename should be in the same directory and starts with `name.`
+	BackupName func(now time.Time) string
+
 	// LocalTime determines if the time used for formatting the timestamps in
 	// log files is the computer's local time.  The default is to use UTC time.
 	LocalTime bool
@@ -70,6 +97,65 @@ type FileWriter struct {
 	// EnsureFolder ensures the file directory creation before writing.
 	EnsureFolder bool
 
+	// BufferSize specifies the size of write buffer, the default is no buffering.
+	// The buffer is flushed on Flush, Sync, Close and rotation.
+	BufferSize int
+
+	// FlushInterval specifies the max duration of buffered data before being flushed.
+	// The default is flushing only when the buffer is full.
+	FlushInterval time.Duration
+
+	// IdleTimeout specifies the duration without writes after which the log file is closed,
+	// it is reopened on next write. It avoids holding the file descriptors of idle log files,
+	// e.g. thousands of per-tenant destinations of MultiFileWriter.
+	IdleTimeout time.Duration
+
+	// ReopenOnDelete determines if checking the log file at most once per second, and
+	// recreating it when it was removed or renamed externally, e.g. by logrotate.
+	ReopenOnDelete bool
+
+	// PipeTimeout specifies the max duration of writing to a full named pipe (FIFO), after
+	// that the entry is dropped with an error. The default is to block until the reader reads.
+	// If Filename is a named pipe, it is opened in non-blocking mode and never rotated.
+	PipeTimeout time.Duration
+
+	// SyncPolicy specifies when the log file is fsynced, the default is never.
+	SyncPolicy SyncPolicy
+
+	// Fallback specifies an optional writer of the data failed to write to the log file,
+	// e.g. disk full or permission denied. The log file is retried on every write.
+	Fallback io.Writer
+
+	// ErrorHandler specifies an optional function called with the errors of symlinking,
+	// chowning and cleaning up, which are performed in background after rotation.
+	ErrorHandler func(err error)
+
+	// EncryptionKey specifies an optional AES key of 16, 24 or 32 bytes, to encrypt the closed
+	// log file to `name.timestamp.ext.enc` with AES-GCM after rotation, and remove the plaintext.
+	// Use DecryptBackup to decrypt it.
+	EncryptionKey []byte
+
+	// Manifest determines if writing a sidecar manifest `name.timestamp.ext.manifest` of the
+	// closed log file after rotation, and appending it to the index file `name.ext.manifest`.
+	// A manifest is a JSON object of filename, sha256, size, start and end time.
+	Manifest bool
+
+	// OnRotate specifies an optional function called with the closed and the new log file
+	// after rotation and before cleanup, e.g. to upload the closed file. oldname is empty
+	// if no log file was opened.
+	OnRotate func(oldname, newname string)
+
+	// Uploader specifies an optional uploader of the closed log file after rotation, e.g.
+	// to S3, GCS or Azure Blob. Upload errors are reported to ErrorHandler.
+	Uploader Uploader
+
+	// RemoveUploaded determines if removing the closed log file after uploaded successfully.
+	RemoveUploaded bool
+
+	// WaitCleanup determines if rotation waits for OnRotate and cleanup to finish, instead
+	// of running them in background. Note that the writes are blocked meanwhile.
+	WaitCleanup bool
+
 	// Cleaner specifies an optional cleanup function of log backups after rotation,
 	// if not set, the default behavior is to delete more than MaxBackups log files.
 	Cleaner func(filename string, maxBackups int, matches []os.FileInfo)
@@ -82,6 +168,37 @@ func (w *FileWriter) WriteEntry(e *Entry) (n int, err error) {
 	w.mu.Lock()
 	n, err = w.write(e.buf)
 	w.mu.Unlock()
+	if err != nil && w.Fallback != nil {
+		n, err = w.Fallback.Write(e.buf)
+	}
+	return
+}
+
+// WriteEntries implements EntryBatchWriter, writes the entries in as few writes as possible
+// while rotating at the same entries as WriteEntry does.
+func (w *FileWriter) WriteEntries(entries []*Entry) (n int, err error) {
+	b := bbget()
+	defer bbput(b)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, e := range entries {
+		b.B = append(b.B, e.buf...)
+		if i+1 < len(entries) && (w.MaxSize <= 0 ||
+			w.size+int64(len(b.B)+len(entries[i+1].buf)) <= w.MaxSize) {
+			continue
+		}
+		m, err1 := w.write(b.B)
+		if err1 != nil && w.Fallback != nil {
+			m, err1 = w.Fallback.Write(b.B)
+		}
+		n += m
+		if err1 != nil && err == nil {
+			err = err1
+		}
+		b.B = b.B[:0]
+	}
 	return
 }
 
@@ -92,33 +209,115 @@ func (w *FileWriter) Write(p []byte) (n int, err error) {
 	w.mu.Lock()
 	n, err = w.write(p)
 	w.mu.Unlock()
+	if err != nil && w.Fallback != nil {
+		n, err = w.Fallback.Write(p)
+	}
 	return
 }
 
 func (w *FileWriter) write(p []byte) (n int, err error) {
+	if w.pipe {
+		return w.writePipe(p)
+	}
+
 	if w.file == nil {
 		if w.Filename == "" {
 			n, err = os.Stderr.Write(p)
 			return
 		}
+		if fi, err1 := os.Stat(w.Filename); err1 == nil && fi.Mode()&os.ModeNamedPipe != 0 {
+			w.pipe = true
+			return w.writePipe(p)
+		}
 		if w.EnsureFolder {
 			err = os.MkdirAll(filepath.Dir(w.Filename), 0755)
 			if err != nil {
 				return
 			}
 		}
-		err = w.create()
+		if w.idlename != "" {
+			err = w.reopenIdle()
+		} else {
+			err = w.create()
+		}
 		if err != nil {
 			return
 		}
+	} else if w.ReopenOnDelete {
+		if now := timeNow(); now.Sub(w.watched) >= time.Second {
+			w.watched = now
+			err = w.reopen()
+			if err != nil {
+				return
+			}
+		}
 	}
 
-	n, err = w.file.Write(p)
+	if w.MaxSize > 0 && int64(len(p)) > w.MaxSize {
+		if !w.ChunkOversized {
+			return 0, ErrFileTooLarge
+		}
+		for len(p) > 0 {
+			chunk := p
+			if int64(len(chunk)) > w.MaxSize {
+				chunk = chunk[:w.MaxSize]
+			}
+			if w.size > 0 {
+				err = w.rotate()
+				if err != nil {
+					return
+				}
+			}
+			var m int
+			m, err = w.write(chunk)
+			n += m
+			if err != nil {
+				return
+			}
+			p = p[len(chunk):]
+		}
+		return
+	}
+
+	if w.BufferSize > 0 {
+		if w.buffer == nil {
+			w.buffer = bufio.NewWriterSize(w.file, w.BufferSize)
+		}
+		n, err = w.buffer.Write(p)
+		if err != nil {
+			// the error of bufio.Writer is sticky, drop the buffer to retry on next write.
+			w.buffer = nil
+			return
+		}
+		if w.FlushInterval > 0 && w.timer == nil && w.buffer.Buffered() > 0 {
+			w.timer = time.AfterFunc(w.FlushInterval, w.flushTimer)
+		}
+	} else {
+		n, err = w.file.Write(p)
+	}
 	if err != nil {
 		return
 	}
 
 	w.size += int64(n)
+	if w.IdleTimeout > 0 {
+		w.written = timeNow()
+		if w.idle == nil {
+			w.idle = time.AfterFunc(w.IdleTimeout, w.idleTimer)
+		}
+	}
+	if w.SyncPolicy.enabled() {
+		w.unsynced += int64(n)
+		if w.SyncPolicy.Always ||
+			(w.SyncPolicy.Bytes > 0 && w.unsynced >= w.SyncPolicy.Bytes) ||
+			(w.SyncPolicy.Interval > 0 && timeNow().Sub(w.synced) >= w.SyncPolicy.Interval) {
+			err = w.sync()
+			if err != nil {
+				return
+			}
+		}
+	}
+
 	if w.MaxSize > 0 && w.size > w.MaxSize && w.Filename != "" {
 		err = w.rotate()
 	}
@@ -126,11 +325,205 @@ func (w *FileWriter) write(p []byte) (n int, err error) {
 	return
 }
 
+// writePipe writes p to the named pipe, and reopens it on next write if the reader has gone.
+func (w *FileWriter) writePipe(p []byte) (n int, err error) {
+	if w.file == nil {
+		w.file, err = os.OpenFile(w.Filename, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+		if err != nil {
+			w.file = nil
+			return
+		}
+	}
+
+	if w.PipeTimeout > 0 {
+		w.file.SetWriteDeadline(timeNow().Add(w.PipeTimeout))
+	}
+
+	n, err = w.file.Write(p)
+	if err != nil {
+		if e, ok := err.(interface{ Timeout() bool }); !ok || !e.Timeout() {
+			w.file.Close()
+			w.file = nil
+		}
+	}
+	return
+}
+
+// ErrFileTooLarge is returned by FileWriter if a write is larger than MaxSize.
+var ErrFileTooLarge = errors.New("log: write length exceeds maximum file size")
+
+// RotateStrategy specifies how FileWriter rotates log files.
+type RotateStrategy int
+
+const (
+	// RotateDefault uses RotateRename on windows, and RotateSymlink on the others.
+	RotateDefault RotateStrategy = iota
+	// RotateSymlink writes to timestamped log files, and points Filename to the current
+	// one by a symlink.
+	RotateSymlink
+	// RotateRename writes to Filename directly, and renames it to a timestamped backup
+	// on rotation. It does not require the privilege of creating symlinks on windows.
+	RotateRename
+)
+
+// Uploader uploads the rotated log files of FileWriter to remote storage.
+type Uploader interface {
+	Upload(filename string) error
+}
+
+// UploaderFunc is an adapter to allow the use of an ordinary function as an Uploader, e.g.
+//
+//	log.UploaderFunc(func(filename string) error {
+//		f, err := os.Open(filename)
+//		if err != nil {
+//			return err
+//		}
+//		defer f.Close()
+//		_, err = s3manager.NewUploader(sess).Upload(&s3manager.UploadInput{
+//			Bucket: aws.String("logs"),
+//			Key:    aws.String(filepath.Base(filename)),
+//			Body:   f,
+//		})
+//		return err
+//	})
+type UploaderFunc func(filename string) error
+
+// Upload calls f(filename).
+func (f UploaderFunc) Upload(filename string) error {
+	return f(filename)
+}
+
+// SyncPolicy specifies when FileWriter fsyncs the log file, trading throughput for durability.
+// The zero value never fsyncs, and the conditions are combined by OR.
+type SyncPolicy struct {
+	// Always fsyncs after every write.
+	Always bool
+
+	// Bytes fsyncs after every Bytes bytes written.
+	Bytes int64
+
+	// Interval fsyncs on the first write after Interval elapsed since the last fsync.
+	Interval time.Duration
+}
+
+func (p SyncPolicy) enabled() bool {
+	return p.Always || p.Bytes > 0 || p.Interval > 0
+}
+
+// Sync commits the current contents of the log file to stable storage.
+func (w *FileWriter) Sync() (err error) {
+	w.mu.Lock()
+	if w.file != nil {
+		err = w.sync()
+	}
+	w.mu.Unlock()
+	return
+}
+
+// Flush writes the buffered data to the log file.
+func (w *FileWriter) Flush() (err error) {
+	w.mu.Lock()
+	if w.file != nil {
+		err = w.flush()
+	}
+	w.mu.Unlock()
+	return
+}
+
+func (w *FileWriter) flush() (err error) {
+	if w.buffer != nil {
+		err = w.buffer.Flush()
+		if err != nil {
+			w.buffer = nil
+		}
+	}
+	return
+}
+
+func (w *FileWriter) flushTimer() {
+	w.mu.Lock()
+	w.timer = nil
+	if w.file != nil {
+		w.flush()
+	}
+	w.mu.Unlock()
+}
+
+// idleTimer closes the log file if there is no write in IdleTimeout.
+func (w *FileWriter) idleTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.idle = nil
+	if w.file == nil || w.pipe {
+		return
+	}
+	if d := timeNow().Sub(w.written); d < w.IdleTimeout {
+		w.idle = time.AfterFunc(w.IdleTimeout-d, w.idleTimer)
+		return
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.close()
+	w.idlename = w.file.Name()
+	w.file = nil
+}
+
+// reopenIdle reopens the log file closed by idleTimer, or creates a new one if it is gone.
+func (w *FileWriter) reopenIdle() (err error) {
+	name := w.idlename
+	w.idlename = ""
+	_, _, perm := w.fileargs(timeNow())
+	w.file, err = os.OpenFile(name, os.O_WRONLY|os.O_APPEND, perm)
+	if err != nil {
+		w.file = nil
+		return w.create()
+	}
+	if w.buffer != nil {
+		w.buffer.Reset(w.file)
+	}
+	if fi, err := w.file.Stat(); err == nil {
+		w.size = fi.Size()
+	}
+	return
+}
+
+func (w *FileWriter) sync() (err error) {
+	if w.pipe {
+		return
+	}
+	err = w.flush()
+	if err1 := w.file.Sync(); err == nil {
+		err = err1
+	}
+	w.unsynced = 0
+	w.synced = timeNow()
+	return
+}
+
 // Close implements io.Closer, and closes the current logfile.
 func (w *FileWriter) Close() (err error) {
+	unregisterWriter(w)
 	w.mu.Lock()
+	if w.idle != nil {
+		w.idle.Stop()
+		w.idle = nil
+	}
+	w.idlename = ""
 	if w.file != nil {
-		err = w.file.Close()
+		if w.timer != nil {
+			w.timer.Stop()
+			w.timer = nil
+		}
+		if w.SyncPolicy.enabled() {
+			err = w.sync()
+		} else {
+			err = w.flush()
+		}
+		if err1 := w.file.Close(); err == nil {
+			err = err1
+		}
 		w.file = nil
 		w.size = 0
 	}
@@ -142,52 +535,98 @@ func (w *FileWriter) Close() (err error) {
 // new one.  This is a helper function for applications that want to initiate
 // rotations outside of the normal rotation rules, such as in response to
 // SIGHUP.  After rotating, this initiates compression and removal of old log
-// files according to the configuration.
+// files according to the configuration. It is a no-op if Filename is a named pipe.
 func (w *FileWriter) Rotate() (err error) {
 	w.mu.Lock()
-	err = w.rotate()
+	if !w.pipe {
+		err = w.rotate()
+	}
 	w.mu.Unlock()
 	return
 }
 
 func (w *FileWriter) rotate() (err error) {
 	var file *os.File
-	file, err = os.OpenFile(w.fileargs(timeNow()))
-	if err != nil {
-		return err
-	}
-	if w.file != nil {
-		w.file.Close()
+	var oldname string
+	var oldtime = w.opened
+	if w.renaming() {
+		w.close()
+		// reopens the log file on next write if the rotation fails.
+		w.file = nil
+		name, flag, perm := w.fileargs(timeNow())
+		name = uniqueName(name)
+		if err = os.Rename(w.Filename, name); err == nil {
+			oldname = name
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+		file, err = os.OpenFile(w.Filename, flag, perm)
+		if err != nil {
+			return err
+		}
+	} else {
+		name, flag, perm := w.fileargs(timeNow())
+		file, err = os.OpenFile(uniqueName(name), flag, perm)
+		if err != nil {
+			return err
+		}
+		if w.file != nil {
+			oldname = w.file.Name()
+			w.close()
+		} else if w.idlename != "" {
+			oldname = w.idlename
+		}
 	}
+	w.idlename = ""
 	w.file = file
+	if w.buffer != nil {
+		w.buffer.Reset(file)
+	}
 	w.size = 0
+	w.unsynced = 0
+	w.synced = timeNow()
+	w.opened = w.synced
 
-	go func(newname string) {
-		os.Remove(w.Filename)
-		if !w.ProcessID {
-			os.Symlink(filepath.Base(newname), w.Filename)
+	// update symlink before releasing the lock, to avoid racing with the next rotation.
+	if !w.renaming() {
+		w.symlink()
+	}
+
+	cleanup := func(oldname, newname string, oldtime, newtime time.Time) {
+		if w.EncryptionKey != nil && oldname != "" {
+			_, _, perm := w.fileargs(timeNow())
+			name, err := encryptBackup(oldname, w.EncryptionKey, perm)
+			if err != nil {
+				w.error(err)
+			} else {
+				oldname = name
+			}
 		}
 
 		uid, _ := strconv.Atoi(os.Getenv("SUDO_UID"))
 		gid, _ := strconv.Atoi(os.Getenv("SUDO_GID"))
 		if uid != 0 && gid != 0 && os.Geteuid() == 0 {
-			os.Lchown(w.Filename, uid, gid)
-			os.Chown(newname, uid, gid)
+			if !w.ProcessID {
+				w.error(os.Lchown(w.Filename, uid, gid))
+			}
+			w.error(os.Chown(newname, uid, gid))
 		}
 
 		dir := filepath.Dir(w.Filename)
 		dirfile, err := os.Open(dir)
 		if err != nil {
+			w.error(err)
 			return
 		}
 		infos, err := dirfile.Readdir(-1)
 		dirfile.Close()
 		if err != nil {
+			w.error(err)
 			return
 		}
 
 		base, ext := filepath.Base(w.Filename), filepath.Ext(w.Filename)
-		prefix, extgz := base[:len(base)-len(ext)]+".", ext+".gz"
+		prefix, extgz, extenc := base[:len(base)-len(ext)]+".", ext+".gz", ext+".enc"
 		exclude := prefix + "error" + ext
 
 		matches := make([]os.FileInfo, 0)
@@ -195,7 +634,7 @@ func (w *FileWriter) rotate() (err error) {
 			name := info.Name()
 			if name != base && name != exclude &&
 				strings.HasPrefix(name, prefix) &&
-				(strings.HasSuffix(name, ext) || strings.HasSuffix(name, extgz)) {
+				(strings.HasSuffix(name, ext) || strings.HasSuffix(name, extgz) || strings.HasSuffix(name, extenc)) {
 				matches = append(matches, info)
 			}
 		}
@@ -203,40 +642,181 @@ func (w *FileWriter) rotate() (err error) {
 			return matches[i].ModTime().Unix() < matches[j].ModTime().Unix()
 		})
 
+		if w.Manifest && oldname != "" {
+			w.error(w.manifest(oldname, oldtime, newtime))
+		}
+
+		if w.OnRotate != nil {
+			w.OnRotate(oldname, newname)
+		}
+
+		if w.Uploader != nil && oldname != "" {
+			if err := w.Uploader.Upload(oldname); err != nil {
+				w.error(err)
+			} else if w.RemoveUploaded {
+				w.error(os.Remove(oldname))
+			}
+		}
+
 		if w.Cleaner != nil {
 			w.Cleaner(w.Filename, w.MaxBackups, matches)
 		} else {
 			for i := 0; i < len(matches)-w.MaxBackups-1; i++ {
-				os.Remove(filepath.Join(dir, matches[i].Name()))
+				w.error(os.Remove(filepath.Join(dir, matches[i].Name())))
+				if w.Manifest {
+					os.Remove(filepath.Join(dir, matches[i].Name()) + ".manifest")
+				}
 			}
 		}
-	}(w.file.Name())
+	}
+
+	if w.WaitCleanup {
+		cleanup(oldname, w.file.Name(), oldtime, w.opened)
+	} else {
+		go cleanup(oldname, w.file.Name(), oldtime, w.opened)
+	}
 
 	return
 }
 
+// reopen recreates the log file if it is not the opened file anymore.
+func (w *FileWriter) reopen() (err error) {
+	fi1, err1 := os.Stat(w.file.Name())
+	if err1 == nil {
+		if fi2, err2 := w.file.Stat(); err2 == nil && os.SameFile(fi1, fi2) {
+			return
+		}
+	}
+	w.flush()
+	w.file.Close()
+	return w.create()
+}
+
 func (w *FileWriter) create() (err error) {
-	w.file, err = os.OpenFile(w.fileargs(timeNow()))
+	if w.renaming() {
+		_, flag, perm := w.fileargs(timeNow())
+		w.file, err = os.OpenFile(w.Filename, flag, perm)
+	} else {
+		w.file, err = os.OpenFile(w.fileargs(timeNow()))
+	}
 	if err != nil {
 		return err
 	}
+	if w.buffer != nil {
+		w.buffer.Reset(w.file)
+	}
 	w.size = 0
+	w.unsynced = 0
+	w.synced = timeNow()
+	w.opened = w.synced
 
-	os.Remove(w.Filename)
-	if !w.ProcessID {
-		os.Symlink(filepath.Base(w.file.Name()), w.Filename)
+	if w.renaming() {
+		if fi, err := w.file.Stat(); err == nil {
+			w.size = fi.Size()
+		}
+	} else {
+		w.symlink()
 	}
 
 	return
 }
 
+// close flushes and closes the current log file, it does not reset the file field.
+func (w *FileWriter) close() {
+	if w.file == nil {
+		return
+	}
+	if w.SyncPolicy.enabled() {
+		w.sync()
+	} else {
+		w.flush()
+	}
+	w.file.Close()
+}
+
+// renaming reports whether the rotation strategy is RotateRename.
+func (w *FileWriter) renaming() bool {
+	return w.Strategy == RotateRename || (w.Strategy == RotateDefault && runtime.GOOS == "windows")
+}
+
+// manifest writes the sidecar manifest of the backup, and appends it to the index file.
+func (w *FileWriter) manifest(filename string, start, end time.Time) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+
+	_, _, perm := w.fileargs(end)
+	b := NewContext(nil).
+		Str("filename", filepath.Base(filename)).
+		Hex("sha256", hash.Sum(nil)).
+		Int64("size", size).
+		Time("start", start).
+		Time("end", end).
+		Value()
+	b[0] = '{'
+	b = append(b, '}', '\n')
+
+	err = ioutil.WriteFile(filename+".manifest", b, perm)
+	if err != nil {
+		return err
+	}
+
+	index, err := os.OpenFile(w.Filename+".manifest", os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	_, err = index.Write(b)
+	if err1 := index.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// symlink points the log file name to the current log file.
+func (w *FileWriter) symlink() {
+	if err := os.Remove(w.Filename); err != nil && !os.IsNotExist(err) {
+		w.error(err)
+	}
+	if !w.ProcessID && w.file != nil {
+		w.error(os.Symlink(filepath.Base(w.file.Name()), w.Filename))
+	}
+}
+
+// error reports the non-nil err to ErrorHandler.
+func (w *FileWriter) error(err error) {
+	if err != nil && w.ErrorHandler != nil {
+		w.ErrorHandler(err)
+	}
+}
+
 // fileargs returns a new filename, flag, perm based on the original name and the given time.
 func (w *FileWriter) fileargs(now time.Time) (filename string, flag int, perm os.FileMode) {
 	if !w.LocalTime {
 		now = now.UTC()
 	}
 
+	// flag
+	flag = os.O_APPEND | os.O_CREATE | os.O_WRONLY
+
+	// perm
+	perm = w.FileMode
+	if perm == 0 {
+		perm = 0644
+	}
+
 	// filename
+	if w.BackupName != nil {
+		filename = w.BackupName(now)
+		return
+	}
 	ext := filepath.Ext(w.Filename)
 	prefix := w.Filename[0 : len(w.Filename)-len(ext)]
 	switch w.TimeFormat {
@@ -263,16 +843,23 @@ func (w *FileWriter) fileargs(now time.Time) (filename string, flag int, perm os
 		}
 	}
 
-	// flag
-	flag = os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	return
+}
 
-	// perm
-	perm = w.FileMode
-	if perm == 0 {
-		perm = 0644
+// uniqueName returns filename if it does not exist, otherwise the first nonexistent
+// name with a sequence number inserted before the extension, e.g. `name.1.ext`.
+func uniqueName(filename string) string {
+	if _, err := os.Lstat(filename); os.IsNotExist(err) {
+		return filename
+	}
+	ext := filepath.Ext(filename)
+	prefix := filename[:len(filename)-len(ext)]
+	for i := 1; ; i++ {
+		name := prefix + "." + strconv.Itoa(i) + ext
+		if _, err := os.Lstat(name); os.IsNotExist(err) {
+			return name
+		}
 	}
-
-	return
 }
 
 var hostname, machine = func() (string, [16]byte) {
@@ -1,10 +1,13 @@
 package log
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -363,4 +366,619 @@ func TestFileWriterFileargs(t *testing.T) {
 			}
 		}
 	})
+	t.Run("backup name", func(t *testing.T) {
+		w := &FileWriter{
+			Filename:  filename,
+			LocalTime: false,
+			BackupName: func(now time.Time) string {
+				return "file-output." + now.Format("20060102") + ".log"
+			},
+		}
+		expected := "file-output.20200812.log"
+		if name, _, perm := w.fileargs(d.In(time.FixedZone("UTC+9", 9*3600))); name != expected || perm != 0644 {
+			t.Fatalf("expected: %q, actual: %q", expected, name)
+		}
+	})
+}
+
+func TestFileWriterSyncPolicy(t *testing.T) {
+	filename := "file-sync-policy.log"
+	text := "hello file writer!\n"
+
+	w := &FileWriter{
+		Filename:   filename,
+		SyncPolicy: SyncPolicy{Bytes: int64(len(text)) * 2},
+	}
+
+	if err := w.Sync(); err != nil {
+		t.Fatalf("file writer sync before open error: %+v", err)
+	}
+
+	fmt.Fprint(w, text)
+	if w.unsynced != int64(len(text)) {
+		t.Errorf("file writer should not sync before the bytes threshold: %d", w.unsynced)
+	}
+	fmt.Fprint(w, text)
+	if w.unsynced != 0 {
+		t.Errorf("file writer should sync after the bytes threshold: %d", w.unsynced)
+	}
+
+	w.SyncPolicy = SyncPolicy{Always: true}
+	fmt.Fprint(w, text)
+	if w.unsynced != 0 {
+		t.Errorf("file writer should sync after every write: %d", w.unsynced)
+	}
+
+	w.SyncPolicy = SyncPolicy{Interval: time.Hour}
+	fmt.Fprint(w, text)
+	if w.unsynced != int64(len(text)) {
+		t.Errorf("file writer should not sync before the interval: %d", w.unsynced)
+	}
+	w.synced = w.synced.Add(-2 * time.Hour)
+	fmt.Fprint(w, text)
+	if w.unsynced != 0 {
+		t.Errorf("file writer should sync after the interval: %d", w.unsynced)
+	}
+
+	if err := w.Sync(); err != nil {
+		t.Errorf("file writer sync error: %+v", err)
+	}
+
+	name := w.file.Name()
+	w.Close()
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil || len(data) != 5*len(text) {
+		t.Errorf("file writer content mismatch: %s, %+v", data, err)
+	}
+
+	os.Remove(name)
+	os.Remove(filename)
+}
+
+func TestFileWriterBuffer(t *testing.T) {
+	filename := "file-buffer.log"
+	text := "hello file writer!\n"
+
+	w := &FileWriter{
+		Filename:      filename,
+		BufferSize:    4096,
+		FlushInterval: 50 * time.Millisecond,
+	}
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+
+	if data, _ := ioutil.ReadFile(name); len(data) != 0 {
+		t.Errorf("file writer should buffer the data: %s", data)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if data, _ := ioutil.ReadFile(name); string(data) != text {
+		t.Errorf("file writer should flush after the interval: %s", data)
+	}
+
+	fmt.Fprint(w, text)
+	if err := w.Flush(); err != nil {
+		t.Errorf("file writer flush error: %+v", err)
+	}
+	if data, _ := ioutil.ReadFile(name); string(data) != text+text {
+		t.Errorf("file writer should flush on Flush: %s", data)
+	}
+
+	fmt.Fprint(w, text)
+	w.Close()
+	if data, _ := ioutil.ReadFile(name); string(data) != text+text+text {
+		t.Errorf("file writer should flush on Close: %s", data)
+	}
+
+	os.Remove(name)
+	os.Remove(filename)
+}
+
+func TestFileWriterBufferError(t *testing.T) {
+	filename := "file-buffer-error.log"
+	text := "hello file writer!\n"
+
+	w := &FileWriter{
+		Filename:   filename,
+		BufferSize: 4096,
+	}
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+	defer os.Remove(filename)
+	defer os.Remove(name)
+
+	w.file.Close()
+	if err := w.Flush(); err == nil {
+		t.Errorf("file writer should return the flush error of closed file")
+	}
+
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("os open file error: %+v", err)
+	}
+	w.file = file
+
+	if _, err := fmt.Fprint(w, text); err != nil {
+		t.Errorf("file writer should not keep the buffer error: %+v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("file writer close error: %+v", err)
+	}
+	if data, _ := ioutil.ReadFile(name); string(data) != text {
+		t.Errorf("file writer should write after the buffer error: %q", data)
+	}
+}
+
+func TestFileWriterReopenOnDelete(t *testing.T) {
+	filename := "file-reopen.log"
+	text := "hello file writer!\n"
+
+	w := &FileWriter{
+		Filename:       filename,
+		ReopenOnDelete: true,
+	}
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+
+	if err := os.Rename(name, name+".rotated"); err != nil {
+		t.Fatalf("os rename error: %+v", err)
+	}
+	defer os.Remove(name + ".rotated")
+
+	w.watched = time.Time{}
+	fmt.Fprint(w, text)
+	w.Close()
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil || string(data) != text {
+		t.Errorf("file writer should recreate the removed file: %s, %+v", data, err)
+	}
+
+	os.Remove(name)
+	os.Remove(filename)
+}
+
+func TestFileWriterErrorHandler(t *testing.T) {
+	filename := "file-error-handler.log"
+	text := "hello file writer!\n"
+
+	os.RemoveAll(filename)
+	if err := os.MkdirAll(filename+"/dir", 0755); err != nil {
+		t.Fatalf("os mkdir error: %+v", err)
+	}
+	defer os.RemoveAll(filename)
+
+	errs := make(chan error, 10)
+	w := &FileWriter{
+		Filename:     filename,
+		ErrorHandler: func(err error) { errs <- err },
+	}
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+	w.Close()
+	os.Remove(name)
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Errorf("file writer should report the symlink error")
+		}
+	default:
+		t.Errorf("file writer should report the symlink error")
+	}
+}
+
+func TestFileWriterOnRotate(t *testing.T) {
+	filename := "file-on-rotate.log"
+	text := "hello file writer!\n"
+
+	var names []string
+	w := &FileWriter{
+		Filename:    filename,
+		MaxBackups:  1,
+		WaitCleanup: true,
+		BackupName: func(now time.Time) string {
+			return fmt.Sprintf("file-on-rotate.%d.log", now.UnixNano())
+		},
+		OnRotate: func(oldname, newname string) {
+			names = append(names, oldname, newname)
+		},
+	}
+
+	fmt.Fprint(w, text)
+	first := w.file.Name()
+	for i := 0; i < 3; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if err := w.Rotate(); err != nil {
+			t.Fatalf("file writer rotate error: %+v", err)
+		}
+	}
+
+	if len(names) != 6 || names[0] != first || names[1] != names[2] || names[3] != names[4] || names[5] != w.file.Name() {
+		t.Errorf("file writer OnRotate mismatch: %v", names)
+	}
+
+	matches, _ := filepath.Glob("file-on-rotate.*.log")
+	if len(matches) != w.MaxBackups+1 {
+		t.Errorf("file writer should wait for cleanup: %v", matches)
+	}
+
+	w.Close()
+	for _, name := range matches {
+		os.Remove(name)
+	}
+	os.Remove(filename)
+}
+
+func TestFileWriterUploader(t *testing.T) {
+	filename := "file-uploader.log"
+	text := "hello file writer!\n"
+
+	var uploaded string
+	w := &FileWriter{
+		Filename:       filename,
+		WaitCleanup:    true,
+		RemoveUploaded: true,
+		BackupName: func(now time.Time) string {
+			return fmt.Sprintf("file-uploader.%d.log", now.UnixNano())
+		},
+		Uploader: UploaderFunc(func(filename string) error {
+			data, err := ioutil.ReadFile(filename)
+			uploaded = string(data)
+			return err
+		}),
+	}
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+	time.Sleep(10 * time.Millisecond)
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("file writer rotate error: %+v", err)
+	}
+
+	if uploaded != text {
+		t.Errorf("file writer upload mismatch: %s", uploaded)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("file writer should remove the uploaded file: %+v", err)
+	}
+
+	w.Close()
+	matches, _ := filepath.Glob("file-uploader.*.log")
+	for _, name := range matches {
+		os.Remove(name)
+	}
+	os.Remove(filename)
+}
+
+func TestFileWriterManifest(t *testing.T) {
+	filename := "file-manifest.log"
+	text := "hello file writer!\n"
+
+	w := &FileWriter{
+		Filename:    filename,
+		MaxBackups:  10,
+		Manifest:    true,
+		WaitCleanup: true,
+		BackupName: func(now time.Time) string {
+			return fmt.Sprintf("file-manifest.%d.log", now.UnixNano())
+		},
+	}
+	defer func() {
+		matches, _ := filepath.Glob("file-manifest.*")
+		for _, name := range matches {
+			os.Remove(name)
+		}
+		os.Remove(filename)
+	}()
+
+	fmt.Fprint(w, text)
+	name := w.file.Name()
+	time.Sleep(10 * time.Millisecond)
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("file writer rotate error: %+v", err)
+	}
+	w.Close()
+
+	var manifest struct {
+		Filename string
+		Sha256   string
+		Size     int64
+		Start    time.Time
+		End      time.Time
+	}
+
+	data, err := ioutil.ReadFile(name + ".manifest")
+	if err != nil {
+		t.Fatalf("read manifest error: %+v", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("manifest invalid json %s: %+v", data, err)
+	}
+	if manifest.Filename != filepath.Base(name) ||
+		manifest.Sha256 != fmt.Sprintf("%x", sha256.Sum256([]byte(text))) ||
+		manifest.Size != int64(len(text)) ||
+		!manifest.End.After(manifest.Start) {
+		t.Errorf("manifest mismatch: %s", data)
+	}
+
+	index, err := ioutil.ReadFile(filename + ".manifest")
+	if err != nil || string(index) != string(data) {
+		t.Errorf("manifest index mismatch: %s, %+v", index, err)
+	}
+}
+
+func TestFileWriterRotateRename(t *testing.T) {
+	filename := "file-rotate-rename.log"
+	text1 := "1. hello file writer!\n"
+	text2 := "2. hello file writer!\n"
+
+	var backup string
+	w := &FileWriter{
+		Filename:    filename,
+		Strategy:    RotateRename,
+		MaxBackups:  10,
+		WaitCleanup: true,
+		OnRotate: func(oldname, newname string) {
+			backup = oldname
+		},
+	}
+	defer func() {
+		matches, _ := filepath.Glob("file-rotate-rename.*.log")
+		for _, name := range matches {
+			os.Remove(name)
+		}
+		os.Remove(filename)
+	}()
+
+	fmt.Fprint(w, text1)
+	if err := w.Rotate(); err != nil {
+		t.Fatalf("file writer rotate error: %+v", err)
+	}
+	fmt.Fprint(w, text2)
+	w.Close()
+
+	if fi, err := os.Lstat(filename); err != nil || !fi.Mode().IsRegular() {
+		t.Fatalf("file writer should write to a regular file: %+v", err)
+	}
+	if data, err := ioutil.ReadFile(filename); err != nil || string(data) != text2 {
+		t.Errorf("file writer current file mismatch: %s, %+v", data, err)
+	}
+	if data, err := ioutil.ReadFile(backup); err != nil || string(data) != text1 {
+		t.Errorf("file writer backup file mismatch: %s, %+v", data, err)
+	}
+
+	// reopen appends to the existing file
+	fmt.Fprint(w, text1)
+	if w.size != int64(len(text2+text1)) {
+		t.Errorf("file writer should continue the size of existing file: %d", w.size)
+	}
+	w.Close()
+}
+
+func TestFileWriterRotateRenameError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-rotate-rename")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	text := "hello file writer!\n"
+	filename := filepath.Join(dir, "logs", "file.log")
+	w := &FileWriter{
+		Filename:     filename,
+		Strategy:     RotateRename,
+		EnsureFolder: true,
+	}
+	defer w.Close()
+
+	fmt.Fprint(w, text)
+	os.RemoveAll(filepath.Dir(filename))
+	if err := w.Rotate(); err == nil {
+		t.Errorf("file writer rotate should fail without the folder")
+	}
+
+	if _, err := fmt.Fprint(w, text); err != nil {
+		t.Errorf("file writer should reopen the log file after the failed rotation: %+v", err)
+	}
+	if data, err := ioutil.ReadFile(filename); err != nil || string(data) != text {
+		t.Errorf("file writer current file mismatch: %s, %+v", data, err)
+	}
+}
+
+func TestFileWriterRotateCollision(t *testing.T) {
+	for _, strategy := range []RotateStrategy{RotateSymlink, RotateRename} {
+		filename := "file-rotate-collision.log"
+		text := "hello file writer!\n"
+
+		w := &FileWriter{
+			Filename:    filename,
+			Strategy:    strategy,
+			MaxBackups:  10,
+			WaitCleanup: true,
+			BackupName: func(now time.Time) string {
+				return "file-rotate-collision.backup.log"
+			},
+		}
+
+		for i := 0; i < 3; i++ {
+			fmt.Fprint(w, text)
+			if err := w.Rotate(); err != nil {
+				t.Fatalf("file writer rotate error: %+v", err)
+			}
+		}
+		w.Close()
+
+		matches, _ := filepath.Glob("file-rotate-collision.*")
+		var total int
+		for _, name := range matches {
+			data, _ := ioutil.ReadFile(name)
+			total += len(data)
+			os.Remove(name)
+		}
+		os.Remove(filename)
+
+		if len(matches) < 3 || total != 3*len(text) {
+			t.Errorf("file writer rotation should not clobber backups: strategy=%d matches=%v total=%d", strategy, matches, total)
+		}
+	}
+}
+
+func TestFileWriterOversized(t *testing.T) {
+	filename := "file-oversized.log"
+	text := "0123456789abcdefghijklmnopqrstuvwxyz\n"
+
+	w := &FileWriter{
+		Filename:    filename,
+		MaxSize:     10,
+		MaxBackups:  10,
+		WaitCleanup: true,
+	}
+	defer func() {
+		matches, _ := filepath.Glob("file-oversized.*")
+		for _, name := range matches {
+			os.Remove(name)
+		}
+		os.Remove(filename)
+	}()
+
+	if _, err := fmt.Fprint(w, text); err != ErrFileTooLarge {
+		t.Errorf("file writer should reject oversized writes: %+v", err)
+	}
+
+	w.ChunkOversized = true
+	w.Strategy = RotateRename
+	var chunks []string
+	w.OnRotate = func(oldname, newname string) {
+		data, _ := ioutil.ReadFile(oldname)
+		chunks = append(chunks, string(data))
+	}
+	if n, err := fmt.Fprint(w, text); err != nil || n != len(text) {
+		t.Fatalf("file writer chunk oversized error: %d %+v", n, err)
+	}
+	w.Close()
+
+	data, _ := ioutil.ReadFile(filename)
+	chunks = append(chunks, string(data))
+	if len(chunks) != 4 || strings.Join(chunks, "") != text || chunks[0] != text[:10] {
+		t.Errorf("file writer chunks mismatch: %q", chunks)
+	}
+}
+
+func TestFileWriterFallback(t *testing.T) {
+	var buf strings.Builder
+
+	w := &FileWriter{
+		Filename: "not-exists-dir/file-fallback.log",
+		Fallback: &buf,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello file fallback")
+
+	if !strings.Contains(buf.String(), "hello file fallback") {
+		t.Errorf("file writer should write to fallback: %s", buf.String())
+	}
+}
+
+func TestFileWriterIdleTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-idle")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := &FileWriter{
+		Filename:    filepath.Join(dir, "file-idle.log"),
+		IdleTimeout: 50 * time.Millisecond,
+	}
+	defer w.Close()
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello before idle")
+
+	time.Sleep(200 * time.Millisecond)
+	w.mu.Lock()
+	file := w.file
+	w.mu.Unlock()
+	if file != nil {
+		t.Fatalf("file writer should close the idle file")
+	}
+
+	logger.Info().Msg("hello after idle")
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "file-idle.*.log"))
+	if len(matches) != 1 {
+		t.Fatalf("file writer should reopen the idle file: %v", matches)
+	}
+	w.Close()
+
+	data, _ := ioutil.ReadFile(matches[0])
+	if !strings.Contains(string(data), "hello before idle") || !strings.Contains(string(data), "hello after idle") {
+		t.Errorf("file writer idle file mismatch: %s", data)
+	}
+}
+
+func TestFileWriterWriteEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file-entries")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	text := "hello file writer entries!\n"
+	w := &FileWriter{
+		Filename: filepath.Join(dir, "file-entries.log"),
+		MaxSize:  int64(3 * len(text)),
+	}
+
+	var entries []*Entry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, &Entry{buf: []byte(text)})
+	}
+	n, err := w.WriteEntries(entries)
+	if err != nil || n != 5*len(text) {
+		t.Fatalf("file writer write entries error: %d, %+v", n, err)
+	}
+	w.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "file-entries.*.log"))
+	if len(matches) != 2 {
+		t.Fatalf("file writer should rotate the entries by max size: %v", matches)
+	}
+	var counts []string
+	for _, name := range matches {
+		data, _ := ioutil.ReadFile(name)
+		counts = append(counts, fmt.Sprint(strings.Count(string(data), text)))
+	}
+	// the rotation happens after the file grows larger than MaxSize, as WriteEntry does.
+	if s := strings.Join(counts, ","); s != "4,1" && s != "1,4" {
+		t.Errorf("file writer write entries rotation mismatch: %s", s)
+	}
+
+	// the batches count the size of the current file.
+	w = &FileWriter{
+		Filename: filepath.Join(dir, "file-written.log"),
+		MaxSize:  int64(3 * len(text)),
+	}
+	fmt.Fprint(w, text+text)
+	if n, err = w.WriteEntries(entries[:3]); err != nil || n != 3*len(text) {
+		t.Fatalf("file writer write entries error: %d, %+v", n, err)
+	}
+	w.Close()
+
+	matches, _ = filepath.Glob(filepath.Join(dir, "file-written.*.log"))
+	counts = counts[:0]
+	for _, name := range matches {
+		data, _ := ioutil.ReadFile(name)
+		counts = append(counts, fmt.Sprint(strings.Count(string(data), text)))
+	}
+	if s := strings.Join(counts, ","); s != "4,1" && s != "1,4" {
+		t.Errorf("file writer write entries rotation mismatch: %s", s)
+	}
 }
@@ -0,0 +1,74 @@
+// +build !windows
+
+package log
+
+import (
+	"bufio"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFileWriterPipe(t *testing.T) {
+	filename := "file-pipe.log"
+	text := "hello file writer!\n"
+
+	os.Remove(filename)
+	if err := syscall.Mkfifo(filename, 0644); err != nil {
+		t.Skipf("mkfifo error: %+v", err)
+	}
+	defer os.Remove(filename)
+
+	w := &FileWriter{
+		Filename:    filename,
+		MaxSize:     1,
+		PipeTimeout: 50 * time.Millisecond,
+	}
+	defer w.Close()
+
+	if _, err := w.Write([]byte(text)); err == nil {
+		t.Errorf("file writer should fail without pipe reader")
+	}
+
+	reader, err := os.OpenFile(filename, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatalf("open pipe reader error: %+v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write([]byte(text)); err != nil {
+			t.Fatalf("file writer pipe error: %+v", err)
+		}
+	}
+
+	r := bufio.NewReader(reader)
+	for i := 0; i < 2; i++ {
+		if line, err := r.ReadString('\n'); err != nil || line != text {
+			t.Errorf("pipe reader mismatch: %#v, %+v", line, err)
+		}
+	}
+
+	// fill the pipe until timeout
+	start := time.Now()
+	for i := 0; ; i++ {
+		if _, err := w.Write(make([]byte, 4096)); err != nil {
+			if time.Since(start) > 5*time.Second {
+				t.Errorf("file writer pipe timeout too late: %+v", err)
+			}
+			break
+		}
+		if i > 1<<16 {
+			t.Fatalf("file writer pipe never blocked")
+		}
+	}
+
+	reader.Close()
+
+	if err := w.Rotate(); err != nil {
+		t.Errorf("file writer rotate pipe error: %+v", err)
+	}
+	if fi, _ := os.Stat(filename); fi.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("file writer should not rotate the pipe")
+	}
+}
@@ -0,0 +1,221 @@
+package log
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// FluentWriter is an Writer that sends entries to fluentd or fluent-bit in the Message mode
+// of Fluentd Forward protocol, i.e. [tag, time, record, option] in msgpack. The tag is
+// Tag joined with the logger name by a period, and the first time field is sent as EventTime.
+type FluentWriter struct {
+	// Network specifies network of the fluentd server, the default is tcp.
+	Network string
+
+	// Address specifies address of the fluentd server, e.g. 127.0.0.1:24224
+	Address string
+
+	// Tag specifies the tag prefix of records, e.g. app
+	Tag string
+
+	// RequireAck determines if waiting for the ack of each record from the server,
+	// the record is retried once on a new connection if no ack is received.
+	RequireAck bool
+
+	// Dial specifies the dial function for creating TCP/TLS connections.
+	Dial func(network, addr string) (net.Conn, error)
+
+	// DialTimeout specifies the timeout of connecting if Dial is nil, the default is 10s.
+	DialTimeout time.Duration
+
+	// WriteTimeout specifies the timeout of writing a log and reading its ack, the default is 10s.
+	WriteTimeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	err  error
+}
+
+// Healthy implements HealthChecker, returns the error of last connecting or writing.
+func (w *FluentWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close closes the connection to the fluentd server.
+func (w *FluentWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	return
+}
+
+// connect makes a connection to the fluentd server.
+func (w *FluentWriter) connect() (err error) {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	network := w.Network
+	if network == "" {
+		network = "tcp"
+	}
+
+	var dial = w.Dial
+	if dial == nil {
+		timeout := w.DialTimeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		dial = func(network, addr string) (net.Conn, error) {
+			return net.DialTimeout(network, addr, timeout)
+		}
+	}
+
+	w.conn, err = dial(network, w.Address)
+	if err != nil {
+		w.conn = nil
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *FluentWriter) WriteEntry(e *Entry) (n int, err error) {
+	b := bbget()
+	defer bbput(b)
+
+	var chunk []byte
+	if w.RequireAck {
+		var id [16]byte
+		rand.Read(id[:])
+		chunk = make([]byte, base64.StdEncoding.EncodedLen(len(id)))
+		base64.StdEncoding.Encode(chunk, id[:])
+	}
+
+	b.B = w.appendMessage(b.B, e, chunk)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if err = w.write(b.B, chunk); err == nil {
+			return len(e.buf), nil
+		}
+	}
+	if err = w.connect(); err != nil {
+		w.err = err
+		return
+	}
+	if err = w.write(b.B, chunk); err == nil {
+		n = len(e.buf)
+	}
+	w.err = err
+	return
+}
+
+// write writes the message, and waits for its ack if chunk is not empty.
+func (w *FluentWriter) write(b, chunk []byte) (err error) {
+	timeout := w.WriteTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	w.conn.SetDeadline(timeNow().Add(timeout))
+
+	if _, err = w.conn.Write(b); err != nil || chunk == nil {
+		return
+	}
+
+	// {"ack": chunk}
+	ack := make([]byte, 0, len(chunk)+8)
+	ack = append(ack, 0x81)
+	ack = appendMsgpackString(ack, []byte("ack"))
+	ack = appendMsgpackString(ack, chunk)
+
+	resp := make([]byte, len(ack))
+	if _, err = io.ReadFull(w.conn, resp); err != nil {
+		return
+	}
+	if !bytes.Equal(resp, ack) {
+		return errors.New("log: fluent ack mismatch")
+	}
+	return
+}
+
+// appendMessage appends the entry to dst as a Forward protocol message.
+func (w *FluentWriter) appendMessage(dst []byte, e *Entry, chunk []byte) []byte {
+	if chunk != nil {
+		dst = append(dst, 0x94)
+	} else {
+		dst = append(dst, 0x93)
+	}
+
+	// tag
+	tag := bbget()
+	defer bbput(tag)
+	tag.B = append(tag.B, w.Tag...)
+	if len(e.loggerFiles) != 0 {
+		if len(tag.B) != 0 {
+			tag.B = append(tag.B, '.')
+		}
+		tag.B = append(tag.B, e.loggerFiles[0]...)
+	}
+	dst = appendMsgpackString(dst, tag.B)
+
+	// time as EventTime, and record without it
+	var t time.Time
+	var n int
+	first := true
+	jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if first {
+			first = false
+			if t1, ok := jsonParseTime(value, typ); ok {
+				t = t1
+				return
+			}
+		}
+		n++
+	})
+	if t.IsZero() {
+		t = timeNow()
+	}
+	sec, nsec := uint32(t.Unix()), uint32(t.Nanosecond())
+	dst = append(dst, 0xd7, 0x00,
+		byte(sec>>24), byte(sec>>16), byte(sec>>8), byte(sec),
+		byte(nsec>>24), byte(nsec>>16), byte(nsec>>8), byte(nsec))
+
+	dst = appendMsgpackHeader(dst, n, 0x80, 0xde, 0xdf)
+	first = true
+	jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if first {
+			first = false
+			if _, ok := jsonParseTime(value, typ); ok {
+				return
+			}
+		}
+		dst = appendMsgpackString(dst, key)
+		dst = appendMsgpack(dst, value, typ)
+	})
+
+	// option
+	if chunk != nil {
+		dst = append(dst, 0x81)
+		dst = appendMsgpackString(dst, []byte("chunk"))
+		dst = appendMsgpackString(dst, chunk)
+	}
+
+	return dst
+}
+
+var _ Writer = (*FluentWriter)(nil)
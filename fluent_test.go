@@ -0,0 +1,94 @@
+package log
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFluentWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %+v", err)
+	}
+	defer ln.Close()
+
+	type message struct {
+		tag    string
+		time   time.Time
+		record string
+		chunk  string
+	}
+	messages := make(chan message, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer close(messages)
+		buf := make([]byte, 4096)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			data := buf[:n]
+			var m message
+			if data[0] != 0x94 {
+				t.Errorf("fluent message should be an array of 4: %x", data[0])
+				return
+			}
+			size := int(data[1] & 0x1f)
+			m.tag, data = string(data[2:2+size]), data[2+size:]
+			if data[0] != 0xd7 || data[1] != 0x00 {
+				t.Errorf("fluent message time should be EventTime: %x", data[:2])
+				return
+			}
+			sec := int64(data[2])<<24 | int64(data[3])<<16 | int64(data[4])<<8 | int64(data[5])
+			nsec := int64(data[6])<<24 | int64(data[7])<<16 | int64(data[8])<<8 | int64(data[9])
+			m.time, data = time.Unix(sec, nsec), data[10:]
+			record, i, err := MsgpackToJSON(nil, data)
+			if err != nil {
+				t.Errorf("fluent message record error: %+v", err)
+				return
+			}
+			m.record, data = string(record), data[i:]
+			// {"chunk": id}
+			m.chunk = string(data[8:])
+			messages <- m
+
+			ack := []byte{0x81, 0xa3, 'a', 'c', 'k', 0xa0 | byte(len(m.chunk))}
+			conn.Write(append(ack, m.chunk...))
+		}
+	}()
+
+	w := &FluentWriter{
+		Address:    ln.Addr().String(),
+		Tag:        "app",
+		RequireAck: true,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Named("http").Info().Str("foo", "bar").Msg("hello fluent")
+	logger.Warn().Msg("hello app")
+	if err := w.Healthy(); err != nil {
+		t.Errorf("fluent writer should be healthy: %+v", err)
+	}
+	w.Close()
+
+	var got []message
+	for m := range messages {
+		got = append(got, m)
+	}
+	if len(got) != 2 {
+		t.Fatalf("fluent writer messages mismatch: %+v", got)
+	}
+	if m := got[0]; m.tag != "app.http" || time.Since(m.time) > time.Minute || m.record != `{"level":"info","foo":"bar","message":"hello fluent"}` || len(m.chunk) != 24 {
+		t.Errorf("fluent writer message mismatch: %+v", m)
+	}
+	if m := got[1]; m.tag != "app" || !strings.Contains(m.record, `"message":"hello app"`) || m.chunk == got[0].chunk {
+		t.Errorf("fluent writer message mismatch: %+v", m)
+	}
+}
@@ -344,3 +344,67 @@ func jsonUnescape(json, str []byte) []byte {
 	}
 	return str
 }
+
+// jsonObjectEach iterates the key/value pairs of a json object, the value is raw json
+// and its typ is one of 's' (string), 'S' (escaped string), 'n' (number), 't' (true),
+// 'f' (false), 'o' (object or array) and 0 (null).
+// It returns the index next to the end of the object.
+func jsonObjectEach(json []byte, fn func(key, value []byte, typ byte)) int {
+	i := 0
+	for i < len(json) && json[i] != '{' {
+		i++
+	}
+	for i++; i < len(json); i++ {
+		switch json[i] {
+		case '}':
+			return i + 1
+		case '"':
+			var key, value []byte
+			var typ byte
+			var ok bool
+			i, key, _, ok = jsonParseString(json, i+1)
+			if !ok {
+				return i
+			}
+			for i < len(json) && (json[i] <= ' ' || json[i] == ':') {
+				i++
+			}
+			if i == len(json) {
+				return i
+			}
+			i, typ, value, ok = jsonParseAny(json, i, true)
+			if !ok {
+				return i
+			}
+			fn(key[1:len(key)-1], value, typ)
+			i--
+		}
+	}
+	return i
+}
+
+// jsonArrayEach calls fn for each element of the json array, and returns the index after it.
+func jsonArrayEach(json []byte, fn func(value []byte, typ byte)) int {
+	i := 0
+	for i < len(json) && json[i] != '[' {
+		i++
+	}
+	for i++; i < len(json); i++ {
+		switch json[i] {
+		case ']':
+			return i + 1
+		case ' ', '\t', '\r', '\n', ',':
+		default:
+			var value []byte
+			var typ byte
+			var ok bool
+			i, typ, value, ok = jsonParseAny(json, i, true)
+			if !ok || len(value) == 0 {
+				return i
+			}
+			fn(value, typ)
+			i--
+		}
+	}
+	return i
+}
@@ -17,3 +17,30 @@ func TestFormatterParse(t *testing.T) {
 		t.Logf("foo=%v", args.Get("foo"))
 	}
 }
+
+func TestFormatterObjectEach(t *testing.T) {
+	json := `{"time":"2019-07-10T05:35:54.277Z", "s":"a\"b","n":-4.2e1,"t":true,"f":false,"o":null,"a":[1,2,3],"obj":{"a":[1,2], "b":{"c":3}}}` + "\n"
+
+	var keys, values, types string
+	n := jsonObjectEach([]byte(json), func(key, value []byte, typ byte) {
+		keys += string(key) + ","
+		values += string(value) + ","
+		if typ == 0 {
+			typ = '0'
+		}
+		types += string(typ)
+	})
+
+	if keys != "time,s,n,t,f,o,a,obj," {
+		t.Errorf("json object each keys mismatch: %s", keys)
+	}
+	if values != `"2019-07-10T05:35:54.277Z","a\"b",-4.2e1,true,false,null,[1,2,3],{"a":[1,2], "b":{"c":3}},` {
+		t.Errorf("json object each values mismatch: %s", values)
+	}
+	if types != "sSntf0oo" {
+		t.Errorf("json object each types mismatch: %s", types)
+	}
+	if n != len(json)-1 {
+		t.Errorf("json object each end mismatch: %d", n)
+	}
+}
@@ -0,0 +1,39 @@
+// +build go1.18
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func FuzzEntryString(f *testing.F) {
+	for _, s := range []string{"", "hello", "a\"b\\c\n", "\x00\x1f<'>&", " 世界", "a\xffb\xe4\xb8"} {
+		f.Add(s, false)
+		f.Add(s, true)
+	}
+	f.Fuzz(func(t *testing.T, s string, html bool) {
+		var buf bytes.Buffer
+		logger := Logger{EscapeHTML: html, Writer: IOWriter{&buf}}
+		logger.Info().Str("s", s).Bytes("b", []byte(s)).Strs("a", []string{s}).Msg(s)
+
+		var entry struct {
+			S       string   `json:"s"`
+			B       string   `json:"b"`
+			A       []string `json:"a"`
+			Message string   `json:"message"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid json output %q: %+v", buf.String(), err)
+		}
+
+		// encoding/json also coerces the invalid UTF-8 to U+FFFD
+		var want string
+		b, _ := json.Marshal(s)
+		json.Unmarshal(b, &want)
+		if entry.S != want || entry.B != want || len(entry.A) != 1 || entry.A[0] != want || entry.Message != want {
+			t.Errorf("entry string %q mismatch: %+v", s, entry)
+		}
+	})
+}
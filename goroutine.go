@@ -0,0 +1,62 @@
+package log
+
+import (
+	"sync"
+)
+
+// goroutineContexts is the registry of goroutine-local fields keyed by goroutine id, sharded
+// to reduce the lock contention.
+var goroutineContexts [64]struct {
+	sync.RWMutex
+	m map[int64]Context
+}
+
+// GoroutineContext returns the goroutine-local fields of the current goroutine.
+func GoroutineContext() Context {
+	id := Goid()
+	shard := &goroutineContexts[uint64(id)%uint64(len(goroutineContexts))]
+	shard.RLock()
+	ctx := shard.m[id]
+	shard.RUnlock()
+	return ctx
+}
+
+// WithGoroutineContext runs fn with ctx appended to the goroutine-local fields of the current
+// goroutine, and restores them after fn returns. The fields are not inherited by the goroutines
+// started in fn. Add GoroutineContextHook to the hooks of logger to log the fields, e.g.
+//
+//	log.WithGoroutineContext(log.NewContext(nil).Str("request_id", id).Value(), func() {
+//		handle(req) // logger.Info().Msg("...") in handle includes request_id
+//	})
+func WithGoroutineContext(ctx Context, fn func()) {
+	id := Goid()
+	shard := &goroutineContexts[uint64(id)%uint64(len(goroutineContexts))]
+
+	shard.Lock()
+	old, ok := shard.m[id]
+	if shard.m == nil {
+		shard.m = make(map[int64]Context)
+	}
+	shard.m[id] = append(old[:len(old):len(old)], ctx...)
+	shard.Unlock()
+
+	defer func() {
+		shard.Lock()
+		if ok {
+			shard.m[id] = old
+		} else {
+			delete(shard.m, id)
+		}
+		shard.Unlock()
+	}()
+
+	fn()
+}
+
+// GoroutineContextHook is a Hook which adds the goroutine-local fields of WithGoroutineContext
+// to the entries.
+var GoroutineContextHook Hook = HookFunc(func(e *Entry, level Level, msg string) {
+	if ctx := GoroutineContext(); len(ctx) != 0 {
+		e.Context(ctx)
+	}
+})
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGoroutineContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Writer: IOWriter{&buf},
+		Hooks:  []Hook{GoroutineContextHook},
+	}
+
+	WithGoroutineContext(NewContext(nil).Str("request_id", "1").Value(), func() {
+		WithGoroutineContext(NewContext(nil).Str("user", "bob").Value(), func() {
+			logger.Info().Msg("nested")
+		})
+		logger.Info().Msg("outer")
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if ctx := GoroutineContext(); len(ctx) != 0 {
+				t.Errorf("goroutine context should not be inherited: %s", ctx)
+			}
+		}()
+		wg.Wait()
+	})
+	logger.Info().Msg("none")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("goroutine context lines mismatch: %q", lines)
+	}
+	if !strings.Contains(lines[0], `"request_id":"1","user":"bob","message":"nested"`) {
+		t.Errorf("goroutine context nested mismatch: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"request_id":"1","message":"outer"`) {
+		t.Errorf("goroutine context outer mismatch: %s", lines[1])
+	}
+	if strings.Contains(lines[2], "request_id") {
+		t.Errorf("goroutine context should be restored: %s", lines[2])
+	}
+	if ctx := GoroutineContext(); len(ctx) != 0 {
+		t.Errorf("goroutine context should be removed: %s", ctx)
+	}
+}
@@ -1,7 +1,11 @@
 package log
 
 import (
+	"context"
+	"reflect"
 	"runtime"
+	"strconv"
+	"time"
 )
 
 // GrpcLogger implements methods to satisfy interface
@@ -175,6 +179,37 @@ func (g *GrpcLogger) V(level int) bool {
 	return level >= int(g.logger.Level)
 }
 
+// InfoDepth logs to INFO log at the specified depth. Arguments are handled in the manner of fmt.Println.
+func (g *GrpcLogger) InfoDepth(depth int, args ...interface{}) {
+	g.depth(InfoLevel, depth, args)
+}
+
+// WarningDepth logs to WARNING log at the specified depth. Arguments are handled in the manner of fmt.Println.
+func (g *GrpcLogger) WarningDepth(depth int, args ...interface{}) {
+	g.depth(WarnLevel, depth, args)
+}
+
+// ErrorDepth logs to ERROR log at the specified depth. Arguments are handled in the manner of fmt.Println.
+func (g *GrpcLogger) ErrorDepth(depth int, args ...interface{}) {
+	g.depth(ErrorLevel, depth, args)
+}
+
+// FatalDepth logs to FATAL log at the specified depth. Arguments are handled in the manner of fmt.Println.
+func (g *GrpcLogger) FatalDepth(depth int, args ...interface{}) {
+	g.depth(FatalLevel, depth, args)
+}
+
+func (g *GrpcLogger) depth(level Level, depth int, args []interface{}) {
+	e := g.logger.header(level)
+	if e == nil {
+		return
+	}
+	if g.logger.Caller > 0 {
+		e.caller(runtime.Caller(g.logger.Caller + depth + 1))
+	}
+	e.Context(g.context).Msgs(args...)
+}
+
 type grpcLoggerV2 interface {
 	Info(args ...interface{})
 	Infoln(args ...interface{})
@@ -191,4 +226,164 @@ type grpcLoggerV2 interface {
 	V(l int) bool
 }
 
-var _ grpcLoggerV2 = (*GrpcLogger)(nil)
+type grpcDepthLoggerV2 interface {
+	grpcLoggerV2
+	InfoDepth(depth int, args ...interface{})
+	WarningDepth(depth int, args ...interface{})
+	ErrorDepth(depth int, args ...interface{})
+	FatalDepth(depth int, args ...interface{})
+}
+
+var _ grpcDepthLoggerV2 = (*GrpcLogger)(nil)
+
+// GrpcInterceptor logs an entry with method, status code, latency and peer per RPC.
+// To keep dependency free, it is used by a thin wrapper of grpc interceptors, e.g.
+//
+//	grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+//		return interceptor.Unary(ctx, req, info.FullMethod, handler)
+//	})
+//	grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+//		return interceptor.Stream(ss.Context(), info.FullMethod, func() error { return handler(srv, ss) })
+//	})
+type GrpcInterceptor struct {
+	// Logger specifies the logger which the entries are written to.
+	Logger Logger
+
+	// Context specifies the contextual fields of entries.
+	Context Context
+
+	// MethodField specifies the field name of method, uses "grpc_method" if empty.
+	MethodField string
+
+	// CodeField specifies the field name of status code, uses "grpc_code" if empty.
+	CodeField string
+
+	// LatencyField specifies the field name of latency, uses "latency" if empty.
+	LatencyField string
+
+	// PeerField specifies the field name of peer address, uses "peer" if empty.
+	PeerField string
+
+	// Level specifies an optional function to map status code to entry level,
+	// if not set, the default mapping of go-grpc-middleware is used.
+	Level func(code int) Level
+
+	// Peer specifies an optional function to get the peer address from the context, e.g.
+	//	func(ctx context.Context) string { p, _ := peer.FromContext(ctx); return p.Addr.String() }
+	Peer func(ctx context.Context) string
+}
+
+// Unary invokes handler and logs the unary RPC.
+func (g *GrpcInterceptor) Unary(ctx context.Context, req interface{}, method string, handler func(ctx context.Context, req interface{}) (interface{}, error)) (resp interface{}, err error) {
+	start := timeNow()
+	resp, err = handler(ctx, req)
+	g.log(ctx, method, start, err)
+	return
+}
+
+// Stream invokes handler and logs the streaming RPC.
+func (g *GrpcInterceptor) Stream(ctx context.Context, method string, handler func() error) (err error) {
+	start := timeNow()
+	err = handler()
+	g.log(ctx, method, start, err)
+	return
+}
+
+func (g *GrpcInterceptor) log(ctx context.Context, method string, start time.Time, err error) {
+	code := grpcCode(err)
+
+	var level Level
+	if g.Level != nil {
+		level = g.Level(code)
+	} else {
+		level = grpcCodeLevel(code)
+	}
+
+	e := g.Logger.header(level)
+	if e == nil {
+		return
+	}
+
+	field := func(name, value string) string {
+		if name == "" {
+			return value
+		}
+		return name
+	}
+
+	e = e.Context(g.Context).
+		Str(field(g.MethodField, "grpc_method"), method).
+		Str(field(g.CodeField, "grpc_code"), grpcCodeString(code)).
+		TimeDiff(field(g.LatencyField, "latency"), timeNow(), start)
+	if g.Peer != nil && ctx != nil {
+		e.Str(field(g.PeerField, "peer"), g.Peer(ctx))
+	}
+	if err != nil {
+		e.Err(err)
+	}
+	e.Msg("")
+}
+
+// grpcCode returns the status code of err which implements `GRPCStatus() *status.Status`.
+func grpcCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	m := reflect.ValueOf(err).MethodByName("GRPCStatus")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return 2 // Unknown
+	}
+	s := m.Call(nil)[0]
+	if s.Kind() == reflect.Ptr && s.IsNil() {
+		return 0
+	}
+	m = s.MethodByName("Code")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return 2 // Unknown
+	}
+	switch c := m.Call(nil)[0]; c.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(c.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(c.Int())
+	}
+	return 2 // Unknown
+}
+
+var grpcCodes = [...]string{
+	"OK",
+	"Canceled",
+	"Unknown",
+	"InvalidArgument",
+	"DeadlineExceeded",
+	"NotFound",
+	"AlreadyExists",
+	"PermissionDenied",
+	"ResourceExhausted",
+	"FailedPrecondition",
+	"Aborted",
+	"OutOfRange",
+	"Unimplemented",
+	"Internal",
+	"Unavailable",
+	"DataLoss",
+	"Unauthenticated",
+}
+
+func grpcCodeString(code int) string {
+	if code >= 0 && code < len(grpcCodes) {
+		return grpcCodes[code]
+	}
+	return "Code(" + strconv.Itoa(code) + ")"
+}
+
+func grpcCodeLevel(code int) Level {
+	switch grpcCodeString(code) {
+	case "OK", "Canceled", "InvalidArgument", "NotFound", "AlreadyExists", "Unauthenticated":
+		return InfoLevel
+	case "DeadlineExceeded", "PermissionDenied", "ResourceExhausted", "FailedPrecondition", "Aborted", "OutOfRange":
+		return WarnLevel
+	default:
+		return ErrorLevel
+	}
+}
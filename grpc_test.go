@@ -1,6 +1,10 @@
 package log
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -52,3 +56,82 @@ func TestGrpcLoggerLevel(t *testing.T) {
 	grpclog.Fatalln("hello", "grpclog Fatalln message")
 	grpclog.Fatalf("hello %s", "grpclog Fatalf message")
 }
+
+func TestGrpcLoggerDepth(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Caller: 1,
+		Writer: IOWriter{&buf},
+	}
+
+	var grpclog grpcDepthLoggerV2 = logger.Grpc(nil)
+	grpclog.WarningDepth(0, "hello grpclog WarningDepth message")
+
+	if s := buf.String(); !strings.Contains(s, `"level":"warn","caller":"grpc_test.go:`) || !strings.Contains(s, `"message":"hello grpclog WarningDepth message"}`) {
+		t.Errorf("grpc depth logger output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	func() { grpclog.ErrorDepth(1, "hello") }()
+	if s := buf.String(); !strings.Contains(s, `"level":"error","caller":"grpc_test.go:`) {
+		t.Errorf("grpc depth logger caller mismatch: %s", s)
+	}
+}
+
+type testGrpcCode uint32
+
+type testGrpcStatus struct {
+	code testGrpcCode
+}
+
+func (s *testGrpcStatus) Code() testGrpcCode { return s.code }
+
+type testGrpcError struct {
+	status *testGrpcStatus
+}
+
+func (e *testGrpcError) Error() string               { return "rpc error" }
+func (e *testGrpcError) GRPCStatus() *testGrpcStatus { return e.status }
+
+func TestGrpcInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+
+	g := &GrpcInterceptor{
+		Logger: Logger{
+			Writer: IOWriter{&buf},
+		},
+		Context: NewContext(nil).Str("tag", "hi grpc").Value(),
+		Peer:    func(ctx context.Context) string { return "127.0.0.1:1234" },
+	}
+
+	resp, err := g.Unary(context.Background(), "req", "/pkg.Service/Method", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "resp", nil
+	})
+	if resp != "resp" || err != nil {
+		t.Errorf("grpc interceptor unary mismatch: %v, %v", resp, err)
+	}
+	if s := buf.String(); !strings.Contains(s, `"level":"info","tag":"hi grpc","grpc_method":"/pkg.Service/Method","grpc_code":"OK","latency":`) ||
+		!strings.Contains(s, `"peer":"127.0.0.1:1234"`) {
+		t.Errorf("grpc interceptor unary output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	err = g.Stream(context.Background(), "/pkg.Service/Stream", func() error {
+		return &testGrpcError{&testGrpcStatus{13}}
+	})
+	if s := buf.String(); !strings.Contains(s, `"level":"error"`) || !strings.Contains(s, `"grpc_code":"Internal"`) || !strings.Contains(s, `"error":"rpc error"`) {
+		t.Errorf("grpc interceptor stream output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	g.MethodField = "method"
+	g.CodeField = "code"
+	g.Level = func(code int) Level { return WarnLevel }
+	g.Unary(context.Background(), nil, "/pkg.Service/Method", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errors.New("plain error")
+	})
+	if s := buf.String(); !strings.Contains(s, `"level":"warn"`) || !strings.Contains(s, `"method":"/pkg.Service/Method","code":"Unknown"`) {
+		t.Errorf("grpc interceptor custom output mismatch: %s", s)
+	}
+}
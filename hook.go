@@ -0,0 +1,36 @@
+package log
+
+// Hook defines an interface to a log hook, which runs on an entry before the Writer.
+// It can add fields to the entry, veto the entry by calling e.Discard(), or fan
+// out the entry, e.g. counting metrics.
+type Hook interface {
+	// Run runs the hook with the entry.
+	Run(e *Entry, level Level, msg string)
+}
+
+// HookFunc is an adaptor to allow the use of an ordinary function as a Hook.
+type HookFunc func(e *Entry, level Level, msg string)
+
+// Run implements the Hook interface.
+func (h HookFunc) Run(e *Entry, level Level, msg string) {
+	h(e, level, msg)
+}
+
+// hook runs the hooks of entry, and reports whether the entry is not discarded.
+func (e *Entry) hook(msg string) bool {
+	e.hooking = true
+	for _, h := range e.hooks {
+		h.Run(e, e.Level, msg)
+		if e.discarded {
+			break
+		}
+	}
+	e.hooking = false
+	if e.discarded {
+		putEntry(e)
+		return false
+	}
+	return true
+}
+
+var _ Hook = HookFunc(nil)
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLoggerHooks(t *testing.T) {
+	var buf bytes.Buffer
+	var count int
+
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+		Hooks: []Hook{
+			HookFunc(func(e *Entry, level Level, msg string) {
+				count++
+			}),
+			HookFunc(func(e *Entry, level Level, msg string) {
+				if strings.Contains(msg, "secret") {
+					e.Discard()
+				}
+			}),
+			HookFunc(func(e *Entry, level Level, msg string) {
+				e.Str("hooked_level", level.String())
+			}),
+		},
+	}
+
+	logger.Info().Str("foo", "bar").Msg("hello hook")
+	if s := buf.String(); !strings.Contains(s, `"foo":"bar","hooked_level":"info","message":"hello hook"`) {
+		t.Errorf("hook output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	logger.Warn().Msgf("hello %s", "hook")
+	if s := buf.String(); !strings.Contains(s, `"hooked_level":"warn","message":"hello hook"`) {
+		t.Errorf("hook msgf output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	logger.Error().Msgs("a ", "secret")
+	logger.Error().Msg("a secret")
+	if s := buf.String(); s != "" {
+		t.Errorf("hook should discard the entries: %s", s)
+	}
+
+	logger.Debug().Msg("hello disabled hook")
+
+	if count != 4 {
+		t.Errorf("hook count mismatch: %d", count)
+	}
+}
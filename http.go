@@ -0,0 +1,167 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPHandler is a http.Handler middleware that logs an entry per request with
+// method, path, status, bytes, duration, remote ip and user agent.
+//
+// For web frameworks, use Middleware with echo.WrapMiddleware or fiber adaptor.HTTPMiddleware,
+// or call Log in a gin middleware, e.g.
+//
+//	router.Use(func(c *gin.Context) {
+//		start := time.Now()
+//		c.Next()
+//		h.Log(c.Request, c.Writer.Status(), int64(c.Writer.Size()), start)
+//	})
+type HTTPHandler struct {
+	// Logger specifies the logger which the entries are written to.
+	Logger Logger
+
+	// Context specifies the contextual fields of entries.
+	Context Context
+
+	// Handler specifies the http handler to be wrapped.
+	Handler http.Handler
+
+	// Fields specifies an optional function to add custom fields from the request.
+	Fields func(e *Entry, req *http.Request)
+
+	// TrustedProxies specifies the networks of reverse proxies whose X-Forwarded-For and
+	// X-Real-IP headers are trusted for the remote ip, see RemoteIP.
+	TrustedProxies []*net.IPNet
+}
+
+// ServeHTTP implements http.Handler.
+func (h *HTTPHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	start := timeNow()
+	w := &httpResponseWriter{ResponseWriter: rw}
+	h.Handler.ServeHTTP(w, req)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	h.Log(req, w.status, w.bytes, start)
+}
+
+// Middleware returns a copy of HTTPHandler which wraps the next handler.
+func (h *HTTPHandler) Middleware(next http.Handler) http.Handler {
+	h1 := *h
+	h1.Handler = next
+	return &h1
+}
+
+// Log logs an entry of the request which is started at start.
+func (h *HTTPHandler) Log(req *http.Request, status int, bytes int64, start time.Time) {
+	e := h.Logger.header(InfoLevel)
+	if e == nil {
+		return
+	}
+	e = e.Context(h.Context).
+		Str("method", req.Method).
+		Str("path", req.URL.Path).
+		Int("status", status).
+		Int64("bytes", bytes).
+		TimeDiff("duration", timeNow(), start).
+		Str("remote_ip", RemoteIP(req, h.TrustedProxies...)).
+		Str("user_agent", req.UserAgent())
+	if h.Fields != nil {
+		h.Fields(e, req)
+	}
+	e.Msg("")
+}
+
+// RemoteIP returns the client ip of request by RemoteAddr. The X-Forwarded-For and X-Real-IP
+// headers are used only if RemoteAddr is one of the trusted proxies, since any client can forge
+// them. The X-Forwarded-For is walked from right to left, and the first untrusted ip is returned.
+//
+//	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+//	ip := log.RemoteIP(req, proxies)
+func RemoteIP(req *http.Request, trusted ...*net.IPNet) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if !httpTrusted(host, trusted) {
+		return host
+	}
+	if s := req.Header.Get("X-Forwarded-For"); s != "" {
+		for s != "" {
+			ip := s
+			if i := strings.LastIndexByte(s, ','); i >= 0 {
+				ip, s = s[i+1:], s[:i]
+			} else {
+				s = ""
+			}
+			if ip = strings.TrimSpace(ip); ip != "" {
+				host = ip
+				if !httpTrusted(ip, trusted) {
+					break
+				}
+			}
+		}
+		return host
+	}
+	if s := req.Header.Get("X-Real-IP"); s != "" {
+		return s
+	}
+	return host
+}
+
+func httpTrusted(host string, trusted []*net.IPNet) bool {
+	if len(trusted) == 0 {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type httpResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *httpResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *httpResponseWriter) Write(b []byte) (n int, err error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err = w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return
+}
+
+func (w *httpResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *httpResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("http.Hijacker is not supported")
+}
+
+var _ http.Handler = (*HTTPHandler)(nil)
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := &HTTPHandler{
+		Logger: Logger{
+			Writer: IOWriter{&buf},
+		},
+		Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusNotFound)
+			io.WriteString(rw, "not found")
+		}),
+		Fields: func(e *Entry, req *http.Request) {
+			e.Str("request_id", req.Header.Get("X-Request-Id"))
+		},
+		TrustedProxies: testTrustedProxies("192.0.2.0/24", "10.0.0.0/8"),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/foo?bar=1", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.1")
+	req.Header.Set("X-Request-Id", "abc")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	s := buf.String()
+	if !strings.Contains(s, `"method":"GET","path":"/foo","status":404,"bytes":9,"duration":`) ||
+		!strings.Contains(s, `"remote_ip":"1.2.3.4","user_agent":"test-agent","request_id":"abc"`) {
+		t.Errorf("http handler output mismatch: %s", s)
+	}
+}
+
+func testTrustedProxies(cidrs ...string) (trusted []*net.IPNet) {
+	for _, s := range cidrs {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			panic(err)
+		}
+		trusted = append(trusted, n)
+	}
+	return
+}
+
+func TestRemoteIP(t *testing.T) {
+	trusted := testTrustedProxies("192.0.2.0/24", "10.0.0.0/8")
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	if ip := RemoteIP(req, trusted...); ip != "192.0.2.1" {
+		t.Errorf("remote ip mismatch: %s", ip)
+	}
+	req.Header.Set("X-Real-IP", "198.51.100.2")
+	if ip := RemoteIP(req); ip != "192.0.2.1" {
+		t.Errorf("remote ip should not trust X-Real-IP by default: %s", ip)
+	}
+	if ip := RemoteIP(req, trusted...); ip != "198.51.100.2" {
+		t.Errorf("remote ip of X-Real-IP mismatch: %s", ip)
+	}
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 198.51.100.3, 10.0.0.1")
+	if ip := RemoteIP(req); ip != "192.0.2.1" {
+		t.Errorf("remote ip should not trust X-Forwarded-For by default: %s", ip)
+	}
+	if ip := RemoteIP(req, trusted...); ip != "198.51.100.3" {
+		t.Errorf("remote ip of X-Forwarded-For should skip the trusted proxies only: %s", ip)
+	}
+	req.Header.Set("X-Forwarded-For", "10.0.0.2, 10.0.0.1")
+	if ip := RemoteIP(req, trusted...); ip != "10.0.0.2" {
+		t.Errorf("remote ip of X-Forwarded-For mismatch: %s", ip)
+	}
+
+	req.RemoteAddr = "198.51.100.4:1234"
+	if ip := RemoteIP(req, trusted...); ip != "198.51.100.4" {
+		t.Errorf("remote ip should not trust the headers from untrusted address: %s", ip)
+	}
+}
+
+func TestHTTPHandlerMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+
+	h := &HTTPHandler{
+		Logger: Logger{
+			Writer: IOWriter{&buf},
+		},
+	}
+
+	handler := h.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		io.WriteString(rw, "hello")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/bar", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if s := buf.String(); !strings.Contains(s, `"method":"POST","path":"/bar","status":200,"bytes":5,`) {
+		t.Errorf("http middleware output mismatch: %s", s)
+	}
+	if h.Handler != nil {
+		t.Errorf("http middleware should not modify the handler")
+	}
+}
@@ -0,0 +1,150 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// HTTPDump specifies the headers and bodies added by Entry.HTTPRequest and Entry.HTTPResponse.
+type HTTPDump struct {
+	// Headers specifies the headers to add, all headers are added if it is nil.
+	Headers []string
+
+	// RedactHeaders specifies the headers whose values are replaced with "[REDACTED]".
+	RedactHeaders []string
+
+	// MaxBodySize specifies the max bytes of bodies to add, the bodies are not added if it is
+	// zero. The longer bodies are truncated with the field "body_truncated":true.
+	MaxBodySize int
+}
+
+// DefaultHTTPDump is the HTTPDump used by Entry.HTTPRequest and Entry.HTTPResponse.
+var DefaultHTTPDump = HTTPDump{
+	RedactHeaders: []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key"},
+}
+
+// HTTPRequest adds the field key with the method, url, headers, content length and optionally
+// the body of req as an object, by DefaultHTTPDump, e.g.
+//
+//	log.Debug().HTTPRequest("request", req).HTTPResponse("response", resp).Msg("http dump")
+//
+// The body is read up to DefaultHTTPDump.MaxBodySize and restored, so req is still readable.
+func (e *Entry) HTTPRequest(key string, req *http.Request) *Entry {
+	if e == nil {
+		return nil
+	}
+	if req == nil {
+		e.key(key)
+		e.buf = append(e.buf, "null"...)
+		return e
+	}
+	start := e.objectStart(key)
+	e.Str("method", req.Method)
+	if req.URL != nil {
+		e.Str("url", req.URL.String())
+	}
+	e.Str("proto", req.Proto)
+	e.httpHeaders(req.Header)
+	if req.ContentLength >= 0 {
+		e.Int64("content_length", req.ContentLength)
+	}
+	req.Body = e.httpBody(req.Body)
+	e.objectEnd(start)
+	return e
+}
+
+// HTTPResponse adds the field key with the status, headers, content length and optionally the
+// body of resp as an object, by DefaultHTTPDump. The body is read up to
+// DefaultHTTPDump.MaxBodySize and restored, so resp is still readable.
+func (e *Entry) HTTPResponse(key string, resp *http.Response) *Entry {
+	if e == nil {
+		return nil
+	}
+	if resp == nil {
+		e.key(key)
+		e.buf = append(e.buf, "null"...)
+		return e
+	}
+	start := e.objectStart(key)
+	e.Int("status", resp.StatusCode)
+	e.Str("proto", resp.Proto)
+	e.httpHeaders(resp.Header)
+	if resp.ContentLength >= 0 {
+		e.Int64("content_length", resp.ContentLength)
+	}
+	resp.Body = e.httpBody(resp.Body)
+	e.objectEnd(start)
+	return e
+}
+
+func (e *Entry) httpHeaders(header http.Header) {
+	if len(header) == 0 {
+		return
+	}
+	names := DefaultHTTPDump.Headers
+	if names == nil {
+		names = make([]string, 0, len(header))
+		for name := range header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	start := e.objectStart("headers")
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		values := header[name]
+		if len(values) == 0 {
+			continue
+		}
+		e.key(name)
+		e.buf = append(e.buf, '"')
+		if httpRedacted(name) {
+			e.buf = append(e.buf, "[REDACTED]"...)
+		} else {
+			for i, value := range values {
+				if i != 0 {
+					e.buf = append(e.buf, ", "...)
+				}
+				e.string(value)
+			}
+		}
+		e.buf = append(e.buf, '"')
+	}
+	e.objectEnd(start)
+}
+
+func httpRedacted(name string) bool {
+	for _, s := range DefaultHTTPDump.RedactHeaders {
+		if strings.EqualFold(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// httpBody adds the body up to DefaultHTTPDump.MaxBodySize, and returns a body which reads the
+// whole content of body.
+func (e *Entry) httpBody(body io.ReadCloser) io.ReadCloser {
+	max := DefaultHTTPDump.MaxBodySize
+	if max <= 0 || body == nil || body == http.NoBody {
+		return body
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(body, int64(max)+1))
+	if len(b) > max {
+		e.Bytes("body", b[:max]).Bool("body_truncated", true)
+	} else {
+		e.Bytes("body", b)
+	}
+	if err != nil {
+		e.AnErr("body_error", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), body), body}
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEntryHTTPDump(t *testing.T) {
+	defer func(d HTTPDump) { DefaultHTTPDump = d }(DefaultHTTPDump)
+	DefaultHTTPDump.MaxBodySize = 8
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+
+	req := httptest.NewRequest("POST", "http://example.com/api?q=1", strings.NewReader(`{"name":"bob"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Add("Accept", "text/plain")
+	req.Header.Add("Accept", "application/json")
+
+	resp := &http.Response{
+		StatusCode:    200,
+		Proto:         "HTTP/1.1",
+		Header:        http.Header{"Set-Cookie": {"sid=1"}},
+		ContentLength: 2,
+		Body:          ioutil.NopCloser(strings.NewReader("ok")),
+	}
+
+	logger.Info().HTTPRequest("request", req).HTTPResponse("response", resp).HTTPResponse("nil", nil).Msg("dump")
+
+	s := buf.String()
+	if !strings.Contains(s, `"request":{"method":"POST","url":"http://example.com/api?q=1","proto":"HTTP/1.1","headers":{"Accept":"text/plain, application/json","Authorization":"[REDACTED]","Content-Type":"application/json"},"content_length":14,"body":"{\"name\":","body_truncated":true}`) {
+		t.Errorf("http request dump mismatch: %s", s)
+	}
+	if !strings.Contains(s, `"response":{"status":200,"proto":"HTTP/1.1","headers":{"Set-Cookie":"[REDACTED]"},"content_length":2,"body":"ok"},"nil":null`) {
+		t.Errorf("http response dump mismatch: %s", s)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+		t.Errorf("http dump should be valid json: %v", err)
+	}
+
+	if body, _ := ioutil.ReadAll(req.Body); string(body) != `{"name":"bob"}` {
+		t.Errorf("http request body should be restored: %s", body)
+	}
+	if body, _ := ioutil.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("http response body should be restored: %s", body)
+	}
+
+	buf.Reset()
+	DefaultHTTPDump.Headers = []string{"accept", "x-missing"}
+	DefaultHTTPDump.MaxBodySize = 0
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Cookie", "a=b")
+	logger.Info().HTTPRequest("request", req).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"request":{"method":"GET","url":"/","proto":"HTTP/1.1","headers":{"Accept":"*/*"},"content_length":0}`) {
+		t.Errorf("http request dump mismatch: %s", s)
+	}
+}
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"time"
+)
+
+// Each calls fn for the top-level fields of entry in order until fn returns false, so the hooks,
+// filters and routing writers can inspect an entry without parsing its json again. The value is
+// the raw json, e.g. "bar", 42 or {"a":1}, and both key and value are only valid during the call.
+// The "message" field is present in the writers but not in the hooks, which run before Msg.
+// The groups opened by Group and Namespace are closed in the values, as they are written.
+func (e *Entry) Each(fn func(key, value []byte) bool) {
+	if e == nil {
+		return
+	}
+	buf := e.buf
+	if len(e.groups) != 0 {
+		// closes the groups in a copy, the hooks run before closeGroups.
+		b := bbget()
+		defer bbput(b)
+		b.B = append(b.B, e.buf...)
+		for i := len(e.groups) - 1; i >= 0; i-- {
+			if pos := e.groups[i]; pos < len(b.B) && b.B[pos] == ',' {
+				b.B = append(b.B[:pos], b.B[pos+1:]...)
+			}
+			b.B = append(b.B, '}')
+		}
+		buf = b.B
+	}
+	stop := false
+	jsonObjectEach(buf, func(key, value []byte, _ byte) {
+		if !stop {
+			stop = !fn(key, value)
+		}
+	})
+}
+
+// Lookup returns the raw json value of the first top-level field named key.
+func (e *Entry) Lookup(key string) (value []byte, ok bool) {
+	e.Each(func(k, v []byte) bool {
+		if b2s(k) == key {
+			value, ok = v, true
+			if len(e.groups) != 0 {
+				// v is in the copy of Each with the groups closed.
+				value = append([]byte(nil), v...)
+			}
+		}
+		return !ok
+	})
+	return
+}
+
+// LookupString returns the value of the first top-level field named key as a string, the json
+// strings are unquoted and the other values are returned as is, e.g. 42 or true.
+func (e *Entry) LookupString(key string) (string, bool) {
+	value, ok := e.Lookup(key)
+	if !ok {
+		return "", false
+	}
+	if len(value) >= 2 && value[0] == '"' {
+		if len(value) == 2 {
+			return "", true
+		}
+		return string(jsonUnescape(value[1:len(value)-1], nil)), true
+	}
+	return string(value), true
+}
+
+// Message returns the message of entry, or empty if the message is not written yet.
+func (e *Entry) Message() string {
+	msg, _ := e.LookupString("message")
+	return msg
+}
+
+// Timestamp returns the time of entry, which is the first field in RFC3339 or UNIX timestamp
+// format. It returns the zero time if the time is in the other formats of Logger.TimeFormat.
+func (e *Entry) Timestamp() (t time.Time) {
+	if e == nil {
+		return
+	}
+	first := true
+	jsonObjectEach(e.buf, func(_, value []byte, typ byte) {
+		if first {
+			t, _ = jsonParseTime(value, typ)
+			first = false
+		}
+	})
+	return
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+type testInspectWriter struct {
+	keys    []string
+	message string
+	user    string
+	time    time.Time
+}
+
+func (w *testInspectWriter) WriteEntry(e *Entry) (int, error) {
+	w.keys = w.keys[:0]
+	e.Each(func(key, value []byte) bool {
+		w.keys = append(w.keys, string(key))
+		return string(key) != "user"
+	})
+	w.message = e.Message()
+	w.user, _ = e.LookupString("user")
+	w.time = e.Timestamp()
+	return len(e.buf), nil
+}
+
+func TestEntryInspect(t *testing.T) {
+	w := &testInspectWriter{}
+	logger := Logger{Writer: w}
+
+	logger.Info().Int("n", 42).Str("user", "bob \"b\"").Str("x", "y").Msg("hello")
+	if got := len(w.keys); got != 4 || w.keys[3] != "user" {
+		t.Errorf("Each should stop on false: %v", w.keys)
+	}
+	if w.message != "hello" {
+		t.Errorf("Message returns %q", w.message)
+	}
+	if w.user != `bob "b"` {
+		t.Errorf("LookupString returns %q", w.user)
+	}
+	if w.time.IsZero() || time.Since(w.time) > time.Minute {
+		t.Errorf("Timestamp returns %v", w.time)
+	}
+
+	logger = Logger{TimeFormat: TimeFormatUnixMs, Writer: w}
+	logger.Info().Msg("")
+	if w.message != "" || w.time.IsZero() || time.Since(w.time) > time.Minute {
+		t.Errorf("Timestamp returns %v, Message returns %q", w.time, w.message)
+	}
+
+	var value []byte
+	var ok bool
+	logger = Logger{
+		Hooks: []Hook{HookFunc(func(e *Entry, level Level, msg string) {
+			value, ok = e.Lookup("n")
+			value = append([]byte(nil), value...)
+			if e.Message() != "" {
+				t.Errorf("Message should be empty in hooks")
+			}
+		})},
+		Writer: w,
+	}
+	logger.Info().Int("n", 42).Msg("hello")
+	if !ok || string(value) != "42" {
+		t.Errorf("Lookup returns %q, %v", value, ok)
+	}
+	logger = Logger{
+		Hooks: []Hook{HookFunc(func(e *Entry, level Level, msg string) {
+			value, ok = e.Lookup("http")
+			value = append([]byte(nil), value...)
+		})},
+		Writer: w,
+	}
+	logger.Group("http").Info().Str("method", "GET").Namespace("resp").Int("status", 200).Msg("hello")
+	if !ok || string(value) != `{"method":"GET","resp":{"status":200}}` {
+		t.Errorf("Lookup of open groups returns %q, %v", value, ok)
+	}
+	logger.Info().Namespace("http").Msg("hello")
+	if !ok || string(value) != `{}` {
+		t.Errorf("Lookup of empty namespace returns %q, %v", value, ok)
+	}
+
+	if _, ok := (&Entry{buf: []byte(`{"a":1}`)}).Lookup("b"); ok {
+		t.Errorf("Lookup should report missing fields")
+	}
+
+	var e *Entry
+	if e.Message() != "" || !e.Timestamp().IsZero() {
+		t.Errorf("nil entry should be empty")
+	}
+}
@@ -0,0 +1,147 @@
+package log
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Key is a field key pre-encoded as `,"key":` by Intern, it is copied into entries as is.
+// Keys are intended to be package variables of services logging the same schema, e.g.
+//
+//	var keyUserID = log.Intern("user_id")
+//
+//	log.Info().KeyStr(keyUserID, id).Msg("user login")
+type Key string
+
+// Field is a field pre-encoded as `,"key":"value"` by InternField, it is copied into entries as is.
+type Field string
+
+var internTable struct {
+	sync.RWMutex
+	keys   map[string]Key
+	fields map[[2]string]Field
+}
+
+// Intern returns the pre-encoded key of name, the same name shares the same Key.
+func Intern(name string) Key {
+	internTable.RLock()
+	k, ok := internTable.keys[name]
+	internTable.RUnlock()
+	if ok {
+		return k
+	}
+
+	internTable.Lock()
+	defer internTable.Unlock()
+	if k, ok = internTable.keys[name]; ok {
+		return k
+	}
+	e := Entry{buf: []byte{',', '"'}}
+	e.string(name)
+	e.buf = append(e.buf, '"', ':')
+	k = Key(e.buf)
+	if internTable.keys == nil {
+		internTable.keys = make(map[string]Key)
+	}
+	internTable.keys[name] = k
+	return k
+}
+
+// InternField returns the pre-encoded field of key and the small string value, the same pair
+// shares the same Field.
+func InternField(key, value string) Field {
+	internTable.RLock()
+	f, ok := internTable.fields[[2]string{key, value}]
+	internTable.RUnlock()
+	if ok {
+		return f
+	}
+
+	k := Intern(key)
+
+	internTable.Lock()
+	defer internTable.Unlock()
+	if f, ok = internTable.fields[[2]string{key, value}]; ok {
+		return f
+	}
+	e := Entry{buf: append([]byte(k), '"')}
+	e.string(value)
+	e.buf = append(e.buf, '"')
+	f = Field(e.buf)
+	if internTable.fields == nil {
+		internTable.fields = make(map[[2]string]Field)
+	}
+	internTable.fields[[2]string{key, value}] = f
+	return f
+}
+
+// Field adds the pre-encoded field f to the entry.
+func (e *Entry) Field(f Field) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, f...)
+	return e
+}
+
+// KeyStr adds the pre-encoded key k with val as a string to the entry.
+func (e *Entry) KeyStr(k Key, val string) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, k...)
+	e.buf = append(e.buf, '"')
+	e.string(val)
+	e.buf = append(e.buf, '"')
+	return e
+}
+
+// KeyInt adds the pre-encoded key k with i as a int to the entry.
+func (e *Entry) KeyInt(k Key, i int) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, k...)
+	e.buf = strconv.AppendInt(e.buf, int64(i), 10)
+	return e
+}
+
+// KeyInt64 adds the pre-encoded key k with i as a int64 to the entry.
+func (e *Entry) KeyInt64(k Key, i int64) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, k...)
+	e.buf = strconv.AppendInt(e.buf, i, 10)
+	return e
+}
+
+// KeyUint64 adds the pre-encoded key k with i as a uint64 to the entry.
+func (e *Entry) KeyUint64(k Key, i uint64) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, k...)
+	e.buf = strconv.AppendUint(e.buf, i, 10)
+	return e
+}
+
+// KeyFloat64 adds the pre-encoded key k with f as a float64 to the entry.
+func (e *Entry) KeyFloat64(k Key, f float64) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, k...)
+	e.buf = strconv.AppendFloat(e.buf, f, 'f', -1, 64)
+	return e
+}
+
+// KeyBool adds the pre-encoded key k with b as a bool to the entry.
+func (e *Entry) KeyBool(k Key, b bool) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, k...)
+	e.buf = strconv.AppendBool(e.buf, b)
+	return e
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestIntern(t *testing.T) {
+	if k := Intern("user_id"); k != `,"user_id":` {
+		t.Errorf("intern key mismatch: %s", k)
+	}
+	if k := Intern(`a"b`); k != `,"a\"b":` {
+		t.Errorf("intern key should be escaped: %s", k)
+	}
+	if f := InternField("status", "ok"); f != `,"status":"ok"` {
+		t.Errorf("intern field mismatch: %s", f)
+	}
+	if a, b := Intern("user_id"), Intern("user_id"); a != b {
+		t.Errorf("intern key should be shared: %s, %s", a, b)
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().
+		KeyStr(Intern("s"), "x\ty").
+		KeyInt(Intern("i"), -1).
+		KeyInt64(Intern("i64"), 64).
+		KeyUint64(Intern("u64"), 42).
+		KeyFloat64(Intern("f"), 1.5).
+		KeyBool(Intern("b"), true).
+		Field(InternField("status", "ok")).
+		Msg("hello intern")
+
+	if s := buf.String(); !strings.Contains(s, `"s":"x\ty","i":-1,"i64":64,"u64":42,"f":1.5,"b":true,"status":"ok","message":"hello intern"}`) {
+		t.Errorf("intern entry mismatch: %s", s)
+	}
+
+	var e *Entry
+	e.KeyStr(Intern("s"), "").KeyInt(Intern("i"), 0).Field(InternField("a", "b")).Msg("nil entry")
+}
+
+func BenchmarkLoggerIntern(b *testing.B) {
+	logger := Logger{Writer: IOWriter{ioutil.Discard}}
+	keyFoo, keyN, status := Intern("foo"), Intern("n"), InternField("status", "ok")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().KeyStr(keyFoo, "bar").KeyInt(keyN, 42).Field(status).Msg("hello world")
+	}
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"runtime"
+)
+
+// SaramaLogger implements methods to satisfy interface
+// github.com/IBM/sarama.StdLogger, which is set to sarama.Logger or sarama.DebugLogger.
+type SaramaLogger struct {
+	logger  Logger
+	level   Level
+	context Context
+}
+
+// Sarama wraps the Logger to provide a sarama logger, which logs at level, e.g. InfoLevel for
+// sarama.Logger and DebugLevel for sarama.DebugLogger.
+func (l *Logger) Sarama(level Level, context Context) *SaramaLogger {
+	return &SaramaLogger{
+		logger:  *l,
+		level:   level,
+		context: context,
+	}
+}
+
+// Print logs a message. Arguments are handled in the manner of fmt.Print.
+func (s *SaramaLogger) Print(args ...interface{}) {
+	e := s.logger.header(s.level)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller))
+	}
+	e.Context(s.context).Msgs(args...)
+}
+
+// Printf logs a message. Arguments are handled in the manner of fmt.Printf.
+func (s *SaramaLogger) Printf(format string, args ...interface{}) {
+	e := s.logger.header(s.level)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller))
+	}
+	e.Context(s.context).Msgf(format, args...)
+}
+
+// Println logs a message. Arguments are handled in the manner of fmt.Println.
+func (s *SaramaLogger) Println(args ...interface{}) {
+	e := s.logger.header(s.level)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller))
+	}
+	e.Context(s.context).Msgs(args...)
+}
+
+// KgoLogger implements methods to satisfy interface github.com/twmb/franz-go/pkg/kgo.Logger
+// by a thin wrapper, because the level of kgo is a named type.
+//
+//	type kgoLogger struct{ *log.KgoLogger }
+//
+//	func (l kgoLogger) Level() kgo.LogLevel { return kgo.LogLevel(l.KgoLogger.Level()) }
+//	func (l kgoLogger) Log(level kgo.LogLevel, msg string, keyvals ...any) {
+//		l.KgoLogger.Log(int(level), msg, keyvals...)
+//	}
+//
+//	client, err := kgo.NewClient(kgo.WithLogger(kgoLogger{logger.Kgo(nil)}))
+type KgoLogger struct {
+	logger  Logger
+	context Context
+}
+
+// Kgo wraps the Logger to provide a kgo logger
+func (l *Logger) Kgo(context Context) *KgoLogger {
+	return &KgoLogger{
+		logger:  *l,
+		context: context,
+	}
+}
+
+// kgoLevels maps the levels of kgo, from LogLevelNone 0 to LogLevelDebug 4.
+var kgoLevels = [...]Level{noLevel, ErrorLevel, WarnLevel, InfoLevel, DebugLevel}
+
+// Level returns the kgo level of Logger.Level, so kgo skips building the disabled messages.
+func (k *KgoLogger) Level() int {
+	for i := len(kgoLevels) - 1; i > 0; i-- {
+		if kgoLevels[i] >= k.logger.Level {
+			return i
+		}
+	}
+	return 0
+}
+
+// Log logs a kgo message with the key/value pairs at the level of kgo.
+func (k *KgoLogger) Log(level int, msg string, keyvals ...interface{}) {
+	if level <= 0 || level >= len(kgoLevels) {
+		return
+	}
+	e := k.logger.header(kgoLevels[level])
+	if e == nil {
+		return
+	}
+	if k.logger.Caller > 0 {
+		e.caller(runtime.Caller(k.logger.Caller))
+	}
+	e.KeysAndValues(keyvals...).Context(k.context).Msg(msg)
+}
@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type saramaStdLogger interface {
+	Print(v ...interface{})
+	Printf(format string, v ...interface{})
+	Println(v ...interface{})
+}
+
+func TestSaramaLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+	}
+
+	var sarama saramaStdLogger = logger.Sarama(InfoLevel, NewContext(nil).Str("tag", "sarama").Value())
+	sarama.Print("client/metadata fetching metadata for all topics from broker ", "localhost:9092")
+	sarama.Printf("Connected to broker at %s (unregistered)", "localhost:9092")
+	sarama.Println("Closing Client")
+	logger.Sarama(DebugLevel, nil).Println("debug message")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("sarama logger should write 3 entries: %s", buf.String())
+	}
+	if s := lines[1]; !strings.Contains(s, `"level":"info","tag":"sarama","message":"Connected to broker at localhost:9092 (unregistered)"}`) {
+		t.Errorf("sarama logger output mismatch: %s", s)
+	}
+}
+
+func TestKgoLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+	}
+
+	kgo := logger.Kgo(nil)
+	if level := kgo.Level(); level != 3 {
+		t.Errorf("kgo logger level should be info(3), got %d", level)
+	}
+	kgo.Log(2, "unable to open connection to broker", "addr", "localhost:9092", "broker", 1)
+	kgo.Log(4, "wrote Metadata v12")
+	kgo.Log(0, "none")
+
+	if s := strings.TrimSpace(buf.String()); strings.Count(s, "\n") != 0 || !strings.Contains(s, `"level":"warn","addr":"localhost:9092","broker":1,"message":"unable to open connection to broker"}`) {
+		t.Errorf("kgo logger output mismatch: %s", s)
+	}
+
+	for level, want := range map[Level]int{0: 4, TraceLevel: 4, WarnLevel: 2, ErrorLevel: 1, FatalLevel: 0} {
+		logger.Level = level
+		if got := logger.Kgo(nil).Level(); got != want {
+			t.Errorf("kgo logger level of %v should be %d, got %d", level, want, got)
+		}
+	}
+}
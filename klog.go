@@ -0,0 +1,152 @@
+package log
+
+import (
+	"io"
+	"strconv"
+)
+
+// KlogWriter is an io.Writer that parses the lines of k8s.io/klog and writes them as leveled
+// entries to Logger, so that the operators and controllers get one structured log stream
+// including the client-go internals. The severity and the file:line of klog header are parsed,
+// and the key/value pairs of structured klog calls like klog.InfoS are parsed into fields.
+//
+//	klog.LogToStderr(false)
+//	klog.SetOutputBySeverity("INFO", &log.KlogWriter{Logger: logger})
+//
+// klog writes an entry to the writers of its severity and all lower severities, so only the
+// INFO writer should be set, or use klog.SetOutput instead. The fatal lines are logged at error
+// level, because klog dumps the stacks and exits by itself.
+type KlogWriter struct {
+	// Logger specifies the logger which the entries are written to.
+	Logger Logger
+
+	// Context specifies the contextual fields of entries.
+	Context Context
+}
+
+// Write implements io.Writer.
+func (w *KlogWriter) Write(p []byte) (int, error) {
+	msg := p
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+
+	level, caller, msg := parseKlogHeader(msg)
+
+	e := w.Logger.header(level)
+	if e == nil {
+		return len(p), nil
+	}
+	if w.Logger.Caller > 0 && caller != nil {
+		e.Str("caller", b2s(caller))
+	}
+	// structured klog lines are like "message" key1="value" key2=123
+	if len(msg) > 0 && msg[0] == '"' {
+		if s, rest, ok := klogQuoted(msg); ok {
+			klogKeysAndValues(e, rest)
+			e.Context(w.Context).Msg(s)
+			return len(p), nil
+		}
+	}
+	e.Context(w.Context).Msg(b2s(msg))
+	return len(p), nil
+}
+
+// parseKlogHeader parses the klog header "Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg".
+// It returns InfoLevel and the original message if the header is absent, e.g. skip_headers.
+func parseKlogHeader(p []byte) (level Level, caller, msg []byte) {
+	level, msg = InfoLevel, p
+	if len(p) < 22 || p[5] != ' ' || p[8] != ':' || p[11] != ':' {
+		return
+	}
+	var l Level
+	switch p[0] {
+	case 'I':
+		l = InfoLevel
+	case 'W':
+		l = WarnLevel
+	case 'E', 'F':
+		// klog exits by itself after writing fatal lines with the stack traces.
+		l = ErrorLevel
+	default:
+		return
+	}
+	i := 6
+	for i < len(p) && p[i] != ' ' {
+		i++
+	}
+	// threadid is right aligned
+	for i < len(p) && p[i] == ' ' {
+		i++
+	}
+	for i < len(p) && p[i] >= '0' && p[i] <= '9' {
+		i++
+	}
+	if i == len(p) || p[i] != ' ' {
+		return
+	}
+	i++
+	j := i
+	for j < len(p) && p[j] != ']' && p[j] != ' ' {
+		j++
+	}
+	if j == len(p) || p[j] != ']' {
+		return
+	}
+	caller, msg = p[i:j], p[j+1:]
+	if len(msg) > 0 && msg[0] == ' ' {
+		msg = msg[1:]
+	}
+	return l, caller, msg
+}
+
+// klogQuoted unquotes the leading quoted string of p.
+func klogQuoted(p []byte) (s string, rest []byte, ok bool) {
+	for i := 1; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '"':
+			s, err := strconv.Unquote(b2s(p[:i+1]))
+			if err != nil {
+				return "", nil, false
+			}
+			return s, p[i+1:], true
+		}
+	}
+	return "", nil, false
+}
+
+// klogKeysAndValues appends the key=value pairs of p as string fields, values are quoted or bare.
+func klogKeysAndValues(e *Entry, p []byte) {
+	for len(p) > 0 {
+		for len(p) > 0 && p[0] == ' ' {
+			p = p[1:]
+		}
+		i := 0
+		for i < len(p) && p[i] != '=' && p[i] != ' ' {
+			i++
+		}
+		if i == 0 || i == len(p) || p[i] != '=' {
+			return
+		}
+		key, value := p[:i], p[i+1:]
+		if len(value) > 0 && value[0] == '"' {
+			s, rest, ok := klogQuoted(value)
+			if !ok {
+				return
+			}
+			e.Str(b2s(key), s)
+			p = rest
+			continue
+		}
+		j := 0
+		for j < len(value) && value[j] != ' ' {
+			j++
+		}
+		e.Str(b2s(key), b2s(value[:j]))
+		p = value[j:]
+	}
+}
+
+var _ io.Writer = (*KlogWriter)(nil)
@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestKlogWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &KlogWriter{
+		Logger: Logger{
+			Level:  DebugLevel,
+			Caller: 1,
+			Writer: IOWriter{&buf},
+		},
+		Context: NewContext(nil).Str("tag", "klog").Value(),
+	}
+
+	cases := []struct {
+		Text   string
+		Output string
+	}{
+		{
+			"I1015 07:53:40.029123       1 leaderelection.go:250] attempting to acquire leader lease\n",
+			`"level":"info","caller":"leaderelection.go:250","tag":"klog","message":"attempting to acquire leader lease"}`,
+		},
+		{
+			"W1015 07:53:40.029123   12345 reflector.go:539] failed to list *v1.Pod: connection refused\n",
+			`"level":"warn","caller":"reflector.go:539","tag":"klog","message":"failed to list *v1.Pod: connection refused"}`,
+		},
+		{
+			"E1015 07:53:40.029123   12345 controller.go:42] \"Reconciler error\" err=\"not found\" controller=\"pod\" retries=3\n",
+			`"level":"error","caller":"controller.go:42","err":"not found","controller":"pod","retries":"3","tag":"klog","message":"Reconciler error"}`,
+		},
+		{
+			"F1015 07:53:40.029123   12345 main.go:7] fatal error\n",
+			`"level":"error","caller":"main.go:7","tag":"klog","message":"fatal error"}`,
+		},
+		{
+			"\"Starting workers\" count=2\n",
+			`"level":"info","count":"2","tag":"klog","message":"Starting workers"}`,
+		},
+		{
+			"plain text without header\n",
+			`"level":"info","tag":"klog","message":"plain text without header"}`,
+		},
+	}
+
+	for _, c := range cases {
+		buf.Reset()
+		fmt.Fprint(w, c.Text)
+		if s := buf.String(); !strings.Contains(s, c.Output) {
+			t.Errorf("klog writer output of %#v mismatch: %s", c.Text, s)
+		}
+	}
+}
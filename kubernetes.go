@@ -0,0 +1,113 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KubernetesHook is a Hook that adds the Kubernetes and container metadata to entries as an
+// object, so the entries are attributable without the enrichment of log collectors, e.g.
+//
+//	"kubernetes":{"pod":"web-7d4b9c-x2x9p","namespace":"default","node":"node-1","container_id":"3f2a..."}
+//
+// The pod name, namespace and node are read from the environment variables POD_NAME, POD_NAMESPACE
+// and NODE_NAME, which are set by the downward API of pod spec, e.g.
+//
+//	env:
+//	- name: NODE_NAME
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: spec.nodeName
+//
+// The hostname and the namespace of service account are used if absent in a Kubernetes pod. The
+// container id is parsed from /proc/self/cgroup or /proc/self/mountinfo. The metadata is detected
+// on first use, and nothing is added outside of containers.
+type KubernetesHook struct {
+	// Key specifies the key of object, uses "kubernetes" if empty.
+	Key string
+
+	once    sync.Once
+	context Context
+}
+
+// Run implements Hook.
+func (h *KubernetesHook) Run(e *Entry, level Level, msg string) {
+	h.once.Do(func() {
+		key := h.Key
+		if key == "" {
+			key = "kubernetes"
+		}
+		h.context = kubernetesContext(key, os.Getenv, ioutil.ReadFile)
+	})
+	e.Context(h.context)
+}
+
+// kubernetesContext returns the metadata object of key, or nil if not in a container.
+func kubernetesContext(key string, getenv func(string) string, readfile func(string) ([]byte, error)) Context {
+	pod, namespace, node := getenv("POD_NAME"), getenv("POD_NAMESPACE"), getenv("NODE_NAME")
+	if getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if pod == "" {
+			pod = getenv("HOSTNAME")
+		}
+		if namespace == "" {
+			if b, err := readfile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+				namespace = string(bytes.TrimSpace(b))
+			}
+		}
+	}
+	var id string
+	if b, err := readfile("/proc/self/cgroup"); err == nil {
+		id = containerID(b, "/")
+	}
+	if id == "" {
+		if b, err := readfile("/proc/self/mountinfo"); err == nil {
+			id = containerID(b, "/containers/")
+		}
+	}
+	if pod == "" && namespace == "" && node == "" && id == "" {
+		return nil
+	}
+
+	e := NewContext(nil)
+	start := e.objectStart(key)
+	e.StrNonEmpty("pod", pod)
+	e.StrNonEmpty("namespace", namespace)
+	e.StrNonEmpty("node", node)
+	e.StrNonEmpty("container_id", id)
+	e.objectEnd(start)
+	return e.Value()
+}
+
+// containerID returns the first 64 hex digits id which follows sep in the lines of data, e.g.
+//
+//	12:pids:/kubepods/burstable/pod5b1d.../3f2a...
+//	0::/system.slice/docker-3f2a....scope
+//	1234 567 0:89 /var/lib/docker/containers/3f2a.../hostname /etc/hostname rw - ...
+func containerID(data []byte, sep string) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		for {
+			i := strings.Index(line, sep)
+			if i < 0 {
+				break
+			}
+			line = line[i+len(sep):]
+			s := line
+			if i := strings.IndexAny(s, "/ "); i >= 0 {
+				s = s[:i]
+			}
+			s = strings.TrimSuffix(s, ".scope")
+			if i := strings.LastIndexAny(s, "-:"); i >= 0 {
+				s = s[i+1:]
+			}
+			if len(s) == 64 && strings.Trim(s, "0123456789abcdef") == "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+var _ Hook = (*KubernetesHook)(nil)
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestKubernetesHook(t *testing.T) {
+	id := strings.Repeat("3f2a", 16)
+	env := map[string]string{
+		"KUBERNETES_SERVICE_HOST": "10.0.0.1",
+		"HOSTNAME":                "web-7d4b9c-x2x9p",
+		"NODE_NAME":               "node-1",
+	}
+	files := map[string]string{
+		"/var/run/secrets/kubernetes.io/serviceaccount/namespace": "default\n",
+		"/proc/self/cgroup": "12:pids:/kubepods/burstable/pod5b1d/" + id + "\n",
+	}
+	getenv := func(key string) string { return env[key] }
+	readfile := func(name string) ([]byte, error) {
+		if s, ok := files[name]; ok {
+			return []byte(s), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	ctx := kubernetesContext("kubernetes", getenv, readfile)
+	if s := string(ctx); s != `,"kubernetes":{"pod":"web-7d4b9c-x2x9p","namespace":"default","node":"node-1","container_id":"`+id+`"}` {
+		t.Errorf("kubernetes context mismatch: %s", s)
+	}
+
+	env = map[string]string{"POD_NAME": "job-1"}
+	files = map[string]string{"/proc/self/cgroup": "0::/\n"}
+	if s := string(kubernetesContext("k8s", getenv, readfile)); s != `,"k8s":{"pod":"job-1"}` {
+		t.Errorf("kubernetes context mismatch: %s", s)
+	}
+
+	env = nil
+	if ctx := kubernetesContext("k8s", getenv, readfile); ctx != nil {
+		t.Errorf("kubernetes context should be nil outside of containers: %s", ctx)
+	}
+
+	var buf bytes.Buffer
+	hook := &KubernetesHook{}
+	hook.once.Do(func() { hook.context = Context(`,"kubernetes":{"pod":"p"}`) })
+	logger := Logger{Hooks: []Hook{hook}, Writer: IOWriter{&buf}}
+	logger.Info().Str("a", "b").Msg("hello")
+	if s := buf.String(); !strings.Contains(s, `"a":"b","kubernetes":{"pod":"p"},"message":"hello"`) {
+		t.Errorf("kubernetes hook mismatch: %s", s)
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	id := strings.Repeat("0123456789abcdef", 4)
+	cases := []struct {
+		Data string
+		Sep  string
+		ID   string
+	}{
+		{"12:pids:/kubepods/burstable/pod5b1d/" + id, "/", id},
+		{"0::/system.slice/docker-" + id + ".scope", "/", id},
+		{"1:name=systemd:/kubepods.slice/cri-containerd-" + id + ".scope\n", "/", id},
+		{"0::/kubepods/crio-" + id, "/", id},
+		{"0::/\n", "/", ""},
+		{"1234 567 0:89 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw - ext4", "/containers/", id},
+		{"1234 567 0:89 /var/lib/kubelet/pods/x/etc-hosts /etc/hosts rw - ext4", "/containers/", ""},
+	}
+	for _, c := range cases {
+		if got := containerID([]byte(c.Data), c.Sep); got != c.ID {
+			t.Errorf("containerID(%q) = %q, want %q", c.Data, got, c.ID)
+		}
+	}
+}
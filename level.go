@@ -1,5 +1,12 @@
 package log
 
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
 // Level defines log levels.
 type Level uint32
 
@@ -41,29 +48,192 @@ func (l Level) String() (s string) {
 		s = "panic"
 	default:
 		s = "????"
+		if info, ok := customLevel(l); ok {
+			s = info.Name
+		}
 	}
 	return
 }
 
-// ParseLevel converts a level string into a log Level value.
+// ParseLevel converts a level string into a log Level value. It is case-insensitive and accepts
+// the aliases of levels, i.e. warn/warning, err/error, the three letters e.g. "WRN" and the single
+// letters e.g. "W". It returns an invalid level for unknown inputs, use ParseLevelStrict to get an error.
 func ParseLevel(s string) (level Level) {
-	switch s {
-	case "trace", "Trace", "TRACE", "TRC":
+	if level = builtinLevel(s); level == noLevel {
+		level, _ = customLevelByName(s)
+	}
+	return
+}
+
+func builtinLevel(s string) (level Level) {
+	// lower the ascii letters on stack to avoid allocations.
+	var b [8]byte
+	if len(s) > len(b) {
+		return noLevel
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b[i] = c
+	}
+	switch b2s(b[:len(s)]) {
+	case "trace", "trc", "t":
 		level = TraceLevel
-	case "debug", "Debug", "DEBUG", "DBG":
+	case "debug", "dbg", "d":
 		level = DebugLevel
-	case "info", "Info", "INFO", "INF":
+	case "info", "inf", "i":
 		level = InfoLevel
-	case "warn", "Warn", "WARN", "warning", "Warning", "WARNING", "WRN":
+	case "warn", "warning", "wrn", "w":
 		level = WarnLevel
-	case "error", "Error", "ERROR", "ERR":
+	case "error", "err", "e":
 		level = ErrorLevel
-	case "fatal", "Fatal", "FATAL", "FTL":
+	case "fatal", "ftl", "f":
 		level = FatalLevel
-	case "panic", "Panic", "PANIC", "PNC":
+	case "panic", "pnc", "p":
 		level = PanicLevel
 	default:
 		level = noLevel
 	}
 	return
 }
+
+// ParseLevelStrict is like ParseLevel but returns an error for unknown inputs instead of an invalid level.
+func ParseLevelStrict(s string) (Level, error) {
+	level := ParseLevel(s)
+	if level == noLevel {
+		return level, errors.New("log: unknown level " + strconv.Quote(s))
+	}
+	return level, nil
+}
+
+// MustParseLevel is like ParseLevelStrict but panics if s is an unknown level.
+// It simplifies safe initialization of levels from flags and configs.
+func MustParseLevel(s string) Level {
+	level, err := ParseLevelStrict(s)
+	if err != nil {
+		panic(err)
+	}
+	return level
+}
+
+// LookupLevel converts a level string into a log Level value, and reports whether it is
+// a known level, e.g. LookupLevel("hahaha") returns false.
+func LookupLevel(s string) (Level, bool) {
+	level := ParseLevel(s)
+	return level, level != noLevel
+}
+
+// LevelInfo describes a custom level registered by RegisterLevel.
+type LevelInfo struct {
+	// Name is the lower case name of level in output, e.g. "notice".
+	Name string
+
+	// Short is the three letters of level in ConsoleWriter, e.g. "NTC".
+	// It uses the first three letters of Name in upper case if empty.
+	Short string
+
+	// Color is the ANSI color of level in ConsoleWriter, e.g. "\x1b[34m". It uses gray if empty.
+	Color string
+
+	// SyslogSeverity is the severity of level in SyslogWriter, from 1 (LOG_ALERT) to 7 (LOG_DEBUG),
+	// e.g. 5 (LOG_NOTICE). It uses 6 (LOG_INFO) if zero.
+	SyslogSeverity int
+
+	// field is the pre-encoded level field, i.e. ,"level":"name"
+	field string
+}
+
+var customLevels struct {
+	sync.RWMutex
+	levels map[Level]LevelInfo
+}
+
+// RegisterLevel registers a custom level, e.g. Notice, Audit or Security, which is logged by
+// Logger.WithLevel and respected by ParseLevel, ConsoleWriter, EncoderWriter and SyslogWriter.
+//
+// The custom levels always rank above the built-in levels and cannot be placed between them,
+// e.g. a Notice level between InfoLevel and WarnLevel is not supported, so level must be greater
+// than PanicLevel+1 or an error is returned. Setting Logger.Level to a custom level therefore
+// filters out all of the built-in levels, including ErrorLevel, FatalLevel and PanicLevel.
+func RegisterLevel(level Level, info LevelInfo) error {
+	if level <= noLevel {
+		return errors.New("log: custom level must be greater than " + strconv.Itoa(int(noLevel)))
+	}
+	if info.Name == "" || builtinLevel(info.Name) != noLevel {
+		return errors.New("log: invalid or duplicate level name " + strconv.Quote(info.Name))
+	}
+	if info.Short == "" {
+		info.Short = info.Name
+		if len(info.Short) > 3 {
+			info.Short = info.Short[:3]
+		}
+		info.Short = strings.ToUpper(info.Short)
+	}
+	if builtinLevel(info.Short) != noLevel {
+		return errors.New("log: duplicate level short name " + strconv.Quote(info.Short))
+	}
+	if info.SyslogSeverity <= 0 || info.SyslogSeverity > 7 {
+		info.SyslogSeverity = 6
+	}
+	info.field = ",\"level\":" + strconv.Quote(info.Name)
+
+	customLevels.Lock()
+	defer customLevels.Unlock()
+	if _, ok := customLevels.levels[level]; ok {
+		return errors.New("log: duplicate level " + strconv.Itoa(int(level)))
+	}
+	if _, ok := lookupCustomLevel(info.Name); ok {
+		return errors.New("log: invalid or duplicate level name " + strconv.Quote(info.Name))
+	}
+	if _, ok := lookupCustomLevel(info.Short); ok {
+		return errors.New("log: duplicate level short name " + strconv.Quote(info.Short))
+	}
+	if customLevels.levels == nil {
+		customLevels.levels = make(map[Level]LevelInfo)
+	}
+	customLevels.levels[level] = info
+	return nil
+}
+
+func customLevel(level Level) (info LevelInfo, ok bool) {
+	if level <= noLevel {
+		return
+	}
+	customLevels.RLock()
+	info, ok = customLevels.levels[level]
+	customLevels.RUnlock()
+	return
+}
+
+func customLevelByName(name string) (Level, bool) {
+	customLevels.RLock()
+	defer customLevels.RUnlock()
+	return lookupCustomLevel(name)
+}
+
+// lookupCustomLevel must be called with customLevels locked.
+func lookupCustomLevel(name string) (Level, bool) {
+	for level, info := range customLevels.levels {
+		if strings.EqualFold(info.Name, name) || strings.EqualFold(info.Short, name) {
+			return level, true
+		}
+	}
+	return noLevel, false
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevelStrict(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
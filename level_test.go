@@ -1,6 +1,10 @@
 package log
 
 import (
+	"bytes"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -28,3 +32,157 @@ func TestLevelParse(t *testing.T) {
 		}
 	}
 }
+
+func TestLevelParseAlias(t *testing.T) {
+	cases := []struct {
+		Level   Level
+		Strings []string
+	}{
+		{TraceLevel, []string{"trace", "TrAcE", "trc", "T"}},
+		{DebugLevel, []string{"Debug", "DBG", "d"}},
+		{InfoLevel, []string{"INFO", "inf", "I"}},
+		{WarnLevel, []string{"warn", "Warning", "WARNING", "wrn", "w"}},
+		{ErrorLevel, []string{"ERROR", "err", "Err", "e"}},
+		{FatalLevel, []string{"fatal", "FTL", "F"}},
+		{PanicLevel, []string{"PANIC", "pnc", "p"}},
+		{noLevel, []string{"", "x", "warnings", "informational"}},
+	}
+
+	for _, c := range cases {
+		for _, s := range c.Strings {
+			if v := ParseLevel(s); v != c.Level {
+				t.Errorf("ParseLevel(%#v) must return %#v, not %#v", s, c.Level, v)
+			}
+		}
+	}
+
+	if n := testing.AllocsPerRun(100, func() { ParseLevel("Warning") }); n != 0 {
+		t.Errorf("ParseLevel should not allocate, got %v", n)
+	}
+}
+
+func TestLevelParseStrict(t *testing.T) {
+	if level, err := ParseLevelStrict("Warning"); err != nil || level != WarnLevel {
+		t.Errorf("ParseLevelStrict mismatch: %v, %+v", level, err)
+	}
+	if _, err := ParseLevelStrict("verbose"); err == nil || err.Error() != `log: unknown level "verbose"` {
+		t.Errorf("ParseLevelStrict should fail on unknown level: %+v", err)
+	}
+
+	if level := MustParseLevel("err"); level != ErrorLevel {
+		t.Errorf("MustParseLevel mismatch: %v", level)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustParseLevel should panic on unknown level")
+		}
+	}()
+	MustParseLevel("verbose")
+}
+
+func TestLevelText(t *testing.T) {
+	var level Level
+	if err := level.UnmarshalText([]byte("WARN")); err != nil || level != WarnLevel {
+		t.Errorf("Level.UnmarshalText mismatch: %v, %+v", level, err)
+	}
+	if err := level.UnmarshalText([]byte("verbose")); err == nil {
+		t.Errorf("Level.UnmarshalText should fail on unknown level")
+	}
+	if b, _ := InfoLevel.MarshalText(); string(b) != "info" {
+		t.Errorf("Level.MarshalText mismatch: %s", b)
+	}
+}
+
+func TestLevelCustom(t *testing.T) {
+	const NoticeLevel, AuditLevel Level = 20, 21
+	defer func() {
+		customLevels.Lock()
+		delete(customLevels.levels, NoticeLevel)
+		delete(customLevels.levels, AuditLevel)
+		customLevels.Unlock()
+	}()
+
+	if err := RegisterLevel(NoticeLevel, LevelInfo{Name: "notice", Color: "\x1b[34m", SyslogSeverity: 5}); err != nil {
+		t.Fatalf("register level error: %+v", err)
+	}
+	if err := RegisterLevel(AuditLevel, LevelInfo{Name: "audit", Short: "AUD"}); err != nil {
+		t.Fatalf("register level error: %+v", err)
+	}
+	for _, c := range []struct {
+		Level Level
+		Info  LevelInfo
+	}{
+		{5, LevelInfo{Name: "low"}},
+		{22, LevelInfo{Name: "info"}},
+		{22, LevelInfo{Name: "notice"}},
+		{22, LevelInfo{}},
+		{NoticeLevel, LevelInfo{Name: "other"}},
+	} {
+		if err := RegisterLevel(c.Level, c.Info); err == nil {
+			t.Errorf("register level %d %+v should fail", c.Level, c.Info)
+		}
+	}
+
+	var wg sync.WaitGroup
+	var registered int32
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(level Level) {
+			defer wg.Done()
+			if RegisterLevel(level, LevelInfo{Name: "security"}) == nil {
+				atomic.AddInt32(&registered, 1)
+			}
+		}(Level(30 + i))
+	}
+	wg.Wait()
+	customLevels.Lock()
+	for i := 0; i < 8; i++ {
+		delete(customLevels.levels, Level(30+i))
+	}
+	customLevels.Unlock()
+	if registered != 1 {
+		t.Errorf("concurrent register level of the same name should succeed once: %d", registered)
+	}
+
+	if s := NoticeLevel.String(); s != "notice" {
+		t.Errorf("custom level string mismatch: %s", s)
+	}
+	for _, s := range []string{"notice", "NOTICE", "NOT"} {
+		if level, ok := LookupLevel(s); !ok || level != NoticeLevel {
+			t.Errorf("LookupLevel(%q) mismatch: %v %v", s, level, ok)
+		}
+	}
+	if _, ok := LookupLevel("hahaha"); ok {
+		t.Errorf("LookupLevel should fail on unknown level")
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Level: ErrorLevel, Writer: IOWriter{&buf}}
+	logger.WithLevel(NoticeLevel).Msg("hello notice")
+	if s := buf.String(); !strings.Contains(s, `"level":"notice","message":"hello notice"`) {
+		t.Errorf("custom level entry mismatch: %s", s)
+	}
+
+	buf.Reset()
+	w := &ConsoleWriter{ColorOutput: true, Writer: &buf}
+	wlprintf(w, AuditLevel, `{"time":"2019-07-10T05:35:54.277Z","level":"audit","message":"hello audit"}`)
+	wlprintf(w, NoticeLevel, `{"time":"2019-07-10T05:35:54.277Z","level":"notice","message":"hello notice"}`)
+	if s := buf.String(); !strings.Contains(s, "AUD") || !strings.Contains(s, "\x1b[34mNOT") {
+		t.Errorf("custom level console mismatch: %q", s)
+	}
+
+	buf.Reset()
+	logger = Logger{Writer: &EncoderWriter{
+		EncoderConfig: EncoderConfig{LevelEncoding: LevelEncodingNumber},
+		Writer:        IOWriter{&buf},
+	}}
+	logger.WithLevel(AuditLevel).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"level":21`) {
+		t.Errorf("custom level encoder mismatch: %s", s)
+	}
+
+	var syslog SyslogWriter
+	if b := syslog.appendHeader(nil, &Entry{Level: NoticeLevel}); string(b[:3]) != "<5>" {
+		t.Errorf("custom level syslog mismatch: %s", b)
+	}
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// limitReserved is the bytes reserved for the marker fields and the ending of limited entries,
+// i.e. ,"part":999,"parts":999,"truncated":true}\n
+const limitReserved = 48
+
+// writeLimited writes the entry larger than maxEntry bytes, it drops the fields which do not fit
+// with a "truncated":true marker, or splits them into several entries if splitEntry is set. The
+// time and level fields and the message are kept in every entry, the message is truncated if needed.
+func (e *Entry) writeLimited() {
+	b := bbget()
+	defer bbput(b)
+	b.B = append(b.B, e.buf...)
+
+	// the fields are "key":value slices of b.B
+	var head, fields [][]byte
+	var message []byte
+	jsonObjectEach(b.B, func(key, value []byte, typ byte) {
+		start := cap(b.B) - cap(key) - 1
+		end := cap(b.B) - cap(value) + len(value)
+		field := b.B[start:end]
+		switch {
+		case len(head) == 0 || b2s(key) == "level":
+			head = append(head, field)
+		case b2s(key) == "message":
+			message = field
+		default:
+			fields = append(fields, field)
+		}
+	})
+
+	size := 1
+	for _, f := range head {
+		size += len(f) + 1
+	}
+	truncated := false
+	if message != nil {
+		if room := e.maxEntry - size - limitReserved; len(message) > room {
+			message, truncated = truncateField(message, room), true
+		}
+		if message != nil {
+			size += len(message) + 1
+		}
+	}
+
+	// pack the fields into parts greedily, the fields larger than a part are dropped.
+	avail := e.maxEntry - size - limitReserved
+	var parts [][][]byte
+	var part [][]byte
+	n := 0
+	for _, f := range fields {
+		if len(f)+1 > avail {
+			truncated = true
+			continue
+		}
+		if n+len(f)+1 > avail {
+			if !e.splitEntry {
+				truncated = true
+				continue
+			}
+			parts = append(parts, part)
+			part, n = nil, 0
+		}
+		part = append(part, f)
+		n += len(f) + 1
+	}
+	parts = append(parts, part)
+
+	for i, part := range parts {
+		e.buf = append(e.buf[:0], '{')
+		for j, f := range head {
+			if j > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			e.buf = append(e.buf, f...)
+		}
+		for _, f := range part {
+			e.buf = append(e.buf, ',')
+			e.buf = append(e.buf, f...)
+		}
+		if message != nil {
+			e.buf = append(e.buf, ',')
+			e.buf = append(e.buf, message...)
+		}
+		if len(parts) > 1 {
+			e.buf = append(e.buf, ",\"part\":"...)
+			e.buf = strconv.AppendInt(e.buf, int64(i+1), 10)
+			e.buf = append(e.buf, ",\"parts\":"...)
+			e.buf = strconv.AppendInt(e.buf, int64(len(parts)), 10)
+		}
+		if truncated {
+			e.buf = append(e.buf, ",\"truncated\":true"...)
+		}
+		e.buf = append(e.buf, '}', '\n')
+		e.w.WriteEntry(e)
+	}
+}
+
+// truncateField truncates the "message":"..." field to n bytes in place at the boundary of
+// escapes and runes. It returns nil if n is too small.
+func truncateField(field []byte, n int) []byte {
+	const prefix = len(`"message":"`)
+	if n <= prefix {
+		return nil
+	}
+	i := prefix
+	for i < n-1 {
+		step := 1
+		if field[i] == '\\' {
+			step = 2
+			if field[i+1] == 'u' {
+				step = 6
+			}
+		}
+		if i+step > n-1 {
+			break
+		}
+		i += step
+	}
+	for i > prefix && !utf8.RuneStart(field[i]) {
+		i--
+	}
+	field[i] = '"'
+	return field[:i+1]
+}
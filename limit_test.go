@@ -0,0 +1,78 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoggerMaxEntryBytes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		MaxEntryBytes: 160,
+		Writer:        IOWriter{&buf},
+	}
+
+	logger.Info().Str("a", "short").Msg("hello")
+	if s := buf.String(); strings.Contains(s, "truncated") || !strings.Contains(s, `"a":"short","message":"hello"}`) {
+		t.Errorf("small entry should not be truncated: %s", s)
+	}
+
+	buf.Reset()
+	logger.Info().Str("a", "short").Str("big", strings.Repeat("x", 200)).Int("n", 42).Msg("hello")
+	s := buf.String()
+	if len(s) > logger.MaxEntryBytes || !json.Valid(buf.Bytes()) {
+		t.Fatalf("truncated entry invalid: %d %s", len(s), s)
+	}
+	if !strings.Contains(s, `"level":"info","a":"short","n":42,"message":"hello","truncated":true}`) {
+		t.Errorf("truncated entry mismatch: %s", s)
+	}
+
+	buf.Reset()
+	logger.Info().Msg(strings.Repeat("\"世界\n", 100))
+	s = buf.String()
+	if len(s) > logger.MaxEntryBytes || !json.Valid(buf.Bytes()) || !strings.Contains(s, `"truncated":true}`) {
+		t.Errorf("truncated message invalid: %d %s", len(s), s)
+	}
+}
+
+func TestLoggerSplitEntry(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		MaxEntryBytes: 160,
+		SplitEntry:    true,
+		Writer:        IOWriter{&buf},
+	}
+
+	logger.Info().Str("a", strings.Repeat("a", 30)).Str("b", strings.Repeat("b", 30)).Str("c", strings.Repeat("c", 30)).Msg("hello")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("entry should be split: %q", lines)
+	}
+	var all string
+	for i, line := range lines {
+		var entry struct {
+			Level   string `json:"level"`
+			Message string `json:"message"`
+			Part    int    `json:"part"`
+			Parts   int    `json:"parts"`
+		}
+		if len(line)+1 > logger.MaxEntryBytes {
+			t.Errorf("split entry too large: %d %s", len(line), line)
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("split entry invalid: %+v %s", err, line)
+		}
+		if entry.Level != "info" || entry.Message != "hello" || entry.Part != i+1 || entry.Parts != len(lines) {
+			t.Errorf("split entry mismatch: %s", line)
+		}
+		all += line
+	}
+	for _, key := range []string{`"a":`, `"b":`, `"c":`} {
+		if strings.Count(all, key) != 1 {
+			t.Errorf("split entries should contain %s once: %q", key, lines)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// LineBuilder accumulates the fields of a request from many call sites during its lifetime, and
+// emits them as one wide entry at the end, i.e. the canonical log lines pattern, which greatly
+// reduces the log volume of high-traffic services, e.g.
+//
+//	b := log.NewLineBuilder(&logger)
+//	defer b.Emit(log.InfoLevel, "canonical-log-line")
+//	ctx = log.WithLineBuilder(ctx, b)
+//	...
+//	log.LineBuilderFromContext(ctx).Add(func(e *log.Entry) { e.Str("user_id", id).Int("rows", n) })
+//
+// It is safe for concurrent use. The fields are written in the order added, and the keys are
+// not deduplicated. The methods of a nil LineBuilder are no-ops.
+type LineBuilder struct {
+	logger  Logger
+	start   time.Time
+	mu      sync.Mutex
+	fields  Entry
+	emitted bool
+}
+
+// NewLineBuilder returns a LineBuilder which emits the entry to logger.
+func NewLineBuilder(logger *Logger) *LineBuilder {
+	return &LineBuilder{
+		logger: *logger,
+		start:  timeNow(),
+	}
+}
+
+// Add adds the fields by fn, e.g. b.Add(func(e *log.Entry) { e.Str("route", route) }).
+// The fields added after Emit are ignored.
+func (b *LineBuilder) Add(fn func(e *Entry)) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.emitted {
+		return
+	}
+	fn(&b.fields)
+	if len(b.fields.groups) != 0 {
+		b.fields.closeGroups()
+	}
+}
+
+// Emit writes the accumulated fields and the "duration" since NewLineBuilder as one entry.
+// It only emits once, the subsequent calls are no-ops.
+func (b *LineBuilder) Emit(level Level, msg string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.emitted {
+		return
+	}
+	b.emitted = true
+
+	e := b.logger.header(level)
+	if e == nil {
+		return
+	}
+	if b.logger.Caller > 0 {
+		e.caller(runtime.Caller(b.logger.Caller))
+	}
+	e.Context(b.fields.buf).Dur("duration", timeNow().Sub(b.start)).Msg(msg)
+}
+
+type lineBuilderContextKey struct{}
+
+// WithLineBuilder returns a copy of ctx in which the LineBuilder is associated.
+func WithLineBuilder(ctx context.Context, b *LineBuilder) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, lineBuilderContextKey{}, b)
+}
+
+// LineBuilderFromContext returns the LineBuilder associated with ctx, or nil if none.
+func LineBuilderFromContext(ctx context.Context) *LineBuilder {
+	if ctx == nil {
+		return nil
+	}
+	b, _ := ctx.Value(lineBuilderContextKey{}).(*LineBuilder)
+	return b
+}
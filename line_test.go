@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLineBuilder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+
+	b := NewLineBuilder(&logger)
+	ctx := WithLineBuilder(context.Background(), b)
+
+	LineBuilderFromContext(ctx).Add(func(e *Entry) {
+		e.Str("method", "GET").Str("path", "/users")
+	})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			LineBuilderFromContext(ctx).Add(func(e *Entry) { e.Int("shard", 1) })
+		}()
+	}
+	wg.Wait()
+	LineBuilderFromContext(ctx).Add(func(e *Entry) {
+		e.Err(errors.New("not found")).Dict("db", NewContext(nil).Int("rows", 0).Value())
+	})
+
+	if buf.Len() != 0 {
+		t.Fatalf("line builder should not write before Emit: %s", buf.String())
+	}
+
+	b.Emit(WarnLevel, "canonical-log-line")
+	b.Emit(WarnLevel, "canonical-log-line")
+	b.Add(func(e *Entry) { e.Str("late", "field") })
+
+	s := buf.String()
+	if strings.Count(s, "\n") != 1 {
+		t.Fatalf("line builder should emit once: %s", s)
+	}
+	if !strings.Contains(s, `"level":"warn","method":"GET","path":"/users","shard":1,"shard":1,"shard":1,"shard":1,"error":"not found","db":{"rows":0},"duration":`) ||
+		!strings.HasSuffix(s, `,"message":"canonical-log-line"}`+"\n") {
+		t.Errorf("line builder output mismatch: %s", s)
+	}
+}
+
+func TestLineBuilderNil(t *testing.T) {
+	b := LineBuilderFromContext(context.Background())
+	if b != nil {
+		t.Fatalf("LineBuilderFromContext should return nil if none")
+	}
+	b.Add(func(e *Entry) { e.Str("foo", "bar") })
+	b.Emit(InfoLevel, "noop")
+	if LineBuilderFromContext(nil) != nil {
+		t.Errorf("LineBuilderFromContext(nil) should return nil")
+	}
+}
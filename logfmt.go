@@ -0,0 +1,90 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// LogfmtWriter is an Writer that writes entries as logfmt key=value pairs to Writer,
+// e.g. `time=2019-07-10T05:35:54.277Z level=info foo=bar message="hello world"`.
+type LogfmtWriter struct {
+	// Writer is the output destination. using os.Stderr if empty.
+	Writer io.Writer
+}
+
+// Close implements io.Closer, will closes the underlying Writer if not empty.
+func (w *LogfmtWriter) Close() (err error) {
+	if w.Writer != nil {
+		if closer, ok := w.Writer.(io.Closer); ok {
+			err = closer.Close()
+		}
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *LogfmtWriter) WriteEntry(e *Entry) (int, error) {
+	return w.Write(e.buf)
+}
+
+// Write implements io.Writer.
+func (w *LogfmtWriter) Write(p []byte) (int, error) {
+	out := w.Writer
+	if out == nil {
+		out = os.Stderr
+	}
+
+	b := bbget()
+	defer bbput(b)
+
+	b.B = appendLogfmt(b.B, p)
+	b.B = append(b.B, '\n')
+
+	if _, err := out.Write(b.B); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendLogfmt appends the key/value pairs of json object to dst in logfmt format.
+func appendLogfmt(dst, json []byte) []byte {
+	first := true
+	jsonObjectEach(json, func(key, value []byte, typ byte) {
+		if !first {
+			dst = append(dst, ' ')
+		}
+		first = false
+		dst = append(dst, key...)
+		dst = append(dst, '=')
+		switch typ {
+		case 's', 'S':
+			s := value[1 : len(value)-1]
+			if typ == 'S' {
+				s = jsonUnescape(s, nil)
+			}
+			dst = appendLogfmtValue(dst, s)
+		case 'o':
+			dst = appendLogfmtValue(dst, value)
+		case 0:
+		default:
+			dst = append(dst, value...)
+		}
+	})
+	return dst
+}
+
+func appendLogfmtValue(dst, value []byte) []byte {
+	if len(value) == 0 {
+		return append(dst, '"', '"')
+	}
+	for _, c := range value {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c >= 0x7f {
+			return strconv.AppendQuote(dst, b2s(value))
+		}
+	}
+	return append(dst, value...)
+}
+
+var _ Writer = (*LogfmtWriter)(nil)
+var _ io.Writer = (*LogfmtWriter)(nil)
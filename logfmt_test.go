@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLogfmtWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		TimeFormat: TimeFormatUnix,
+		Writer:     &LogfmtWriter{Writer: &buf},
+	}
+
+	logger.Info().
+		Str("foo", "bar").
+		Str("empty", "").
+		Str("quote", "a \"b\"\n").
+		Err(errors.New("an error")).
+		Err(nil).
+		Int("n", 42).
+		Bool("ok", true).
+		Strs("tags", []string{"a", "b"}).
+		Msg("hello logfmt")
+
+	s := buf.String()
+	if i := bytes.IndexByte(buf.Bytes(), ' '); i < 0 || s[i:] != ` level=info foo=bar empty="" quote="a \"b\"\n" error="an error" error= n=42 ok=true tags="[\"a\",\"b\"]" message="hello logfmt"`+"\n" {
+		t.Errorf("logfmt writer output mismatch: %s", s)
+	}
+
+	p := []byte(`{"level":"info","message":"hello"}`)
+	if n, err := (&LogfmtWriter{Writer: &buf}).Write(p); err != nil || n != len(p) {
+		t.Errorf("logfmt writer should return the length of input: %d, %+v", n, err)
+	}
+}
@@ -1,6 +1,8 @@
 package log
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +15,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 	"unsafe"
 )
 
@@ -25,12 +28,47 @@ var DefaultLogger = Logger{
 	Writer:     IOWriter{os.Stderr},
 }
 
+var defaultLogger = unsafe.Pointer(&DefaultLogger)
+
+// Default returns the logger used by the package-level functions, which is DefaultLogger
+// unless SetDefault is called.
+func Default() *Logger {
+	return (*Logger)(atomic.LoadPointer(&defaultLogger))
+}
+
+// SetDefault atomically replaces the logger used by the package-level functions with a copy
+// of logger, so that libraries can log without plumbing a logger everywhere.
+func SetDefault(logger Logger) {
+	atomic.StorePointer(&defaultLogger, unsafe.Pointer(&logger))
+}
+
 // Entry represents a log entry. It is instanced by one of the level method of Logger and finalized by the Msg or Msgf method.
+//
+// Entries are pooled to be zero allocation: an Entry and its buffer are reused after Msg, Msgf
+// or Discard, so it must not be retained or used after that, nor shared across goroutines.
+// A Writer must not retain the entry or its buffer after WriteEntry returns, it copies them
+// if needed, e.g. AsyncWriter.
+//
+// The level methods of Logger return a nil Entry if the level is disabled, all methods of Entry
+// are no-op on nil, so a disabled call costs one atomic load of Logger.Level. Use Logger.Enabled
+// or Entry.Enabled to guard the expensive computation of fields.
 type Entry struct {
 	buf         []byte
 	Level       Level
 	w           Writer
 	loggerFiles []string
+	hooks       []Hook
+	hooking     bool
+	discarded   bool
+	exit        func(code int)
+	groups      []int
+	groupStart  int
+	escapeHTML  bool
+	maxValue    int
+	maxEntry    int
+	splitEntry  bool
+	// humans holds the offsets of the numeric keys of DurHuman and ByteSize in buf.
+	humans []int
 }
 
 // Writer defines an entry writer interface.
@@ -38,6 +76,19 @@ type Writer interface {
 	WriteEntry(*Entry) (int, error)
 }
 
+// EntryBatchWriter is implemented by the writers which can write many entries at once, e.g.
+// in one write or writev syscall. AsyncWriter uses it to flush the queued entries in batches.
+type EntryBatchWriter interface {
+	WriteEntries(entries []*Entry) (int, error)
+}
+
+// HealthChecker is implemented by the network writers to report the health of sinks,
+// e.g. in the readiness probes of applications.
+type HealthChecker interface {
+	// Healthy returns nil if the writer is healthy, otherwise the last error.
+	Healthy() error
+}
+
 // IOWriter wraps an io.Writer to Writer.
 type IOWriter struct {
 	io.Writer
@@ -48,6 +99,16 @@ func (w IOWriter) WriteEntry(e *Entry) (n int, err error) {
 	return w.Writer.Write(e.buf)
 }
 
+// WriteEntries implements EntryBatchWriter, uses writev if the Writer is a net.Conn.
+func (w IOWriter) WriteEntries(entries []*Entry) (n int, err error) {
+	buffers := make(net.Buffers, len(entries))
+	for i, e := range entries {
+		buffers[i] = e.buf
+	}
+	n64, err := buffers.WriteTo(w.Writer)
+	return int(n64), err
+}
+
 // LogObjectMarshaler provides a strongly-typed and encoding-agnostic interface
 // to be implemented by types used with Entry's Object methods.
 type LogObjectMarshaler interface {
@@ -69,10 +130,67 @@ type Logger struct {
 	// If set with `TimeFormatUnix`, `TimeFormatUnixMs`, times are formated as UNIX timestamp.
 	TimeFormat string
 
+	// TimeLocation specifies the location of times in output, e.g. time.UTC or a regional zone
+	// loaded by time.LoadLocation. It uses time.Local if nil.
+	TimeLocation *time.Location
+
 	// Writer specifies the writer of output. It uses a wrapped os.Stderr Writer in if empty.
 	Writer Writer
+
+	// Context specifies the contextual fields which are pinned to every entry.
+	Context Context
+
+	// Name specifies the logger name which MultiFileWriter routes entries on.
+	Name string
+
+	// Hooks specifies the hooks which run on every entry before the Writer.
+	Hooks []Hook
+
+	// FieldProviders specifies the functions which add the dynamic fields to every entry after
+	// Context, e.g. the tenant of goroutine, feature flags or memory usage. They run only for the
+	// enabled entries.
+	FieldProviders []FieldProvider
+
+	// ContextExtractor specifies an optional extractor that appends fields from the
+	// context.Context of logger which is returned by FromContext.
+	ContextExtractor ContextFieldExtractor
+
+	// ExitFunc specifies the function called by FatalLevel entries after writing,
+	// using os.Exit if nil.
+	ExitFunc func(code int)
+
+	// EscapeHTML determines if escapes '>', '&', U+2028 and U+2029 in strings additionally,
+	// so the output is safe to embed in HTML. '<' and '\'' are always escaped.
+	EscapeHTML bool
+
+	// MaxValueSize specifies the max bytes of string values and message, the longer ones are
+	// truncated with the suffix "...(truncated)". It is unlimited if zero.
+	MaxValueSize int
+
+	// MaxEntryBytes specifies the max bytes of entries, e.g. for UDP syslog and Kafka sinks which
+	// drop oversized messages silently. The fields of a longer entry are dropped with a marker of
+	// "truncated":true, or split into several entries if SplitEntry is set. It is unlimited if zero.
+	MaxEntryBytes int
+
+	// SplitEntry determines if splits the entries larger than MaxEntryBytes into several entries
+	// with "part" and "parts" fields, instead of truncating them.
+	SplitEntry bool
+
+	// CoarseClock determines if uses a cached wall time which is updated every millisecond by a
+	// background goroutine, instead of reading the clock on every entry.
+	CoarseClock bool
+
+	ctx context.Context
+
+	// groupContext is the pre-encoded namespaces of Group and the fields of With in them,
+	// groups holds the offsets next to the '{' of namespaces in groupContext.
+	groupContext []byte
+	groups       []int
 }
 
+// FieldProvider adds the dynamic fields to an entry, see Logger.FieldProviders.
+type FieldProvider func(e *Entry)
+
 // TimeFormatUnix defines a time format that makes time fields to be
 // serialized as Unix timestamp integers.
 const TimeFormatUnix = "\x01"
@@ -83,72 +201,80 @@ const TimeFormatUnixMs = "\x02"
 
 // Trace starts a new message with trace level.
 func Trace() (e *Entry) {
-	e = DefaultLogger.header(TraceLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(TraceLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Debug starts a new message with debug level.
 func Debug() (e *Entry) {
-	e = DefaultLogger.header(DebugLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(DebugLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Info starts a new message with info level.
 func Info() (e *Entry) {
-	e = DefaultLogger.header(InfoLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(InfoLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Warn starts a new message with warning level.
 func Warn() (e *Entry) {
-	e = DefaultLogger.header(WarnLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(WarnLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Error starts a new message with error level.
 func Error() (e *Entry) {
-	e = DefaultLogger.header(ErrorLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(ErrorLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Fatal starts a new message with fatal level.
 func Fatal() (e *Entry) {
-	e = DefaultLogger.header(FatalLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(FatalLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Panic starts a new message with panic level.
 func Panic() (e *Entry) {
-	e = DefaultLogger.header(PanicLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e = l.header(PanicLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	return
 }
 
 // Printf sends a log entry without extra field. Arguments are handled in the manner of fmt.Printf.
 func Printf(format string, v ...interface{}) {
-	e := DefaultLogger.header(noLevel)
-	if e != nil && DefaultLogger.Caller > 0 {
-		e.caller(runtime.Caller(DefaultLogger.Caller))
+	l := Default()
+	e := l.header(noLevel)
+	if e != nil && l.Caller > 0 {
+		e.caller(runtime.Caller(l.Caller))
 	}
 	e.Msgf(format, v...)
 }
@@ -240,6 +366,58 @@ func (l *Logger) SetLevel(level Level) {
 	return
 }
 
+// GetLevel returns logger current level.
+func (l *Logger) GetLevel() Level {
+	return Level(atomic.LoadUint32((*uint32)(&l.Level)))
+}
+
+// Enabled reports whether the entries of level are going to be logged, e.g.
+//
+//	if logger.Enabled(log.DebugLevel) {
+//		logger.Debug().Str("dump", expensiveDump()).Msg("state")
+//	}
+func (l *Logger) Enabled(level Level) bool {
+	return uint32(level) >= atomic.LoadUint32((*uint32)(&l.Level))
+}
+
+// With returns a child logger with keysAndValues pinned to every entry, the fields are
+// encoded once here and copied into the entries. If the logger has groups, the fields
+// are nested in the innermost group.
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	logger := *l
+	fields := NewContext(nil).KeysAndValues(keysAndValues...).Value()
+	if len(l.groups) != 0 {
+		logger.groupContext = append(append([]byte(nil), l.groupContext...), fields...)
+	} else {
+		logger.Context = append(append(Context(nil), l.Context...), fields...)
+	}
+	return &logger
+}
+
+// Named returns a child logger with name appended to the logger name by a period,
+// the fields of logger are inherited.
+func (l *Logger) Named(name string) *Logger {
+	logger := *l
+	if logger.Name != "" {
+		logger.Name += "." + name
+	} else {
+		logger.Name = name
+	}
+	return &logger
+}
+
+// Group returns a child logger whose fields of entries are nested under the name object,
+// e.g. {"level":"info","http":{"status":200},"message":"hello"}. It prevents key collisions
+// when components add fields with the same names. See Entry.Namespace.
+func (l *Logger) Group(name string) *Logger {
+	logger := *l
+	logger.groupContext = append(append([]byte(nil), l.groupContext...), ',', '"')
+	logger.groupContext = append(logger.groupContext, name...)
+	logger.groupContext = append(logger.groupContext, '"', ':', '{')
+	logger.groups = append(l.groups[:len(l.groups):len(l.groups)], len(logger.groupContext))
+	return &logger
+}
+
 // Printf sends a log entry without extra field. Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Printf(format string, v ...interface{}) {
 	e := l.header(noLevel)
@@ -257,6 +435,24 @@ var epool = sync.Pool{
 	},
 }
 
+// putEntry returns the entry to pool unless its buffer grew too large to be kept, and drops
+// the references of entry so that the pooled entries do not pin writers and hooks.
+func putEntry(e *Entry) {
+	if cap(e.buf) > bbcap {
+		return
+	}
+	e.w = nil
+	e.hooks = nil
+	e.exit = nil
+	// resets the settings of logger, the writers reuse the pooled entries by epool.Get directly.
+	e.escapeHTML = false
+	e.maxValue = 0
+	e.maxEntry = 0
+	e.splitEntry = false
+	e.humans = e.humans[:0]
+	epool.Put(e)
+}
+
 const smallsString = "00010203040506070809" +
 	"10111213141516171819" +
 	"20212223242526272829" +
@@ -269,6 +465,16 @@ const smallsString = "00010203040506070809" +
 	"90919293949596979899"
 
 var timeNow = time.Now
+
+// locationOffset returns the offset in seconds east of UTC of loc at the unix time sec.
+func locationOffset(loc *time.Location, sec int64) int64 {
+	if loc == time.UTC {
+		return 0
+	}
+	_, offset := time.Unix(sec, 0).In(loc).Zone()
+	return int64(offset)
+}
+
 var timeOffset, timeZone = func() (int64, string) {
 	now := timeNow()
 	_, n := now.Zone()
@@ -276,14 +482,30 @@ var timeOffset, timeZone = func() (int64, string) {
 	return int64(n), s
 }()
 
+// header returns nil if level is disabled, it is small enough to be inlined for the fast path.
 func (l *Logger) header(level Level) *Entry {
 	if uint32(level) < atomic.LoadUint32((*uint32)(&l.Level)) {
 		return nil
 	}
+	return l.entry(level)
+}
+
+// entry starts a new entry of level with the time, level and context fields of logger.
+func (l *Logger) entry(level Level) *Entry {
 	e := epool.Get().(*Entry)
-	e.loggerFiles = make([]string, 0)
+	e.loggerFiles = e.loggerFiles[:0]
 	e.buf = e.buf[:0]
 	e.Level = level
+	e.hooks = l.Hooks
+	e.hooking = false
+	e.discarded = false
+	e.exit = l.ExitFunc
+	e.groups = e.groups[:0]
+	e.humans = e.humans[:0]
+	e.escapeHTML = l.EscapeHTML
+	e.maxValue = l.MaxValueSize
+	e.maxEntry = l.MaxEntryBytes
+	e.splitEntry = l.SplitEntry
 	if l.Writer != nil {
 		e.w = l.Writer
 	} else {
@@ -301,12 +523,17 @@ func (l *Logger) header(level Level) *Entry {
 	case "":
 		var tmp [32]byte
 		var buf []byte
-		if timeOffset == 0 {
+		sec, nsec := l.walltime()
+		offset := timeOffset
+		if l.TimeLocation != nil {
+			offset = locationOffset(l.TimeLocation, sec)
+		}
+		if offset == 0 {
 			// "2006-01-02T15:04:05.999Z"
 			tmp[25] = '"'
 			tmp[24] = 'Z'
 			buf = tmp[:26]
-		} else {
+		} else if l.TimeLocation == nil {
 			// "2006-01-02T15:04:05.999Z07:00"
 			tmp[30] = '"'
 			tmp[29] = timeZone[5]
@@ -316,10 +543,23 @@ func (l *Logger) header(level Level) *Entry {
 			tmp[25] = timeZone[1]
 			tmp[24] = timeZone[0]
 			buf = tmp[:31]
+		} else {
+			// "2006-01-02T15:04:05.999Z07:00" of TimeLocation
+			zone, minutes := byte('+'), offset/60
+			if minutes < 0 {
+				zone, minutes = '-', -minutes
+			}
+			tmp[30] = '"'
+			tmp[29] = byte('0' + minutes%60%10)
+			tmp[28] = byte('0' + minutes%60/10)
+			tmp[27] = ':'
+			tmp[26] = byte('0' + minutes/60%10)
+			tmp[25] = byte('0' + minutes/60/10)
+			tmp[24] = zone
+			buf = tmp[:31]
 		}
-		sec, nsec := walltime()
 		// date time
-		sec += 9223372028715321600 + timeOffset // unixToInternal + internalToAbsolute + timeOffset
+		sec += 9223372028715321600 + offset // unixToInternal + internalToAbsolute + offset
 		year, month, day, _ := absDate(uint64(sec), true)
 		hour, minute, second := absClock(uint64(sec))
 		// year
@@ -367,7 +607,7 @@ func (l *Logger) header(level Level) *Entry {
 	case TimeFormatUnix:
 		// 1595759807
 		var tmp [10]byte
-		sec, _ := walltime()
+		sec, _ := l.walltime()
 		// seconds
 		b := sec % 100 * 2
 		sec /= 100
@@ -393,7 +633,7 @@ func (l *Logger) header(level Level) *Entry {
 	case TimeFormatUnixMs:
 		// 1595759807105
 		var tmp [13]byte
-		sec, nsec := walltime()
+		sec, nsec := l.walltime()
 		// milli seconds
 		a := int64(nsec) / 1000000
 		b := a % 100 * 2
@@ -424,7 +664,11 @@ func (l *Logger) header(level Level) *Entry {
 		e.buf = append(e.buf, tmp[:]...)
 	default:
 		e.buf = append(e.buf, '"')
-		e.buf = timeNow().AppendFormat(e.buf, l.TimeFormat)
+		if l.TimeLocation != nil {
+			e.buf = l.now().In(l.TimeLocation).AppendFormat(e.buf, l.TimeFormat)
+		} else {
+			e.buf = l.now().AppendFormat(e.buf, l.TimeFormat)
+		}
 		e.buf = append(e.buf, '"')
 	}
 	// level
@@ -443,6 +687,31 @@ func (l *Logger) header(level Level) *Entry {
 		e.buf = append(e.buf, ",\"level\":\"fatal\""...)
 	case PanicLevel:
 		e.buf = append(e.buf, ",\"level\":\"panic\""...)
+	default:
+		if info, ok := customLevel(level); ok {
+			e.buf = append(e.buf, info.field...)
+		}
+	}
+	// context
+	if len(l.Context) != 0 {
+		e.buf = append(e.buf, l.Context...)
+	}
+	for _, provider := range l.FieldProviders {
+		provider(e)
+	}
+	if len(l.groups) != 0 {
+		base := len(e.buf)
+		e.groupStart = base
+		e.buf = append(e.buf, l.groupContext...)
+		for _, pos := range l.groups {
+			e.groups = append(e.groups, base+pos)
+		}
+	}
+	if l.Name != "" {
+		e.loggerFiles = append(e.loggerFiles, l.Name)
+	}
+	if l.ctx != nil && l.ContextExtractor != nil {
+		l.ContextExtractor(l.ctx, e)
 	}
 	return e
 }
@@ -510,6 +779,14 @@ func (e *Entry) Dur(key string, d time.Duration) *Entry {
 	return e
 }
 
+// DurNonZero adds the field key with duration d to the entry if d is not zero.
+func (e *Entry) DurNonZero(key string, d time.Duration) *Entry {
+	if d == 0 {
+		return e
+	}
+	return e.Dur(key, d)
+}
+
 // Durs adds the field key with val as a []time.Duration to the entry.
 func (e *Entry) Durs(key string, d []time.Duration) *Entry {
 	if e == nil {
@@ -529,12 +806,109 @@ func (e *Entry) Durs(key string, d []time.Duration) *Entry {
 	return e
 }
 
+// DurHuman adds the field key_ms with d in milliseconds and the field key with d rendered
+// human-readable to the entry, e.g. {"elapsed_ms":1530,"elapsed":"1.53s"}.
+// See EncoderConfig.HumanFormat to keep one of them.
+func (e *Entry) DurHuman(key string, d time.Duration) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, ',', '"')
+	e.humans = append(e.humans, len(e.buf))
+	e.buf = append(e.buf, key...)
+	e.buf = append(e.buf, "_ms\":"...)
+	e.buf = strconv.AppendInt(e.buf, int64(d/time.Millisecond), 10)
+	e.key(key)
+	e.buf = append(e.buf, '"')
+	e.buf = appendHumanDuration(e.buf, d)
+	e.buf = append(e.buf, '"')
+	return e
+}
+
+// ByteSize adds the field key_bytes with n and the field key with n rendered in IEC units
+// to the entry, e.g. {"size_bytes":1572864,"size":"1.5 MiB"}.
+// See EncoderConfig.HumanFormat to keep one of them.
+func (e *Entry) ByteSize(key string, n int64) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, ',', '"')
+	e.humans = append(e.humans, len(e.buf))
+	e.buf = append(e.buf, key...)
+	e.buf = append(e.buf, "_bytes\":"...)
+	e.buf = strconv.AppendInt(e.buf, n, 10)
+	e.key(key)
+	e.buf = append(e.buf, '"')
+	e.buf = appendByteSize(e.buf, n)
+	e.buf = append(e.buf, '"')
+	return e
+}
+
+// appendHumanFloat appends v with at most 2 decimals and the trailing zeros removed.
+func appendHumanFloat(dst []byte, v float64) []byte {
+	dst = strconv.AppendFloat(dst, v, 'f', 2, 64)
+	for dst[len(dst)-1] == '0' {
+		dst = dst[:len(dst)-1]
+	}
+	if dst[len(dst)-1] == '.' {
+		dst = dst[:len(dst)-1]
+	}
+	return dst
+}
+
+// appendHumanDuration appends d with 3 significant digits, e.g. 1.53s, 250ms, 1m30s.
+func appendHumanDuration(dst []byte, d time.Duration) []byte {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Minute:
+		return append(dst, d.Round(time.Second).String()...)
+	case abs >= time.Second:
+		return append(appendHumanFloat(dst, float64(d)/float64(time.Second)), 's')
+	case abs >= time.Millisecond:
+		return append(appendHumanFloat(dst, float64(d)/float64(time.Millisecond)), "ms"...)
+	case abs >= time.Microsecond:
+		return append(appendHumanFloat(dst, float64(d)/float64(time.Microsecond)), "µs"...)
+	default:
+		return append(strconv.AppendInt(dst, int64(d), 10), "ns"...)
+	}
+}
+
+// appendByteSize appends n in IEC units, e.g. 512 B, 1.5 KiB, 2 GiB.
+func appendByteSize(dst []byte, n int64) []byte {
+	const units = "KMGTPE"
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < 1024 {
+		return append(strconv.AppendInt(dst, n, 10), " B"...)
+	}
+	v, i := float64(n)/1024, 0
+	for (v >= 1024 || v <= -1024) && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	return append(appendHumanFloat(dst, v), ' ', units[i], 'i', 'B')
+}
+
 // Err adds the field "error" with serialized err to the entry.
+//
+// If err is an error group, i.e. it implements Unwrap() []error as errors.Join of Go 1.20 or
+// WrappedErrors() []error as hashicorp/go-multierror, the errors of group are added as an array
+// of objects with their messages and types to the field "errors" additionally, e.g.
+//
+//	{"error":"a\nb","errors":[{"message":"a","type":"*errors.errorString"},...]}
+//
+// The nested groups are flattened.
 func (e *Entry) Err(err error) *Entry {
 	return e.AnErr("error", err)
 }
 
-// AnErr adds the field key with serialized err to the logger context.
+// AnErr adds the field key with serialized err to the logger context. The errors of an error
+// group are added to the field key with suffix "s" additionally, see Err.
 func (e *Entry) AnErr(key string, err error) *Entry {
 	if e == nil {
 		return nil
@@ -554,9 +928,51 @@ func (e *Entry) AnErr(key string, err error) *Entry {
 		e.string(err.Error())
 		e.buf = append(e.buf, '"')
 	}
+
+	if errs := unwrapErrors(err); errs != nil {
+		e.buf = append(e.buf, ',', '"')
+		e.buf = append(e.buf, key...)
+		e.buf = append(e.buf, "s\":["...)
+		e.errorGroup(errs, true)
+		e.buf = append(e.buf, ']')
+	}
 	return e
 }
 
+// unwrapErrors returns the errors of an error group, or nil if err is not a group.
+func unwrapErrors(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		return x.Unwrap()
+	case interface{ WrappedErrors() []error }:
+		return x.WrappedErrors()
+	}
+	return nil
+}
+
+// errorGroup appends the errors of group as json objects, and returns whether the next one is first.
+func (e *Entry) errorGroup(errs []error, first bool) bool {
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if group := unwrapErrors(err); group != nil {
+			first = e.errorGroup(group, first)
+			continue
+		}
+		if !first {
+			e.buf = append(e.buf, ',')
+		}
+		first = false
+		e.buf = append(e.buf, "{\"message\":\""...)
+		e.string(err.Error())
+		e.buf = append(e.buf, "\",\"type\":\""...)
+		e.buf = append(e.buf, reflect.TypeOf(err).String()...)
+		e.buf = append(e.buf, "\"}"...)
+	}
+	return first
+}
+
 // Errs adds the field key with errs as an array of serialized errors to the entry.
 func (e *Entry) Errs(key string, errs []error) *Entry {
 	if e == nil {
@@ -591,6 +1007,14 @@ func (e *Entry) Float64(key string, f float64) *Entry {
 	return e
 }
 
+// Float64NonZero adds the field key with f as a float64 to the entry if f is not zero.
+func (e *Entry) Float64NonZero(key string, f float64) *Entry {
+	if f == 0 {
+		return e
+	}
+	return e.Float64(key, f)
+}
+
 // Floats64 adds the field key with f as a []float64 to the entry.
 func (e *Entry) Floats64(key string, f []float64) *Entry {
 	if e == nil {
@@ -635,6 +1059,14 @@ func (e *Entry) Int64(key string, i int64) *Entry {
 	return e
 }
 
+// Int64NonZero adds the field key with i as a int64 to the entry if i is not zero.
+func (e *Entry) Int64NonZero(key string, i int64) *Entry {
+	if i == 0 {
+		return e
+	}
+	return e.Int64(key, i)
+}
+
 // Uint adds the field key with i as a uint to the entry.
 func (e *Entry) Uint(key string, i uint) *Entry {
 	if e == nil {
@@ -665,6 +1097,14 @@ func (e *Entry) Int(key string, i int) *Entry {
 	return e.Int64(key, int64(i))
 }
 
+// IntNonZero adds the field key with i as a int to the entry if i is not zero.
+func (e *Entry) IntNonZero(key string, i int) *Entry {
+	if i == 0 {
+		return e
+	}
+	return e.Int64(key, int64(i))
+}
+
 // Int32 adds the field key with i as a int32 to the entry.
 func (e *Entry) Int32(key string, i int32) *Entry {
 	return e.Int64(key, int64(i))
@@ -727,6 +1167,14 @@ func (e *Entry) Str(key string, val string) *Entry {
 	return e
 }
 
+// StrNonEmpty adds the field key with val as a string to the entry if val is not empty.
+func (e *Entry) StrNonEmpty(key string, val string) *Entry {
+	if val == "" {
+		return e
+	}
+	return e.Str(key, val)
+}
+
 // StrInt adds the field key with integer val as a string to the entry.
 func (e *Entry) StrInt(key string, val int64) *Entry {
 	if e == nil {
@@ -771,6 +1219,31 @@ func (e *Entry) GoStringer(key string, val fmt.GoStringer) *Entry {
 	return e
 }
 
+// Func adds the field key with the value returned by fn, which is called only if the entry is
+// enabled, i.e. it passes the level check of the logger, so the expensive values are not computed
+// for the disabled entries, e.g.
+//
+//	log.Debug().Func("dump", func() interface{} { return state.Dump() }).Msg("state")
+//
+// The value is added as in KeysAndValues. fn is called immediately for the enabled entries, so the
+// writers which drop the built entries, e.g. SamplerWriter, do not save the computation; guard fn
+// with Sampled for the values of the sampled-out keys.
+func (e *Entry) Func(key string, fn func() interface{}) *Entry {
+	if e == nil {
+		return nil
+	}
+	return e.KeysAndValues(key, fn())
+}
+
+// LazyStr adds the field key with the string returned by fn, which is called only if the entry
+// passes the level check of the logger, as in Func.
+func (e *Entry) LazyStr(key string, fn func() string) *Entry {
+	if e == nil {
+		return nil
+	}
+	return e.Str(key, fn())
+}
+
 // Strs adds the field key with vals as a []string to the entry.
 func (e *Entry) Strs(key string, vals []string) *Entry {
 	if e == nil {
@@ -796,30 +1269,10 @@ func (e *Entry) Byte(key string, val byte) *Entry {
 		return nil
 	}
 	e.key(key)
-	switch val {
-	case '"':
-		e.buf = append(e.buf, "\"\\\"\""...)
-	case '\\':
-		e.buf = append(e.buf, "\"\\\\\""...)
-	case '\n':
-		e.buf = append(e.buf, "\"\\n\""...)
-	case '\r':
-		e.buf = append(e.buf, "\"\\r\""...)
-	case '\t':
-		e.buf = append(e.buf, "\"\\t\""...)
-	case '\f':
-		e.buf = append(e.buf, "\"\\u000c\""...)
-	case '\b':
-		e.buf = append(e.buf, "\"\\u0008\""...)
-	case '<':
-		e.buf = append(e.buf, "\"\\u003c\""...)
-	case '\'':
-		e.buf = append(e.buf, "\"\\u0027\""...)
-	case 0:
-		e.buf = append(e.buf, "\"\\u0000\""...)
-	default:
-		e.buf = append(e.buf, '"', val, '"')
-	}
+	b := [1]byte{val}
+	e.buf = append(e.buf, '"')
+	e.bytes(b[:])
+	e.buf = append(e.buf, '"')
 	return e
 }
 
@@ -874,9 +1327,10 @@ func (e *Entry) Xid(key string, xid [12]byte) *Entry {
 	}
 	e.key(key)
 
-	e.buf = append(e.buf, '"')
-	e.buf = append(e.buf, (XID(xid)).String()...)
-	e.buf = append(e.buf, '"')
+	var tmp [22]byte
+	tmp[0], tmp[21] = '"', '"'
+	XID(xid).encode(tmp[1:21])
+	e.buf = append(e.buf, tmp[:]...)
 
 	return e
 }
@@ -969,19 +1423,57 @@ func (e *Entry) Stack() *Entry {
 	return e
 }
 
-// Enabled return false if the entry is going to be filtered out by log level.
+// Stacktrace enables the stack trace as an array of lines in the "stacktrace" field, the leading
+// tabs of lines are removed. It keeps the stack in one physical line for any output format.
+func (e *Entry) Stacktrace() *Entry {
+	if e == nil {
+		return nil
+	}
+	e.buf = append(e.buf, ",\"stacktrace\":["...)
+	first := true
+	for _, line := range bytes.Split(stacks(false), []byte{'\n'}) {
+		line = bytes.TrimLeft(line, "\t")
+		if len(line) == 0 {
+			continue
+		}
+		if !first {
+			e.buf = append(e.buf, ',')
+		}
+		first = false
+		e.buf = append(e.buf, '"')
+		e.bytes(line)
+		e.buf = append(e.buf, '"')
+	}
+	e.buf = append(e.buf, ']')
+	return e
+}
+
+// Enabled return false if the entry is going to be filtered out by log level, i.e. it is nil.
 func (e *Entry) Enabled() bool {
 	return e != nil
 }
 
+// If adds the fields by fn if cond is true, e.g.
+//
+//	log.Info().If(err != nil, func(e *log.Entry) { e.Err(err).Int("retries", n) }).Msg("done")
+func (e *Entry) If(cond bool, fn func(e *Entry)) *Entry {
+	if e == nil || !cond {
+		return e
+	}
+	fn(e)
+	return e
+}
+
 // Discard disables the entry so Msg(f) won't print it.
 func (e *Entry) Discard() *Entry {
 	if e == nil {
 		return e
 	}
-	if cap(e.buf) <= bbcap {
-		epool.Put(e)
+	if e.hooking {
+		e.discarded = true
+		return nil
 	}
+	putEntry(e)
 	return nil
 }
 
@@ -992,6 +1484,16 @@ func (e *Entry) Msg(msg string) {
 	if e == nil {
 		return
 	}
+	if e.hooks != nil && !e.hook(msg) {
+		return
+	}
+	e.msg(msg)
+}
+
+func (e *Entry) msg(msg string) {
+	if len(e.groups) != 0 {
+		e.closeGroups()
+	}
 	if msg != "" {
 		e.buf = append(e.buf, ",\"message\":\""...)
 		e.string(msg)
@@ -999,16 +1501,26 @@ func (e *Entry) Msg(msg string) {
 	} else {
 		e.buf = append(e.buf, '}', '\n')
 	}
-	e.w.WriteEntry(e)
-	if (e.Level == FatalLevel) && notTest {
-		os.Exit(255)
-	}
-	if (e.Level == PanicLevel) && notTest {
-		panic(msg)
+	if e.maxEntry > 0 && len(e.buf) > e.maxEntry {
+		e.writeLimited()
+	} else {
+		e.w.WriteEntry(e)
+	}
+	if e.Level == FatalLevel {
+		runExitHandlers(e.w)
+		if e.exit != nil {
+			e.exit(255)
+		} else if notTest {
+			os.Exit(255)
+		}
 	}
-	if cap(e.buf) <= bbcap {
-		epool.Put(e)
+	if e.Level == PanicLevel {
+		runExitHandlers(e.w)
+		if notTest {
+			panic(msg)
+		}
 	}
+	putEntry(e)
 }
 
 type bb struct {
@@ -1044,12 +1556,16 @@ func (e *Entry) Msgf(format string, v ...interface{}) {
 		return
 	}
 	b := bbget()
+	b.B = appendPrintf(b.B, format, v)
+	if e.hooks != nil && !e.hook(b2s(b.B)) {
+		bbput(b)
+		return
+	}
 	e.buf = append(e.buf, ",\"message\":\""...)
-	fmt.Fprintf(b, format, v...)
 	e.bytes(b.B)
 	e.buf = append(e.buf, '"')
 	bbput(b)
-	e.Msg("")
+	e.msg("")
 }
 
 // Msgv sends the entry with msgs added as the message field if not empty.
@@ -1058,12 +1574,16 @@ func (e *Entry) Msgs(args ...interface{}) {
 		return
 	}
 	b := bbget()
-	e.buf = append(e.buf, ",\"message\":\""...)
 	fmt.Fprint(b, args...)
+	if e.hooks != nil && !e.hook(b2s(b.B)) {
+		bbput(b)
+		return
+	}
+	e.buf = append(e.buf, ",\"message\":\""...)
 	e.bytes(b.B)
 	e.buf = append(e.buf, '"')
 	bbput(b)
-	e.Msg("")
+	e.msg("")
 }
 
 func (e *Entry) key(key string) {
@@ -1076,88 +1596,116 @@ func (e *Entry) caller(_ uintptr, file string, line int, _ bool) {
 	if i := strings.LastIndex(file, "/"); i >= 0 {
 		file = file[i+1:]
 	}
+	start := len(e.buf)
 	e.buf = append(e.buf, ",\"caller\":\""...)
 	e.buf = append(e.buf, file...)
 	e.buf = append(e.buf, ':')
 	e.buf = strconv.AppendInt(e.buf, int64(line), 10)
 	e.buf = append(e.buf, "\",\"goid\":"...)
 	e.buf = strconv.AppendInt(e.buf, int64(goid()), 10)
+	if len(e.groups) != 0 {
+		e.hoist(start)
+	}
 }
 
-var escapes = [256]bool{
-	'"':  true,
-	'<':  true,
-	'\'': true,
-	'\\': true,
-	'\b': true,
-	'\f': true,
-	'\n': true,
-	'\r': true,
-	'\t': true,
+// hoist moves the fields from pos to the end of buf before the groups of logger, so that the
+// caller and goid are not nested in the groups. It rotates the bytes in place.
+func (e *Entry) hoist(pos int) {
+	n := len(e.buf) - pos
+	for _, b := range [][]byte{e.buf[e.groupStart:pos], e.buf[pos:], e.buf[e.groupStart:]} {
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+	}
+	for i := range e.groups {
+		e.groups[i] += n
+	}
+	for i, pos := range e.humans {
+		if pos >= e.groupStart {
+			e.humans[i] += n
+		}
+	}
 }
 
+// escapes is the bytes need escaping in json strings, i.e. control characters, '"', '\\', and
+// '<' and single quote for compatibility. The bytes of utf8.RuneSelf and above are validated as UTF-8.
+var escapes = func() (t [256]bool) {
+	for i := range t {
+		t[i] = i < 0x20 || i >= utf8.RuneSelf
+	}
+	t['"'], t['\\'], t['<'], t['\''] = true, true, true, true
+	return
+}()
+
+// htmlEscapes is escapes with the HTML-sensitive bytes for Logger.EscapeHTML.
+var htmlEscapes = func() (t [256]bool) {
+	t = escapes
+	t['>'], t['&'] = true, true
+	return
+}()
+
+// truncatedSuffix is appended to the values truncated by Logger.MaxValueSize.
+const truncatedSuffix = "...(truncated)"
+
 func (e *Entry) escape(b []byte) {
+	table := &escapes
+	if e.escapeHTML {
+		table = &htmlEscapes
+	}
 	n := len(b)
 	j := 0
-	if n > 0 {
-		// Hint the compiler to remove bounds checks in the loop below.
-		_ = b[n-1]
-	}
-	for i := 0; i < n; i++ {
-		switch b[i] {
-		case '"':
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', '"')
-			j = i + 1
-		case '\\':
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', '\\')
-			j = i + 1
+	for i := 0; i < n; {
+		c := b[i]
+		if !table[c] {
+			i++
+			continue
+		}
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(b[i:])
+			switch {
+			case r == utf8.RuneError && size == 1:
+				e.buf = append(e.buf, b[j:i]...)
+				e.buf = append(e.buf, '\\', 'u', 'f', 'f', 'f', 'd')
+				j = i + size
+			case e.escapeHTML && (r == '\u2028' || r == '\u2029'):
+				e.buf = append(e.buf, b[j:i]...)
+				e.buf = append(e.buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+				j = i + size
+			}
+			i += size
+			continue
+		}
+		e.buf = append(e.buf, b[j:i]...)
+		switch c {
+		case '"', '\\':
+			e.buf = append(e.buf, '\\', c)
 		case '\n':
-			e.buf = append(e.buf, b[j:i]...)
 			e.buf = append(e.buf, '\\', 'n')
-			j = i + 1
 		case '\r':
-			e.buf = append(e.buf, b[j:i]...)
 			e.buf = append(e.buf, '\\', 'r')
-			j = i + 1
 		case '\t':
-			e.buf = append(e.buf, b[j:i]...)
 			e.buf = append(e.buf, '\\', 't')
-			j = i + 1
-		case '\f':
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', 'u', '0', '0', '0', 'c')
-			j = i + 1
-		case '\b':
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', 'u', '0', '0', '0', '8')
-			j = i + 1
-		case '<':
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', 'u', '0', '0', '3', 'c')
-			j = i + 1
-		case '\'':
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', 'u', '0', '0', '2', '7')
-			j = i + 1
-		case 0:
-			e.buf = append(e.buf, b[j:i]...)
-			e.buf = append(e.buf, '\\', 'u', '0', '0', '0', '0')
-			j = i + 1
+		default:
+			e.buf = append(e.buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
 		}
+		i++
+		j = i
 	}
 	e.buf = append(e.buf, b[j:]...)
 }
 
 func (e *Entry) string(s string) {
+	if e.maxValue > 0 && len(s) > e.maxValue {
+		e.truncated(s2b(s))
+		return
+	}
+	table := &escapes
+	if e.escapeHTML {
+		table = &htmlEscapes
+	}
 	for _, c := range []byte(s) {
-		if escapes[c] {
-			sh := (*reflect.StringHeader)(unsafe.Pointer(&s))
-			b := *(*[]byte)(unsafe.Pointer(&reflect.SliceHeader{
-				Data: sh.Data, Len: sh.Len, Cap: sh.Len,
-			}))
-			e.escape(b)
+		if table[c] {
+			e.escape(s2b(s))
 			return
 		}
 	}
@@ -1166,8 +1714,16 @@ func (e *Entry) string(s string) {
 }
 
 func (e *Entry) bytes(b []byte) {
+	if e.maxValue > 0 && len(b) > e.maxValue {
+		e.truncated(b)
+		return
+	}
+	table := &escapes
+	if e.escapeHTML {
+		table = &htmlEscapes
+	}
 	for _, c := range b {
-		if escapes[c] {
+		if table[c] {
 			e.escape(b)
 			return
 		}
@@ -1176,6 +1732,16 @@ func (e *Entry) bytes(b []byte) {
 	return
 }
 
+// truncated appends b truncated to maxValue bytes at a rune boundary with truncatedSuffix.
+func (e *Entry) truncated(b []byte) {
+	n := e.maxValue
+	for n > 0 && n > e.maxValue-utf8.UTFMax && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	e.escape(b[:n])
+	e.buf = append(e.buf, truncatedSuffix...)
+}
+
 // Interface adds the field key with i marshaled using reflection.
 func (e *Entry) Interface(key string, i interface{}) *Entry {
 	if e == nil {
@@ -1190,7 +1756,7 @@ func (e *Entry) Interface(key string, i interface{}) *Entry {
 	e.buf = append(e.buf, '"')
 	b := bbget()
 	enc := json.NewEncoder(b)
-	enc.SetEscapeHTML(false)
+	enc.SetEscapeHTML(e.escapeHTML)
 	err := enc.Encode(i)
 	if err != nil {
 		e.string("marshaling error: " + err.Error())
@@ -1405,6 +1971,29 @@ func (e *Entry) Fields(fields map[string]interface{}) *Entry {
 	return e
 }
 
+// Namespace opens the name object in entry, the subsequent fields are nested under it
+// until the entry is sent, e.g. {"level":"info","db":{"rows":3},"message":"hello"}.
+func (e *Entry) Namespace(name string) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.key(name)
+	e.buf = append(e.buf, '{')
+	e.groups = append(e.groups, len(e.buf))
+	return e
+}
+
+// closeGroups removes the leading comma of fields in namespaces, and closes them.
+func (e *Entry) closeGroups() {
+	for i := len(e.groups) - 1; i >= 0; i-- {
+		if pos := e.groups[i]; pos < len(e.buf) && e.buf[pos] == ',' {
+			e.buf = append(e.buf[:pos], e.buf[pos+1:]...)
+		}
+		e.buf = append(e.buf, '}')
+	}
+	e.groups = e.groups[:0]
+}
+
 // Context represents contextual fields.
 type Context []byte
 
@@ -1417,6 +2006,9 @@ func NewContext(dst []byte) (e *Entry) {
 
 // Value builds the contextual fields.
 func (e *Entry) Value() Context {
+	if len(e.groups) != 0 {
+		e.closeGroups()
+	}
 	return e.buf
 }
 
@@ -1446,7 +2038,22 @@ func (e *Entry) Dict(key string, ctx Context) *Entry {
 	return e
 }
 
-// Str adds the field key with val as a string to the entry.
+// objectStart starts the object field of key, and returns the offset of its first field.
+func (e *Entry) objectStart(key string) int {
+	e.key(key)
+	e.buf = append(e.buf, '{')
+	return len(e.buf)
+}
+
+// objectEnd ends the object field started at start, removing the leading comma of its first field.
+func (e *Entry) objectEnd(start int) {
+	if len(e.buf) > start && e.buf[start] == ',' {
+		e.buf = append(e.buf[:start], e.buf[start+1:]...)
+	}
+	e.buf = append(e.buf, '}')
+}
+
+// LoggerFile adds the name to the destinations of entry, see To.
 func (e *Entry) LoggerFile(name string) *Entry {
 	if e == nil {
 		return nil
@@ -1455,6 +2062,28 @@ func (e *Entry) LoggerFile(name string) *Entry {
 	return e
 }
 
+// To routes the entry to the named writers of MultiFileWriter, e.g.
+//
+//	logger.Info().To("heartbeat", "audit").Msg("hello")
+//
+// The entry is written once to each named writer found, or to the "default" writer if
+// none is found. The name of logger is always one of the destinations.
+func (e *Entry) To(names ...string) *Entry {
+	if e == nil {
+		return nil
+	}
+	e.loggerFiles = append(e.loggerFiles, names...)
+	return e
+}
+
+// LoggerFiles returns the destinations of entry, see To.
+func (e *Entry) LoggerFiles() []string {
+	if e == nil {
+		return nil
+	}
+	return e.loggerFiles
+}
+
 // stacks is a wrapper for runtime.Stack that attempts to recover the data for all goroutines.
 func stacks(all bool) (trace []byte) {
 	// We don't know how big the traces are, so grow a few times if they don't fit. Start large, though.
@@ -1484,6 +2113,13 @@ func wlprintf(w Writer, level Level, format string, args ...interface{}) (int, e
 
 func b2s(b []byte) string { return *(*string)(unsafe.Pointer(&b)) }
 
+func s2b(s string) []byte {
+	sh := (*reflect.StringHeader)(unsafe.Pointer(&s))
+	return *(*[]byte)(unsafe.Pointer(&reflect.SliceHeader{
+		Data: sh.Data, Len: sh.Len, Cap: sh.Len,
+	}))
+}
+
 //go:noescape
 //go:linkname absDate time.absDate
 func absDate(abs uint64, full bool) (year int, month time.Month, day int, yday int)
@@ -3,12 +3,16 @@ package log
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 func TestLoggerDefault(t *testing.T) {
@@ -141,6 +145,11 @@ func TestLoggerNil(t *testing.T) {
 		Stringer("stringer", ipv4Addr).
 		GoStringer("gostringer", nil).
 		GoStringer("gostringer", binary.BigEndian).
+		Func("func", func() interface{} { panic("lazy field evaluated") }).
+		StrNonEmpty("non_empty", "a").
+		IntNonZero("non_zero", 1).
+		If(true, func(e *Entry) { e.Str("if", "a") }).
+		LazyStr("lazy", func() string { panic("lazy field evaluated") }).
 		Time("now_1", timeNow()).
 		TimeFormat("now_2", time.RFC3339, timeNow()).
 		TimeDiff("time_diff_1", timeNow().Add(time.Second), timeNow()).
@@ -327,6 +336,51 @@ func TestLoggerTimeOffset(t *testing.T) {
 	logger.Info().Msg("this is -7:00 timezone time log entry")
 }
 
+func TestLoggerTimeLocation(t *testing.T) {
+	cases := []struct {
+		Location *time.Location
+		Suffix   string
+	}{
+		{time.UTC, `Z"`},
+		{time.FixedZone("IST", 5*3600+1800), `+05:30"`},
+		{time.FixedZone("NST", -(3*3600 + 1800)), `-03:30"`},
+		{time.FixedZone("UTC0", 0), `Z"`},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		logger := Logger{TimeLocation: c.Location, Writer: IOWriter{&buf}}
+		logger.Info().Msg("")
+
+		var entry struct {
+			Time string `json:"time"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("time location entry invalid: %+v %s", err, buf.String())
+		}
+		if !strings.HasSuffix(entry.Time+`"`, c.Suffix) {
+			t.Errorf("time location %s suffix mismatch: %s", c.Location, entry.Time)
+		}
+		ts, err := time.Parse(time.RFC3339Nano, entry.Time)
+		if err != nil {
+			t.Fatalf("time location parse error: %+v", err)
+		}
+		if d := time.Since(ts); d < 0 || d > time.Second {
+			t.Errorf("time location %s time mismatch: %s", c.Location, entry.Time)
+		}
+		if want := time.Now().In(c.Location).Format("2006-01-02T15"); !strings.HasPrefix(entry.Time, want) {
+			t.Errorf("time location %s wall clock mismatch: %s, want %s", c.Location, entry.Time, want)
+		}
+
+		buf.Reset()
+		logger.TimeFormat = time.RFC3339
+		logger.Info().Msg("")
+		if !strings.Contains(buf.String(), c.Suffix) {
+			t.Errorf("time location %s custom format mismatch: %s", c.Location, buf.String())
+		}
+	}
+}
+
 func TestLoggerContext(t *testing.T) {
 	ctx := NewContext(nil).Bool("ctx_bool", true).Str("ctx_str", "ctx str").Value()
 
@@ -440,6 +494,41 @@ func (e errno) Format(s fmt.State, verb rune) {
 	}
 }
 
+type testJoinError struct{ errs []error }
+
+func (e testJoinError) Error() string   { return fmt.Sprint(e.errs) }
+func (e testJoinError) Unwrap() []error { return e.errs }
+
+type testMultiError struct{ errs []error }
+
+func (e *testMultiError) Error() string          { return fmt.Sprintf("%d errors occurred", len(e.errs)) }
+func (e *testMultiError) WrappedErrors() []error { return e.errs }
+
+func TestLoggerErrorGroup(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+
+	err := testJoinError{[]error{
+		errors.New("a"),
+		nil,
+		&testMultiError{[]error{fmt.Errorf("b %d", 1), &net.AddrError{Err: "c"}}},
+	}}
+	logger.Info().Err(err).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"error":"[a \u003cnil> 2 errors occurred]","errors":[{"message":"a","type":"*errors.errorString"},{"message":"b 1","type":"*errors.errorString"},{"message":"c","type":"*net.AddrError"}]`) {
+		t.Errorf("error group mismatch: %s", s)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+		t.Errorf("error group should be valid json: %v", err)
+	}
+
+	buf.Reset()
+	logger.Info().AnErr("cause", &testMultiError{[]error{}}).Err(errors.New("x")).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"cause":"0 errors occurred","causes":[],"error":"x"}`) {
+		t.Errorf("error group mismatch: %s", s)
+	}
+}
+
 func TestLoggerErrorStack(t *testing.T) {
 	logger := Logger{Level: TraceLevel, Writer: &ConsoleWriter{ColorOutput: true}}
 	logger.Info().Err(errno(0)).Msg("log errno(0) here")
@@ -461,3 +550,432 @@ func BenchmarkLogger(b *testing.B) {
 		logger.Info().Str("foo", "bar").Msgf("hello %s", "world")
 	}
 }
+
+func TestLoggerWithNamed(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+	}
+
+	child := logger.With("foo", "bar").Named("db").With("number", 42).Named("sql")
+	child.Info().Str("a", "b").Msg("hello child logger")
+	if s := buf.String(); !strings.Contains(s, `"level":"info","foo":"bar","number":42,"a":"b","message":"hello child logger"`) {
+		t.Errorf("child logger output mismatch: %s", s)
+	}
+	if child.Name != "db.sql" {
+		t.Errorf("child logger name mismatch: %s", child.Name)
+	}
+
+	buf.Reset()
+	logger.Info().Msg("hello parent logger")
+	if s := buf.String(); strings.Contains(s, "foo") {
+		t.Errorf("parent logger should not contain child fields: %s", s)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	if Default() != &DefaultLogger {
+		t.Fatalf("Default() should return DefaultLogger")
+	}
+
+	var buf bytes.Buffer
+	SetDefault(Logger{Level: InfoLevel, Writer: IOWriter{&buf}})
+	defer atomic.StorePointer(&defaultLogger, unsafe.Pointer(&DefaultLogger))
+
+	Debug().Msg("hello debug")
+	Info().Str("foo", "bar").Msg("hello default")
+	Printf("hello %s", "printf")
+
+	if s := buf.String(); strings.Contains(s, "hello debug") || !strings.Contains(s, `"level":"info","foo":"bar","message":"hello default"`) || !strings.Contains(s, `"message":"hello printf"`) {
+		t.Errorf("SetDefault output mismatch: %s", s)
+	}
+
+	if Default() == &DefaultLogger {
+		t.Errorf("SetDefault should replace DefaultLogger")
+	}
+}
+
+func TestLoggerGroup(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		TimeField:  "ts",
+		TimeFormat: TimeFormatUnix,
+		Context:    NewContext(nil).Str("app", "demo").Value(),
+		Writer:     IOWriter{&buf},
+	}
+
+	cases := []struct {
+		Log    func()
+		Output string
+	}{
+		{func() { logger.Group("http").Info().Int("status", 200).Str("path", "/").Msg("hello") }, `"app":"demo","http":{"status":200,"path":"/"},"message":"hello"}`},
+		{func() { logger.Group("http").Group("req").Info().Str("id", "1").Msg("") }, `"app":"demo","http":{"req":{"id":"1"}}}`},
+		{func() { logger.Group("empty").Info().Msg("hello") }, `"app":"demo","empty":{},"message":"hello"}`},
+		{func() {
+			logger.With("env", "prod").Group("http").With("method", "GET").Info().Int("status", 200).Msg("")
+		}, `"app":"demo","env":"prod","http":{"method":"GET","status":200}}`},
+		{func() { logger.Group("http").With("method", "GET").Group("req").Info().Msg("") }, `"app":"demo","http":{"method":"GET","req":{}}}`},
+		{func() {
+			logger.Info().Str("a", "1").Namespace("db").Int("rows", 3).Namespace("conn").Str("a", "2").Msg("hello")
+		}, `"a":"1","db":{"rows":3,"conn":{"a":"2"}},"message":"hello"}`},
+		{func() { logger.Info().Dict("ctx", NewContext(nil).Namespace("x").Int("y", 1).Value()).Msg("hello") }, `"ctx":{"x":{"y":1}},"message":"hello"}`},
+	}
+
+	for _, c := range cases {
+		buf.Reset()
+		c.Log()
+		if !strings.HasSuffix(buf.String(), c.Output+"\n") {
+			t.Errorf("group output mismatch, got %s want %s", buf.String(), c.Output)
+		}
+	}
+
+	// the caller and goid are not nested in the groups
+	logger.Caller = 1
+	buf.Reset()
+	logger.Group("http").With("method", "GET").Info().DurHuman("took", time.Second).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"app":"demo","caller":"logger_test.go:`) ||
+		!strings.HasSuffix(s, `,"http":{"method":"GET","took_ms":1000,"took":"1s"}}`+"\n") {
+		t.Errorf("group caller output mismatch: %s", s)
+	}
+}
+
+func TestLoggerEnabledLevel(t *testing.T) {
+	logger := Logger{Level: InfoLevel, Writer: IOWriter{ioutil.Discard}}
+
+	for level := TraceLevel; level <= PanicLevel; level++ {
+		if got, want := logger.Enabled(level), level >= InfoLevel; got != want {
+			t.Errorf("logger.Enabled(%s) = %v, want %v", level, got, want)
+		}
+	}
+	if e := logger.Debug(); e != nil || e.Enabled() {
+		t.Errorf("disabled level should return a nil entry")
+	}
+	if e := logger.Info(); !e.Enabled() {
+		t.Errorf("enabled level should return a non-nil entry")
+	} else {
+		e.Discard()
+	}
+
+	logger.SetLevel(DebugLevel)
+	if !logger.Enabled(DebugLevel) {
+		t.Errorf("logger.Enabled should follow SetLevel")
+	}
+}
+
+func TestLoggerZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("skipping allocation assertions under the race detector")
+	}
+	logger := Logger{
+		Level:  DebugLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}
+	child := logger.With("app", "demo").Group("http").With("method", "GET")
+
+	cases := map[string]func(){
+		"str_int_msg": func() { logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello world") },
+		"msgf":        func() { logger.Info().Msgf("hello %s", "world") },
+		"disabled":    func() { logger.Trace().Str("foo", "bar").Msg("hello world") },
+		"to":          func() { logger.Info().To("audit").Msg("hello world") },
+		"namespace":   func() { logger.Info().Namespace("http").Int("status", 200).Msg("hello world") },
+		"with_group":  func() { child.Info().Int("status", 200).Msg("hello world") },
+	}
+
+	for name, fn := range cases {
+		if n := testing.AllocsPerRun(1000, fn); n != 0 {
+			t.Errorf("logger %s should be zero allocation, got %v allocs/op", name, n)
+		}
+	}
+}
+
+func BenchmarkLoggerStrIntMsg(b *testing.B) {
+	logger := Logger{
+		Level:  DebugLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello world")
+	}
+}
+
+func BenchmarkLoggerWith(b *testing.B) {
+	logger := (&Logger{
+		Level:  DebugLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}).With("app", "demo", "pid", 42).Group("http").With("method", "GET")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Int("status", 200).Msg("hello world")
+	}
+}
+
+func BenchmarkLoggerDisabled(b *testing.B) {
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug().Str("foo", "bar").Int("n", 42).Msg("hello world")
+	}
+}
+
+func BenchmarkLoggerDisabledEnabled(b *testing.B) {
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if logger.Enabled(DebugLevel) {
+			logger.Debug().Str("foo", "bar").Int("n", 42).Msg("hello world")
+		}
+	}
+}
+
+func BenchmarkLoggerDisabledMsgf(b *testing.B) {
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug().Msgf("hello %s", "world")
+	}
+}
+
+func BenchmarkLoggerParallel(b *testing.B) {
+	logger := Logger{
+		Level:  DebugLevel,
+		Writer: IOWriter{ioutil.Discard},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello world")
+		}
+	})
+}
+
+func TestLoggerEscape(t *testing.T) {
+	cases := []struct {
+		Value  string
+		Output string
+		HTML   bool
+	}{
+		{"plain", `"plain"`, false},
+		{"a\"b\\c\n\r\t", `"a\"b\\c\n\r\t"`, false},
+		{"\x00\x01\x1f\b\f", `"\u0000\u0001\u001f\u0008\u000c"`, false},
+		{"<'>&", `"\u003c\u0027>&"`, false},
+		{"<'>&", `"\u003c\u0027\u003e\u0026"`, true},
+		{"\u2028\u2029", "\"\u2028\u2029\"", false},
+		{"\u2028\u2029", `"\u2028\u2029"`, true},
+		{"世界🌐", `"世界🌐"`, false},
+		{"a\xffb\xe4\xb8", `"a\ufffdb\ufffd\ufffd"`, false},
+	}
+
+	for _, c := range cases {
+		var buf bytes.Buffer
+		logger := Logger{EscapeHTML: c.HTML, Writer: IOWriter{&buf}}
+		logger.Info().Str("s", c.Value).Msg("")
+		if !strings.Contains(buf.String(), `"s":`+c.Output+`}`) {
+			t.Errorf("escape %q mismatch, got %s want %s", c.Value, buf.String(), c.Output)
+		}
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("escape %q output is not valid json: %s", c.Value, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	for i := 0; i < 256; i++ {
+		buf.Reset()
+		logger.Info().Byte("b", byte(i)).Msg("")
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("escape byte %#x output is not valid json: %s", i, buf.String())
+		}
+	}
+}
+
+func TestLoggerMaxValueSize(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{MaxValueSize: 8, Writer: IOWriter{&buf}}
+
+	cases := []struct {
+		Value  string
+		Output string
+	}{
+		{"short", `"short"`},
+		{"12345678", `"12345678"`},
+		{"123456789", `"12345678...(truncated)"`},
+		{"1234567世界", `"1234567...(truncated)"`},
+		{"\n\n\n\n\n\n\n\n\n", `"\n\n\n\n\n\n\n\n...(truncated)"`},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		logger.Info().Str("s", c.Value).Bytes("b", []byte(c.Value)).Msg("")
+		if !strings.Contains(buf.String(), `"s":`+c.Output+`,"b":`+c.Output+`}`) {
+			t.Errorf("max value size %q mismatch, got %s want %s", c.Value, buf.String(), c.Output)
+		}
+	}
+
+	buf.Reset()
+	logger.Info().Msg("a long long message")
+	if !strings.Contains(buf.String(), `"message":"a long l...(truncated)"`) {
+		t.Errorf("max value size message mismatch: %s", buf.String())
+	}
+}
+
+func TestLoggerStacktrace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Error().Stacktrace().Msg("hello stacktrace")
+
+	var entry struct {
+		Stacktrace []string `json:"stacktrace"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("stacktrace entry invalid: %+v %s", err, buf.String())
+	}
+	if strings.Count(buf.String(), "\n") != 1 || len(entry.Stacktrace) < 2 || !strings.HasPrefix(entry.Stacktrace[0], "goroutine ") {
+		t.Errorf("stacktrace mismatch: %q", entry.Stacktrace)
+	}
+	for _, line := range entry.Stacktrace {
+		if line == "" || line[0] == '\t' || strings.Contains(line, "\n") {
+			t.Errorf("stacktrace line mismatch: %q", line)
+		}
+	}
+}
+
+func TestLoggerHumanFormat(t *testing.T) {
+	durations := []struct {
+		D    time.Duration
+		Text string
+	}{
+		{0, "0ns"},
+		{999, "999ns"},
+		{1500 * time.Nanosecond, "1.5µs"},
+		{250 * time.Millisecond, "250ms"},
+		{1234567 * time.Microsecond, "1.23s"},
+		{-2 * time.Second, "-2s"},
+		{90*time.Second + 400*time.Millisecond, "1m30s"},
+	}
+	for _, c := range durations {
+		if s := string(appendHumanDuration(nil, c.D)); s != c.Text {
+			t.Errorf("appendHumanDuration(%d) = %s, want %s", c.D, s, c.Text)
+		}
+	}
+
+	sizes := []struct {
+		N    int64
+		Text string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1 KiB"},
+		{1536, "1.5 KiB"},
+		{5 << 30, "5 GiB"},
+		{-2048, "-2 KiB"},
+		{1<<63 - 1, "8 EiB"},
+	}
+	for _, c := range sizes {
+		if s := string(appendByteSize(nil, c.N)); s != c.Text {
+			t.Errorf("appendByteSize(%d) = %s, want %s", c.N, s, c.Text)
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().DurHuman("elapsed", 1530*time.Millisecond).ByteSize("size", 1536).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"elapsed_ms":1530,"elapsed":"1.53s","size_bytes":1536,"size":"1.5 KiB"}`) {
+		t.Errorf("human format fields mismatch: %s", s)
+	}
+}
+
+func TestLoggerLazy(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Level: InfoLevel, Writer: IOWriter{&buf}}
+
+	calls := 0
+	dump := func() interface{} {
+		calls++
+		return map[string]int{"rows": 2}
+	}
+	name := func() string {
+		calls++
+		return "bob"
+	}
+	logger.Debug().Func("dump", dump).LazyStr("name", name).Msg("")
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("lazy fields should not be evaluated for disabled entries: %d %s", calls, buf.String())
+	}
+
+	logger.Info().Func("dump", dump).LazyStr("name", name).Func("nil", func() interface{} { return nil }).Msg("")
+	if s := buf.String(); calls != 2 || !strings.Contains(s, `"name":"bob","nil":null`) || !strings.Contains(s, `"dump":`) {
+		t.Errorf("lazy fields mismatch: %d %s", calls, s)
+	}
+}
+
+func TestLoggerOmitEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+
+	logger.Info().
+		StrNonEmpty("s0", "").StrNonEmpty("s1", "a").
+		IntNonZero("i0", 0).IntNonZero("i1", 1).
+		Int64NonZero("l0", 0).Int64NonZero("l1", -1).
+		Float64NonZero("f0", 0).Float64NonZero("f1", 0.5).
+		DurNonZero("d0", 0).DurNonZero("d1", time.Second).
+		If(false, func(e *Entry) { e.Str("c0", "a") }).
+		If(true, func(e *Entry) { e.Str("c1", "a") }).
+		Msg("")
+	if s := buf.String(); !strings.HasSuffix(s, `"level":"info","s1":"a","i1":1,"l1":-1,"f1":0.5,"d1":"1s","c1":"a"}`+"\n") {
+		t.Errorf("omit empty fields mismatch: %s", s)
+	}
+}
+
+func TestLoggerFieldProviders(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	logger := Logger{
+		Level:   InfoLevel,
+		Context: NewContext(nil).Str("app", "web").Value(),
+		FieldProviders: []FieldProvider{
+			func(e *Entry) { calls++; e.Str("tenant", "acme") },
+			func(e *Entry) { e.Bool("beta", true) },
+		},
+		Writer: IOWriter{&buf},
+	}
+
+	logger.Debug().Msg("disabled")
+	if calls != 0 || buf.Len() != 0 {
+		t.Errorf("field providers should not run for disabled entries: %d %s", calls, buf.String())
+	}
+
+	logger.Info().Str("a", "b").Msg("hello")
+	if s := buf.String(); calls != 1 || !strings.Contains(s, `"level":"info","app":"web","tenant":"acme","beta":true,"a":"b","message":"hello"}`) {
+		t.Errorf("field providers mismatch: %d %s", calls, s)
+	}
+
+	buf.Reset()
+	sub := logger.Group("http")
+	sub.Info().Int("status", 200).Msg("")
+	if s := buf.String(); !strings.Contains(s, `"tenant":"acme","beta":true,"http":{"status":200}}`) {
+		t.Errorf("field providers should add top-level fields: %s", s)
+	}
+}
@@ -103,3 +103,119 @@ func (l *LogrLogger) V(level int) *LogrLogger {
 	}
 	return nil
 }
+
+// LogrSink implements methods of interface github.com/go-logr/logr.LogSink
+// for logr v1 or later. The V-levels are mapped to Info(0), Debug(1) and Trace(2+).
+//
+// To keep dependency free, the Init, WithValues and WithName methods use
+// builtin types, a thin wrapper is needed to create the logr.Logger, e.g.
+//
+//	type sink struct{ *log.LogrSink }
+//
+//	func (s sink) Init(info logr.RuntimeInfo)                { s.LogrSink.Init(info.CallDepth) }
+//	func (s sink) WithValues(kv ...interface{}) logr.LogSink { return sink{s.LogrSink.WithValues(kv...)} }
+//	func (s sink) WithName(name string) logr.LogSink        { return sink{s.LogrSink.WithName(name)} }
+//
+//	var logger logr.Logger = logr.New(sink{log.DefaultLogger.LogrSink(nil)})
+type LogrSink struct {
+	logger  Logger
+	context Context
+	name    string
+	depth   int
+}
+
+// LogrSink wraps the Logger to provide a logr sink
+func (l *Logger) LogrSink(context Context) *LogrSink {
+	if l == nil {
+		return nil
+	}
+	return &LogrSink{
+		logger:  *l,
+		context: context,
+	}
+}
+
+// Init receives the number of call frames added by logr.
+func (s *LogrSink) Init(callDepth int) {
+	if s == nil {
+		return
+	}
+	s.depth = callDepth
+}
+
+func (s *LogrSink) level(v int) Level {
+	switch {
+	case v <= 0:
+		return InfoLevel
+	case v == 1:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+// Enabled tests whether this LogSink is enabled at the specified V-level.
+func (s *LogrSink) Enabled(level int) bool {
+	return s != nil && uint32(s.level(level)) >= uint32(s.logger.Level)
+}
+
+// Info logs a non-error message with the given key/value pairs as context.
+// The level argument is provided for optional logging.
+func (s *LogrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if s == nil {
+		return
+	}
+	e := s.logger.header(s.level(level))
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller + s.depth))
+	}
+	if s.name != "" {
+		e.Str("logger", s.name)
+	}
+	e.Context(s.context).KeysAndValues(keysAndValues...).Msg(msg)
+}
+
+// Error logs an error, with the given message and key/value pairs as context.
+func (s *LogrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if s == nil {
+		return
+	}
+	e := s.logger.header(ErrorLevel)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller + s.depth))
+	}
+	if s.name != "" {
+		e.Str("logger", s.name)
+	}
+	e.Context(s.context).Err(err).KeysAndValues(keysAndValues...).Msg(msg)
+}
+
+// WithValues returns a new LogSink with additional key/value pairs.
+func (s *LogrSink) WithValues(keysAndValues ...interface{}) *LogrSink {
+	if s == nil {
+		return nil
+	}
+	sink := *s
+	sink.context = append(append(Context(nil), s.context...), NewContext(nil).KeysAndValues(keysAndValues...).Value()...)
+	return &sink
+}
+
+// WithName returns a new LogSink with the specified name appended.
+func (s *LogrSink) WithName(name string) *LogrSink {
+	if s == nil {
+		return nil
+	}
+	sink := *s
+	if sink.name != "" {
+		sink.name += "/" + name
+	} else {
+		sink.name = name
+	}
+	return &sink
+}
@@ -1,7 +1,9 @@
 package log
 
 import (
+	"bytes"
 	"errors"
+	"strings"
 	"testing"
 )
 
@@ -66,3 +68,65 @@ func TestLogrLoggerLevel(t *testing.T) {
 	logr.Info("hello", "foo", "bar", "number", 42)
 	logr.Error(errors.New("this is a error"), "hello", "foo", "bar", "number", 42)
 }
+
+type logrSink interface {
+	Init(callDepth int)
+	Enabled(level int) bool
+	Info(level int, msg string, keysAndValues ...interface{})
+	Error(err error, msg string, keysAndValues ...interface{})
+	WithValues(keysAndValues ...interface{}) *LogrSink
+	WithName(name string) *LogrSink
+}
+
+func TestLogrSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Level:  DebugLevel,
+		Caller: 1,
+		Writer: IOWriter{&buf},
+	}
+
+	var sink logrSink = logger.LogrSink(NewContext(nil).Str("tag", "hi logr sink").Value())
+	sink.Init(1)
+
+	sink = sink.WithName("controller").WithName("reconciler").WithValues("a_key", "a_value")
+	sink.Info(0, "hello", "foo", "bar")
+	if s := buf.String(); !strings.Contains(s, `"level":"info"`) || !strings.Contains(s, `"logger":"controller/reconciler","tag":"hi logr sink","a_key":"a_value","foo":"bar","message":"hello"`) {
+		t.Errorf("logr sink output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	sink.Info(1, "hello debug")
+	if s := buf.String(); !strings.Contains(s, `"level":"debug"`) {
+		t.Errorf("logr sink V(1) should be debug level: %s", s)
+	}
+
+	buf.Reset()
+	sink.Info(2, "hello trace")
+	if s := buf.String(); s != "" {
+		t.Errorf("logr sink V(2) should be disabled: %s", s)
+	}
+	if sink.Enabled(2) || !sink.Enabled(1) || !sink.Enabled(0) {
+		t.Errorf("logr sink enabled mismatch")
+	}
+
+	sink.Error(errors.New("this is a error"), "hello", "number", 42)
+	if s := buf.String(); !strings.Contains(s, `"error":"this is a error"`) {
+		t.Errorf("logr sink error output mismatch: %s", s)
+	}
+}
+
+func TestLogrSinkNil(t *testing.T) {
+	var logger *Logger
+
+	var sink logrSink = logger.LogrSink(nil)
+
+	sink.Init(1)
+	sink.Info(0, "hello", "foo", "bar")
+	sink.Error(errors.New("this is a error"), "hello")
+	sink = sink.WithName("a_named_logger").WithValues("a_key", "a_value")
+	if sink.Enabled(0) {
+		t.Errorf("nil logr sink should be disabled")
+	}
+}
@@ -0,0 +1,166 @@
+// Package logtest provides an in-memory writer and assertion helpers to unit-test the logging
+// of applications without parsing json by hand.
+package logtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/phuslu/log"
+)
+
+// Entry is a parsed log entry recorded by ObservedWriter.
+type Entry struct {
+	// Time is the raw time field of entry.
+	Time string
+
+	// Level is the level of entry.
+	Level log.Level
+
+	// Message is the message of entry.
+	Message string
+
+	// Fields is the fields of entry except time, level and message.
+	Fields map[string]interface{}
+
+	// JSON is the json of entry.
+	JSON []byte
+
+	raw map[string]json.RawMessage
+}
+
+// ObservedWriter is an io.Writer which records the parsed entries in memory, e.g.
+//
+//	w := logtest.New()
+//	logger := w.Logger()
+//	logger.Info().Int("status", 200).Msg("hello")
+//	if !w.ContainsEntry(log.InfoLevel, "hello", "status", 200) {
+//		t.Errorf("missing entry: %v", w.Entries())
+//	}
+type ObservedWriter struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// New returns an empty ObservedWriter.
+func New() *ObservedWriter {
+	return &ObservedWriter{}
+}
+
+// Logger returns a logger at TraceLevel which writes to w.
+func (w *ObservedWriter) Logger() *log.Logger {
+	return &log.Logger{
+		Level:  log.TraceLevel,
+		Writer: log.IOWriter{Writer: w},
+	}
+}
+
+// Write implements io.Writer, it parses and records the json entries of p.
+func (w *ObservedWriter) Write(p []byte) (int, error) {
+	var entries []Entry
+	for _, line := range bytes.Split(p, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		e, err := parse(line)
+		if err != nil {
+			return 0, err
+		}
+		entries = append(entries, e)
+	}
+
+	w.mu.Lock()
+	w.entries = append(w.entries, entries...)
+	w.mu.Unlock()
+
+	return len(p), nil
+}
+
+func parse(line []byte) (e Entry, err error) {
+	if err = json.Unmarshal(line, &e.raw); err != nil {
+		return
+	}
+	e.JSON = append([]byte(nil), line...)
+	e.Fields = make(map[string]interface{}, len(e.raw))
+	for key, value := range e.raw {
+		switch key {
+		case "time":
+			json.Unmarshal(value, &e.Time)
+			if e.Time == "" {
+				e.Time = string(value)
+			}
+		case "level":
+			var s string
+			json.Unmarshal(value, &s)
+			e.Level = log.ParseLevel(s)
+		case "message":
+			json.Unmarshal(value, &e.Message)
+		default:
+			var v interface{}
+			json.Unmarshal(value, &v)
+			e.Fields[key] = v
+		}
+	}
+	return
+}
+
+// Entries returns a copy of the recorded entries.
+func (w *ObservedWriter) Entries() []Entry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Entry(nil), w.entries...)
+}
+
+// Len returns the number of recorded entries.
+func (w *ObservedWriter) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.entries)
+}
+
+// Reset removes the recorded entries.
+func (w *ObservedWriter) Reset() {
+	w.mu.Lock()
+	w.entries = nil
+	w.mu.Unlock()
+}
+
+// ContainsEntry reports whether an entry of level and msg is recorded with the fields, which are
+// key/value pairs compared by their decoded json values, e.g. ContainsEntry(log.InfoLevel, "hello", "status", 200).
+func (w *ObservedWriter) ContainsEntry(level log.Level, msg string, fields ...interface{}) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, e := range w.entries {
+		if e.Level == level && e.Message == msg && e.match(fields) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Entry) match(fields []interface{}) bool {
+	for i := 0; i < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		raw, ok := e.raw[key]
+		if !ok {
+			return false
+		}
+		if i+1 == len(fields) {
+			continue
+		}
+		// compares the decoded values, the logger and encoding/json escape strings differently,
+		// e.g. "<" is escaped by both but "&" and ">" only by encoding/json.
+		data, err := json.Marshal(fields[i+1])
+		if err != nil {
+			return false
+		}
+		var got, want interface{}
+		if json.Unmarshal(raw, &got) != nil || json.Unmarshal(data, &want) != nil || !reflect.DeepEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
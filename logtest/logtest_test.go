@@ -0,0 +1,53 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/phuslu/log"
+)
+
+func TestObservedWriter(t *testing.T) {
+	w := New()
+	logger := w.Logger()
+
+	logger.Info().Int("status", 200).Str("path", "/").Msg("hello")
+	logger.Error().Bool("retry", true).Floats64("ratio", []float64{0.5}).Msg("failed")
+	logger.With("app", "demo").Debug().Msg("")
+	logger.Warn().Str("query", "a<b && c>d").Msg("html")
+
+	if w.Len() != 4 {
+		t.Fatalf("observed writer length mismatch: %d", w.Len())
+	}
+
+	cases := []struct {
+		Level  log.Level
+		Msg    string
+		Fields []interface{}
+		Want   bool
+	}{
+		{log.InfoLevel, "hello", nil, true},
+		{log.InfoLevel, "hello", []interface{}{"status", 200, "path", "/"}, true},
+		{log.InfoLevel, "hello", []interface{}{"status"}, true},
+		{log.InfoLevel, "hello", []interface{}{"status", 404}, false},
+		{log.InfoLevel, "hello", []interface{}{"missing", 1}, false},
+		{log.WarnLevel, "hello", nil, false},
+		{log.ErrorLevel, "failed", []interface{}{"retry", true, "ratio", []float64{0.5}}, true},
+		{log.DebugLevel, "", []interface{}{"app", "demo"}, true},
+		{log.WarnLevel, "html", []interface{}{"query", "a<b && c>d"}, true},
+	}
+	for _, c := range cases {
+		if got := w.ContainsEntry(c.Level, c.Msg, c.Fields...); got != c.Want {
+			t.Errorf("ContainsEntry(%v, %q, %v) = %v, want %v", c.Level, c.Msg, c.Fields, got, c.Want)
+		}
+	}
+
+	e := w.Entries()[0]
+	if e.Level != log.InfoLevel || e.Message != "hello" || e.Fields["status"] != float64(200) || e.Time == "" {
+		t.Errorf("observed entry mismatch: %+v", e)
+	}
+
+	w.Reset()
+	if w.Len() != 0 || w.ContainsEntry(log.InfoLevel, "hello") {
+		t.Errorf("observed writer should be reset")
+	}
+}
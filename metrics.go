@@ -0,0 +1,148 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// metricsLatencyBuckets is the upper bounds of write latency histogram buckets.
+var metricsLatencyBuckets = [...]time.Duration{
+	10 * time.Microsecond,
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// Metrics collects the counters of a logging pipeline. It implements expvar.Var, e.g.
+//
+//	metrics := &log.Metrics{}
+//	expvar.Publish("log", metrics)
+//	log.DefaultLogger.Writer = &log.MetricsWriter{
+//		Metrics: metrics,
+//		Writer:  &log.FileWriter{Filename: "main.log", OnRotate: metrics.OnRotate},
+//	}
+type Metrics struct {
+	entries   [noLevel + 1]int64
+	bytes     int64
+	dropped   int64
+	rotations int64
+	latency   [len(metricsLatencyBuckets) + 1]int64
+	latencyNs int64
+}
+
+// MetricsSnapshot is a point-in-time copy of Metrics, e.g. for exporting to Prometheus.
+type MetricsSnapshot struct {
+	// Entries is the number of written entries by level.
+	Entries map[string]int64 `json:"entries"`
+
+	// Bytes is the number of written bytes.
+	Bytes int64 `json:"bytes"`
+
+	// Dropped is the number of entries failed to write.
+	Dropped int64 `json:"dropped"`
+
+	// Rotations is the number of rotations.
+	Rotations int64 `json:"rotations"`
+
+	// LatencyBuckets is the cumulative histogram of write latency, keyed by the upper
+	// bounds in seconds, and "+Inf".
+	LatencyBuckets map[string]int64 `json:"latency_buckets"`
+
+	// LatencySum is the total write latency in seconds.
+	LatencySum float64 `json:"latency_sum"`
+}
+
+// OnRotate counts a rotation, it fits the OnRotate of FileWriter.
+func (m *Metrics) OnRotate(oldname, newname string) {
+	atomic.AddInt64(&m.rotations, 1)
+}
+
+// Snapshot returns a copy of the current metrics.
+func (m *Metrics) Snapshot() (s MetricsSnapshot) {
+	s.Entries = make(map[string]int64)
+	for level := range m.entries {
+		if n := atomic.LoadInt64(&m.entries[level]); n != 0 {
+			name := Level(level).String()
+			if level == 0 || level == int(noLevel) {
+				name = "none"
+			}
+			s.Entries[name] += n
+		}
+	}
+	s.Bytes = atomic.LoadInt64(&m.bytes)
+	s.Dropped = atomic.LoadInt64(&m.dropped)
+	s.Rotations = atomic.LoadInt64(&m.rotations)
+	s.LatencyBuckets = make(map[string]int64)
+	var count int64
+	for i := range m.latency {
+		count += atomic.LoadInt64(&m.latency[i])
+		if i < len(metricsLatencyBuckets) {
+			s.LatencyBuckets[strconv.FormatFloat(metricsLatencyBuckets[i].Seconds(), 'g', -1, 64)] = count
+		} else {
+			s.LatencyBuckets["+Inf"] = count
+		}
+	}
+	s.LatencySum = float64(atomic.LoadInt64(&m.latencyNs)) / float64(time.Second)
+	return
+}
+
+// String implements expvar.Var, returns the snapshot in json.
+func (m *Metrics) String() string {
+	b, _ := json.Marshal(m.Snapshot())
+	return string(b)
+}
+
+func (m *Metrics) observe(level Level, n int, err error, latency time.Duration) {
+	if level > noLevel {
+		level = noLevel
+	}
+	if err != nil {
+		atomic.AddInt64(&m.dropped, 1)
+	} else {
+		atomic.AddInt64(&m.entries[level], 1)
+	}
+	atomic.AddInt64(&m.bytes, int64(n))
+	i := 0
+	for i < len(metricsLatencyBuckets) && latency > metricsLatencyBuckets[i] {
+		i++
+	}
+	atomic.AddInt64(&m.latency[i], 1)
+	atomic.AddInt64(&m.latencyNs, int64(latency))
+}
+
+// MetricsWriter is an Writer that collects the metrics of Writer.
+type MetricsWriter struct {
+	// Metrics specifies the metrics to collect into.
+	Metrics *Metrics
+
+	// Writer specifies the writer of output.
+	Writer Writer
+}
+
+// Close implements io.Closer, and closes the underlying Writer.
+func (w *MetricsWriter) Close() (err error) {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		err = closer.Close()
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *MetricsWriter) WriteEntry(e *Entry) (n int, err error) {
+	start := time.Now()
+	n, err = w.Writer.WriteEntry(e)
+	w.Metrics.observe(e.Level, n, err, time.Since(start))
+	return
+}
+
+var _ Writer = (*MetricsWriter)(nil)
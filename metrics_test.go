@@ -0,0 +1,46 @@
+package log
+
+import (
+	"encoding/json"
+	"expvar"
+	"io/ioutil"
+	"testing"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	metrics := &Metrics{}
+	expvar.Publish("log_test_metrics", metrics)
+
+	primary := &testFailWriter{}
+	logger := Logger{
+		Level:  TraceLevel,
+		Writer: &MetricsWriter{Metrics: metrics, Writer: primary},
+	}
+
+	logger.Info().Msg("hello metrics")
+	logger.Info().Msg("hello metrics")
+	logger.Error().Msg("hello metrics")
+	logger.Log().Msg("hello metrics")
+	primary.fail = true
+	logger.Warn().Msg("hello metrics")
+
+	metrics.OnRotate("a.log", "b.log")
+
+	s := metrics.Snapshot()
+	if s.Entries["info"] != 2 || s.Entries["error"] != 1 || s.Entries["none"] != 1 || s.Entries["warn"] != 0 {
+		t.Errorf("metrics entries mismatch: %+v", s.Entries)
+	}
+	if s.Dropped != 1 || s.Rotations != 1 || s.Bytes == 0 {
+		t.Errorf("metrics counters mismatch: %+v", s)
+	}
+	if s.LatencyBuckets["+Inf"] != 5 || s.LatencyBuckets["1"] > 5 || s.LatencySum < 0 {
+		t.Errorf("metrics latency mismatch: %+v", s)
+	}
+
+	var v MetricsSnapshot
+	if err := json.Unmarshal([]byte(expvar.Get("log_test_metrics").String()), &v); err != nil || v.Entries["info"] != 2 {
+		t.Errorf("metrics expvar mismatch: %+v, %+v", v, err)
+	}
+
+	(&MetricsWriter{Metrics: metrics, Writer: IOWriter{ioutil.Discard}}).Close()
+}
@@ -0,0 +1,256 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MQTTWriter is an Writer that publishes entries to a MQTT 3.1.1 topic, e.g. "logs/{level}",
+// where {name} is replaced with the value of field name of entry.
+type MQTTWriter struct {
+	// Address specifies address of the MQTT broker, e.g. 127.0.0.1:1883
+	Address string
+
+	// Topic specifies the topic template, e.g. "logs/{service}/{level}". A missing field is
+	// replaced with "_", and the wildcard and separator chars of values with '_'.
+	Topic string
+
+	// ClientID specifies the client identifier, the broker assigns one if empty.
+	ClientID string
+
+	// Username and Password specify the credentials of connecting.
+	Username string
+	Password string
+
+	// QoS specifies the QoS level of publishing, 0 (at most once) or 1 (at least once).
+	// With QoS 1, a publishing waits for the acknowledgement of broker.
+	QoS byte
+
+	// Retain determines if the broker retains the last entry of topics.
+	Retain bool
+
+	// Dial specifies the dial function for creating TCP/TLS connections.
+	Dial func(network, addr string) (net.Conn, error)
+
+	// DialTimeout specifies the timeout of connecting and handshaking, the default is 10s.
+	DialTimeout time.Duration
+
+	// WriteTimeout specifies the timeout of publishing a log, the default is 10s.
+	WriteTimeout time.Duration
+
+	mu       sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+	packetID uint16
+	err      error
+}
+
+// Healthy implements HealthChecker, returns the error of last connecting or publishing.
+func (w *MQTTWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close sends DISCONNECT and closes the connection to the MQTT broker.
+func (w *MQTTWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		w.conn.Write([]byte{0xe0, 0x00})
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	return
+}
+
+// connect makes a connection to the MQTT broker.
+func (w *MQTTWriter) connect() (err error) {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	timeout := w.DialTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	var dial = w.Dial
+	if dial == nil {
+		dial = func(network, addr string) (net.Conn, error) {
+			return net.DialTimeout(network, addr, timeout)
+		}
+	}
+
+	conn, err := dial("tcp", w.Address)
+	if err != nil {
+		return
+	}
+
+	// variable header: protocol name, level 4, flags and keep alive 0 (disabled)
+	var flags byte = 0x02 // clean session
+	if w.Username != "" {
+		flags |= 0x80
+		if w.Password != "" {
+			flags |= 0x40
+		}
+	}
+	b := []byte{0x00, 0x04, 'M', 'Q', 'T', 'T', 0x04, flags, 0x00, 0x00}
+	b = appendMQTTString(b, w.ClientID)
+	if w.Username != "" {
+		b = appendMQTTString(b, w.Username)
+		if w.Password != "" {
+			b = appendMQTTString(b, w.Password)
+		}
+	}
+	b = appendMQTTPacket(nil, 0x10, b)
+
+	conn.SetDeadline(timeNow().Add(timeout))
+	if _, err = conn.Write(b); err != nil {
+		conn.Close()
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+	var connack [4]byte
+	if _, err = io.ReadFull(reader, connack[:]); err != nil {
+		conn.Close()
+		return
+	}
+	if connack[0] != 0x20 || connack[3] != 0 {
+		conn.Close()
+		return errors.New("log: mqtt connect refused with code " + strconv.Itoa(int(connack[3])))
+	}
+	conn.SetDeadline(time.Time{})
+
+	w.conn = conn
+	w.reader = reader
+	return
+}
+
+// WriteEntry implements Writer, publishes the entry to the topic.
+func (w *MQTTWriter) WriteEntry(e *Entry) (n int, err error) {
+	payload := e.buf
+	if len(payload) != 0 && payload[len(payload)-1] == '\n' {
+		payload = payload[:len(payload)-1]
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if n, err = w.publish(e, payload); err == nil {
+			return
+		}
+	}
+	if err = w.connect(); err != nil {
+		w.err = err
+		return
+	}
+	n, err = w.publish(e, payload)
+	w.err = err
+	return
+}
+
+func (w *MQTTWriter) publish(e *Entry, payload []byte) (n int, err error) {
+	b := bbget()
+	defer bbput(b)
+
+	var header byte = 0x30
+	if w.QoS > 0 {
+		header |= 0x02
+	}
+	if w.Retain {
+		header |= 0x01
+	}
+
+	// topic, the length is filled later
+	b.B = append(b.B, 0, 0)
+	b.B = appendTopic(b.B, w.Topic, e, "+#/")
+	b.B[0], b.B[1] = byte((len(b.B)-2)>>8), byte(len(b.B)-2)
+	if w.QoS > 0 {
+		w.packetID++
+		if w.packetID == 0 {
+			w.packetID = 1
+		}
+		b.B = append(b.B, byte(w.packetID>>8), byte(w.packetID))
+	}
+	b.B = append(b.B, payload...)
+
+	p := bbget()
+	defer bbput(p)
+	p.B = appendMQTTPacket(p.B, header, b.B)
+
+	timeout := w.WriteTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	w.conn.SetDeadline(timeNow().Add(timeout))
+
+	if _, err = w.conn.Write(p.B); err != nil {
+		return
+	}
+	n = len(e.buf)
+	if w.QoS == 0 {
+		return
+	}
+
+	// wait for the PUBACK of packet id, skipping other packets
+	for {
+		var typ byte
+		if typ, err = w.reader.ReadByte(); err != nil {
+			return
+		}
+		var length, shift uint
+		for {
+			c, err := w.reader.ReadByte()
+			if err != nil {
+				return n, err
+			}
+			length |= uint(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		body := make([]byte, length)
+		if _, err = io.ReadFull(w.reader, body); err != nil {
+			return
+		}
+		if typ&0xf0 == 0x40 && len(body) == 2 && uint16(body[0])<<8|uint16(body[1]) == w.packetID {
+			return
+		}
+	}
+}
+
+// appendMQTTPacket appends the MQTT control packet with header and body to dst.
+func appendMQTTPacket(dst []byte, header byte, body []byte) []byte {
+	dst = append(dst, header)
+	length := len(body)
+	for {
+		c := byte(length & 0x7f)
+		length >>= 7
+		if length > 0 {
+			c |= 0x80
+		}
+		dst = append(dst, c)
+		if length == 0 {
+			break
+		}
+	}
+	return append(dst, body...)
+}
+
+func appendMQTTString(dst []byte, s string) []byte {
+	dst = append(dst, byte(len(s)>>8), byte(len(s)))
+	return append(dst, s...)
+}
+
+var _ Writer = (*MQTTWriter)(nil)
@@ -0,0 +1,93 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestMQTTWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %+v", err)
+	}
+	defer ln.Close()
+
+	type packet struct {
+		header byte
+		body   []byte
+	}
+	packets := make(chan packet, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		defer close(packets)
+		reader := bufio.NewReader(conn)
+		for {
+			header, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			var length, shift uint
+			for {
+				c, _ := reader.ReadByte()
+				length |= uint(c&0x7f) << shift
+				shift += 7
+				if c&0x80 == 0 {
+					break
+				}
+			}
+			body := make([]byte, length)
+			io.ReadFull(reader, body)
+			packets <- packet{header, body}
+			switch header & 0xf0 {
+			case 0x10:
+				conn.Write([]byte{0x20, 0x02, 0x00, 0x00})
+			case 0x30:
+				if header&0x06 != 0 {
+					n := int(body[0])<<8 | int(body[1])
+					conn.Write([]byte{0x40, 0x02, body[2+n], body[3+n]})
+				}
+			}
+		}
+	}()
+
+	w := &MQTTWriter{
+		Address:  ln.Addr().String(),
+		Topic:    "logs/{service}/{level}",
+		ClientID: "test",
+		Username: "user",
+		Password: "pass",
+		QoS:      1,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Str("service", "a/b").Str("long", strings.Repeat("x", 200)).Msg("hello mqtt")
+	logger.Warn().Msg("hello missing")
+	if err := w.Healthy(); err != nil {
+		t.Errorf("mqtt writer should be healthy: %+v", err)
+	}
+	w.Close()
+
+	var got []packet
+	for p := range packets {
+		got = append(got, p)
+	}
+	if len(got) != 4 || got[0].header != 0x10 || got[3].header != 0xe0 {
+		t.Fatalf("mqtt writer packets mismatch: %+v", got)
+	}
+	if body := string(got[0].body); !strings.HasPrefix(body, "\x00\x04MQTT\x04\xc2") || !strings.HasSuffix(body, "\x00\x04test\x00\x04user\x00\x04pass") {
+		t.Errorf("mqtt writer connect mismatch: %q", body)
+	}
+	if p := got[1]; p.header != 0x32 || !strings.HasPrefix(string(p.body), "\x00\x0dlogs/a_b/info\x00\x01{") || !strings.HasSuffix(string(p.body), `"message":"hello mqtt"}`) {
+		t.Errorf("mqtt writer publish mismatch: %x %q", p.header, p.body)
+	}
+	if p := got[2]; !strings.HasPrefix(string(p.body), "\x00\x0blogs/_/warn\x00\x02{") {
+		t.Errorf("mqtt writer missing field mismatch: %q", p.body)
+	}
+}
@@ -0,0 +1,339 @@
+package log
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// MsgpackWriter is an Writer that writes entries in MessagePack format to Writer.
+// Each entry is encoded as a msgpack map, use MsgpackToJSON to decode them.
+type MsgpackWriter struct {
+	// Writer is the output destination. using os.Stderr if empty.
+	Writer io.Writer
+}
+
+// Close implements io.Closer, will closes the underlying Writer if not empty.
+func (w *MsgpackWriter) Close() (err error) {
+	if w.Writer != nil {
+		if closer, ok := w.Writer.(io.Closer); ok {
+			err = closer.Close()
+		}
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *MsgpackWriter) WriteEntry(e *Entry) (int, error) {
+	return w.Write(e.buf)
+}
+
+// Write implements io.Writer.
+func (w *MsgpackWriter) Write(p []byte) (int, error) {
+	out := w.Writer
+	if out == nil {
+		out = os.Stderr
+	}
+
+	b := bbget()
+	defer bbput(b)
+
+	b.B = appendMsgpack(b.B, p, 'o')
+
+	if _, err := out.Write(b.B); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendMsgpack appends the json value with typ to dst in msgpack format.
+func appendMsgpack(dst, value []byte, typ byte) []byte {
+	switch typ {
+	case 's':
+		return appendMsgpackString(dst, value[1:len(value)-1])
+	case 'S':
+		b := bbget()
+		b.B = jsonUnescape(value[1:len(value)-1], b.B)
+		dst = appendMsgpackString(dst, b.B)
+		bbput(b)
+		return dst
+	case 'n':
+		if i, err := strconv.ParseInt(b2s(value), 10, 64); err == nil {
+			return appendMsgpackInt(dst, i)
+		}
+		if u, err := strconv.ParseUint(b2s(value), 10, 64); err == nil {
+			dst = append(dst, 0xcf)
+			return appendUint64(dst, u)
+		}
+		f, _ := strconv.ParseFloat(b2s(value), 64)
+		dst = append(dst, 0xcb)
+		return appendUint64(dst, math.Float64bits(f))
+	case 't':
+		return append(dst, 0xc3)
+	case 'f':
+		return append(dst, 0xc2)
+	case 'o':
+		if len(value) > 0 && value[0] == '[' {
+			return appendMsgpackArray(dst, value)
+		}
+		n := 0
+		jsonObjectEach(value, func(key, value []byte, typ byte) { n++ })
+		dst = appendMsgpackHeader(dst, n, 0x80, 0xde, 0xdf)
+		jsonObjectEach(value, func(key, value []byte, typ byte) {
+			dst = appendMsgpackString(dst, key)
+			dst = appendMsgpack(dst, value, typ)
+		})
+		return dst
+	default:
+		return append(dst, 0xc0)
+	}
+}
+
+func appendMsgpackArray(dst, json []byte) []byte {
+	n := 0
+	jsonArrayEach(json, func(value []byte, typ byte) { n++ })
+	dst = appendMsgpackHeader(dst, n, 0x90, 0xdc, 0xdd)
+	jsonArrayEach(json, func(value []byte, typ byte) { dst = appendMsgpack(dst, value, typ) })
+	return dst
+}
+
+func appendMsgpackHeader(dst []byte, n int, fix, b16, b32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(dst, fix|byte(n))
+	case n < 1<<16:
+		return append(dst, b16, byte(n>>8), byte(n))
+	default:
+		return append(dst, b32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func appendMsgpackString(dst, s []byte) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n < 1<<8:
+		dst = append(dst, 0xd9, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 0xda, byte(n>>8), byte(n))
+	default:
+		dst = append(dst, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(dst, s...)
+}
+
+func appendMsgpackInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i < 128:
+		return append(dst, byte(i))
+	case i < 0 && i >= -32:
+		return append(dst, byte(i))
+	default:
+		dst = append(dst, 0xd3)
+		return appendUint64(dst, uint64(i))
+	}
+}
+
+func appendUint64(dst []byte, u uint64) []byte {
+	return append(dst, byte(u>>56), byte(u>>48), byte(u>>40), byte(u>>32), byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+}
+
+// ErrMsgpackInvalid is returned by MsgpackToJSON if the data is invalid or unsupported msgpack.
+var ErrMsgpackInvalid = errors.New("log: invalid msgpack data")
+
+// MsgpackToJSON decodes a msgpack value from data and appends it to dst as JSON.
+// It returns the number of bytes read from data, e.g. to decode an entry stream
+//
+//	for len(data) > 0 {
+//		json, n, err := log.MsgpackToJSON(nil, data)
+//		if err != nil {
+//			break
+//		}
+//		fmt.Printf("%s\n", json)
+//		data = data[n:]
+//	}
+func MsgpackToJSON(dst, data []byte) ([]byte, int, error) {
+	e := Entry{buf: dst}
+	n, err := e.msgpack(data)
+	return e.buf, n, err
+}
+
+// msgpackWidths is the byte widths of the lengths of str, bin, array and map, and of the
+// values of int, uint and float, by the msgpack type byte.
+var msgpackWidths = [256]int{
+	0xc4: 1, 0xc5: 2, 0xc6: 4,
+	0xca: 4, 0xcb: 8,
+	0xcc: 1, 0xcd: 2, 0xce: 4, 0xcf: 8,
+	0xd0: 1, 0xd1: 2, 0xd2: 4, 0xd3: 8,
+	0xd9: 1, 0xda: 2, 0xdb: 4,
+	0xdc: 2, 0xdd: 4,
+	0xde: 2, 0xdf: 4,
+}
+
+func (e *Entry) msgpack(data []byte) (n int, err error) {
+	if len(data) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+
+	size := func(i, width int) (int, error) {
+		if len(data) < i+width {
+			return 0, io.ErrUnexpectedEOF
+		}
+		switch width {
+		case 1:
+			return int(data[i]), nil
+		case 2:
+			return int(binary.BigEndian.Uint16(data[i:])), nil
+		default:
+			return int(binary.BigEndian.Uint32(data[i:])), nil
+		}
+	}
+
+	c := data[0]
+	switch {
+	case c <= 0x7f:
+		e.buf = strconv.AppendInt(e.buf, int64(c), 10)
+		return 1, nil
+	case c >= 0xe0:
+		e.buf = strconv.AppendInt(e.buf, int64(int8(c)), 10)
+		return 1, nil
+	case c >= 0xa0 && c <= 0xbf:
+		return e.msgpackString(data, 1, int(c&0x1f))
+	case c >= 0x90 && c <= 0x9f:
+		return e.msgpackArray(data, 1, int(c&0x0f))
+	case c >= 0x80 && c <= 0x8f:
+		return e.msgpackMap(data, 1, int(c&0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		e.buf = append(e.buf, "null"...)
+		return 1, nil
+	case 0xc2:
+		e.buf = append(e.buf, "false"...)
+		return 1, nil
+	case 0xc3:
+		e.buf = append(e.buf, "true"...)
+		return 1, nil
+	case 0xd9, 0xda, 0xdb, 0xc4, 0xc5, 0xc6:
+		width := msgpackWidths[c]
+		if n, err = size(1, width); err != nil {
+			return
+		}
+		return e.msgpackString(data, 1+width, n)
+	case 0xdc, 0xdd:
+		width := msgpackWidths[c]
+		if n, err = size(1, width); err != nil {
+			return
+		}
+		return e.msgpackArray(data, 1+width, n)
+	case 0xde, 0xdf:
+		width := msgpackWidths[c]
+		if n, err = size(1, width); err != nil {
+			return
+		}
+		return e.msgpackMap(data, 1+width, n)
+	case 0xcc, 0xcd, 0xce, 0xcf, 0xd0, 0xd1, 0xd2, 0xd3, 0xca, 0xcb:
+		width := msgpackWidths[c]
+		if len(data) < 1+width {
+			return 0, io.ErrUnexpectedEOF
+		}
+		var u uint64
+		for _, b := range data[1 : 1+width] {
+			u = u<<8 | uint64(b)
+		}
+		switch c {
+		case 0xcc, 0xcd, 0xce, 0xcf:
+			e.buf = strconv.AppendUint(e.buf, u, 10)
+		case 0xd0:
+			e.buf = strconv.AppendInt(e.buf, int64(int8(u)), 10)
+		case 0xd1:
+			e.buf = strconv.AppendInt(e.buf, int64(int16(u)), 10)
+		case 0xd2:
+			e.buf = strconv.AppendInt(e.buf, int64(int32(u)), 10)
+		case 0xd3:
+			e.buf = strconv.AppendInt(e.buf, int64(u), 10)
+		case 0xca:
+			e.msgpackFloat(float64(math.Float32frombits(uint32(u))), 32)
+		case 0xcb:
+			e.msgpackFloat(math.Float64frombits(u), 64)
+		}
+		return 1 + width, nil
+	}
+
+	return 0, ErrMsgpackInvalid
+}
+
+// msgpackFloat appends f as a json number, or as a string "NaN", "+Inf" or "-Inf" which json
+// numbers cannot represent.
+func (e *Entry) msgpackFloat(f float64, bitSize int) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		e.buf = append(e.buf, '"')
+		e.buf = strconv.AppendFloat(e.buf, f, 'f', -1, bitSize)
+		e.buf = append(e.buf, '"')
+		return
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, 'f', -1, bitSize)
+}
+
+func (e *Entry) msgpackString(data []byte, i, n int) (int, error) {
+	if len(data) < i+n {
+		return 0, io.ErrUnexpectedEOF
+	}
+	e.buf = append(e.buf, '"')
+	e.bytes(data[i : i+n])
+	e.buf = append(e.buf, '"')
+	return i + n, nil
+}
+
+func (e *Entry) msgpackArray(data []byte, i, n int) (int, error) {
+	e.buf = append(e.buf, '[')
+	for j := 0; j < n; j++ {
+		if j != 0 {
+			e.buf = append(e.buf, ',')
+		}
+		m, err := e.msgpack(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += m
+	}
+	e.buf = append(e.buf, ']')
+	return i, nil
+}
+
+func (e *Entry) msgpackMap(data []byte, i, n int) (int, error) {
+	e.buf = append(e.buf, '{')
+	for j := 0; j < n; j++ {
+		if j != 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if i >= len(data) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		// keys must be strings
+		if c := data[i]; !(c >= 0xa0 && c <= 0xbf || c >= 0xd9 && c <= 0xdb) {
+			return 0, ErrMsgpackInvalid
+		}
+		m, err := e.msgpack(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += m
+		e.buf = append(e.buf, ':')
+		m, err = e.msgpack(data[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += m
+	}
+	e.buf = append(e.buf, '}')
+	return i, nil
+}
+
+var _ Writer = (*MsgpackWriter)(nil)
+var _ io.Writer = (*MsgpackWriter)(nil)
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsgpackWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Writer: &MsgpackWriter{Writer: &buf},
+	}
+
+	long := strings.Repeat("x", 300)
+	logger.Info().
+		Str("foo", "bar").
+		Str("quote", "a \"b\"\n").
+		Str("long", long).
+		Int("n", 42).
+		Int("neg", -1).
+		Int64("big", -1234567890123).
+		Uint64("huge", 18446744073709551615).
+		Float64("pi", 3.14).
+		RawJSON("inf", []byte("-1e999")).
+		Bool("t", true).
+		Bool("f", false).
+		Err(nil).
+		Floats64("empty", nil).
+		Strs("tags", []string{"a", "b"}).
+		Dict("dict", NewContext(nil).Str("a", "b").Floats64("c", []float64{1.5, -2}).Value()).
+		Msg("hello msgpack")
+	logger.Info().Msg("second entry")
+
+	data := buf.Bytes()
+
+	var entries []map[string]interface{}
+	for len(data) > 0 {
+		b, n, err := MsgpackToJSON(nil, data)
+		if err != nil {
+			t.Fatalf("msgpack decode error: %+v", err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatalf("msgpack decode invalid json %s: %+v", b, err)
+		}
+		entries = append(entries, m)
+		data = data[n:]
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("msgpack entries mismatch: %+v", entries)
+	}
+
+	m := entries[0]
+	for key, value := range map[string]interface{}{
+		"level":   "info",
+		"foo":     "bar",
+		"quote":   "a \"b\"\n",
+		"long":    long,
+		"n":       float64(42),
+		"neg":     float64(-1),
+		"big":     float64(-1234567890123),
+		"huge":    float64(18446744073709551615),
+		"pi":      3.14,
+		"inf":     "-Inf",
+		"t":       true,
+		"f":       false,
+		"error":   nil,
+		"empty":   []interface{}{},
+		"tags":    []interface{}{"a", "b"},
+		"dict":    map[string]interface{}{"a": "b", "c": []interface{}{1.5, float64(-2)}},
+		"message": "hello msgpack",
+	} {
+		if !reflect.DeepEqual(m[key], value) {
+			t.Errorf("msgpack field %s mismatch: %#v != %#v", key, m[key], value)
+		}
+	}
+
+	nan := append([]byte{0xcb}, appendUint64(nil, math.Float64bits(math.NaN()))...)
+	if b, _, err := MsgpackToJSON(nil, nan); err != nil || string(b) != `"NaN"` {
+		t.Errorf("msgpack should decode NaN as string: %s, %+v", b, err)
+	}
+
+	p := []byte(`{"level":"info","message":"hello"}`)
+	if n, err := (&MsgpackWriter{Writer: &buf}).Write(p); err != nil || n != len(p) {
+		t.Errorf("msgpack writer should return the length of input: %d, %+v", n, err)
+	}
+
+	if _, _, err := MsgpackToJSON(nil, []byte{0x81, 0x01, 0x01}); err != ErrMsgpackInvalid {
+		t.Errorf("msgpack should reject non-string keys: %+v", err)
+	}
+	if _, _, err := MsgpackToJSON(nil, []byte{0xa5, 'a'}); err == nil {
+		t.Errorf("msgpack should reject truncated data")
+	}
+}
@@ -4,7 +4,19 @@ import (
 	"io"
 )
 
-// MultiWriter is an Writer that log to different writers by different levels
+// MultiWriter is an Writer that log to different writers by different levels.
+//
+// Each writer encodes the entries in its own format from a single log call, because entries
+// carry the complete structured fields as json which every writer decodes by itself, e.g.
+//
+//	log.DefaultLogger.Writer = &log.MultiWriter{
+//		InfoWriter: &log.FileWriter{Filename: "main.log"},
+//		Sinks: []log.MultiSink{
+//			{Writer: &log.ConsoleWriter{ColorOutput: true}},
+//			{Level: log.WarnLevel, Writer: &log.SyslogWriter{Network: "udp", Address: "localhost:514"}},
+//			{Level: log.ErrorLevel, Writer: &log.LogfmtWriter{Writer: &log.FileWriter{Filename: "error.logfmt"}}},
+//		},
+//	}
 type MultiWriter struct {
 	// InfoWriter specifies all the level logs writes to
 	InfoWriter Writer
@@ -20,16 +32,32 @@ type MultiWriter struct {
 
 	// ConsoleLevel specifies the level greater than or equal to it also writes to
 	ConsoleLevel Level
+
+	// Sinks specifies the additional writers with their own levels.
+	Sinks []MultiSink
+}
+
+// MultiSink is a writer of MultiWriter with its own level.
+type MultiSink struct {
+	// Level specifies the level greater than or equal to it writes to Writer.
+	Level Level
+
+	// Writer specifies the writer of sink, which encodes entries in its own format.
+	Writer Writer
 }
 
 // Close implements io.Closer, and closes the underlying LeveledWriter.
 func (w *MultiWriter) Close() (err error) {
-	for _, writer := range []Writer{
+	writers := []Writer{
 		w.InfoWriter,
 		w.WarnWriter,
 		w.ErrorWriter,
 		w.ConsoleWriter,
-	} {
+	}
+	for _, sink := range w.Sinks {
+		writers = append(writers, sink.Writer)
+	}
+	for _, writer := range writers {
 		if writer == nil {
 			continue
 		}
@@ -75,6 +103,14 @@ func (w *MultiWriter) WriteEntry(e *Entry) (n int, err error) {
 		w.ConsoleWriter.WriteEntry(e)
 	}
 
+	for _, sink := range w.Sinks {
+		if sink.Writer != nil && e.Level >= sink.Level {
+			if _, err1 = sink.Writer.WriteEntry(e); err1 != nil && err == nil {
+				err = err1
+			}
+		}
+	}
+
 	return
 }
 
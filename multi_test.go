@@ -1,10 +1,12 @@
 package log
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -109,3 +111,34 @@ func TestMultiWriterEntry(t *testing.T) {
 		t.Errorf("test close mutli writer error: %+v", err)
 	}
 }
+
+func TestMultiWriterSinks(t *testing.T) {
+	var jsonBuf, textBuf, logfmtBuf bytes.Buffer
+
+	w := &MultiWriter{
+		InfoWriter: IOWriter{&jsonBuf},
+		Sinks: []MultiSink{
+			{Writer: &ConsoleWriter{Writer: &textBuf}},
+			{Level: WarnLevel, Writer: &LogfmtWriter{Writer: &logfmtBuf}},
+			{Level: ErrorLevel},
+		},
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Str("foo", "bar").Msg("hello sinks")
+	logger.Warn().Int("n", 42).Msg("hello warn")
+
+	if s := jsonBuf.String(); !strings.Contains(s, `"level":"info","foo":"bar","message":"hello sinks"}`) || !strings.Contains(s, `"level":"warn","n":42,"message":"hello warn"}`) {
+		t.Errorf("multi writer json sink mismatch: %s", s)
+	}
+	if s := textBuf.String(); !strings.Contains(s, "INF") || !strings.Contains(s, "hello sinks") || !strings.Contains(s, "foo=bar") || !strings.Contains(s, "WRN") {
+		t.Errorf("multi writer console sink mismatch: %s", s)
+	}
+	if s := logfmtBuf.String(); strings.Contains(s, "hello sinks") || !strings.Contains(s, `level=warn n=42 message="hello warn"`) {
+		t.Errorf("multi writer logfmt sink mismatch: %s", s)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("multi writer close error: %+v", err)
+	}
+}
@@ -1,17 +1,60 @@
 package log
 
 import (
-	"fmt"
 	"io"
+	"path"
+	"strings"
+	"sync"
 )
 
 // MultiWriter is an Writer that log to different writers by different levels
 type MultiFileWriter struct {
+	// Writes is the named writers, use Register and Unregister to change it at runtime.
 	Writes map[string]Writer
+
+	// Levels is the minimum levels of the named writers, the entries below it are
+	// skipped for that writer only, e.g. {"heartbeat": InfoLevel}.
+	Levels map[string]Level
+
+	mu sync.RWMutex
+}
+
+// SetLevel sets the minimum level of the named writer at runtime.
+func (w *MultiFileWriter) SetLevel(name string, level Level) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.Levels == nil {
+		w.Levels = make(map[string]Level)
+	}
+	w.Levels[name] = level
+}
+
+// Register adds or replaces the named writer at runtime, and returns the replaced one,
+// which is not closed.
+func (w *MultiFileWriter) Register(name string, writer Writer) (old Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.Writes == nil {
+		w.Writes = make(map[string]Writer)
+	}
+	old = w.Writes[name]
+	w.Writes[name] = writer
+	return
+}
+
+// Unregister removes the named writer at runtime, and returns it, which is not closed.
+func (w *MultiFileWriter) Unregister(name string) (old Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	old = w.Writes[name]
+	delete(w.Writes, name)
+	return
 }
 
 // Close implements io.Closer, and closes the underlying LeveledWriter.
 func (w *MultiFileWriter) Close() (err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
 	if w.Writes == nil {
 		return nil
 	}
@@ -28,45 +71,74 @@ func (w *MultiFileWriter) Close() (err error) {
 	return
 }
 
-// WriteEntry implements entryWriter.
+// WriteEntry implements entryWriter. The entry is written once to each writer named by
+// Entry.To or Logger.Named, or to the "default" writer if none is found. An entry below
+// the level of a found writer is skipped, and not written to the "default" writer.
+// It returns the sum of bytes written and the first error.
+//
+// The keys of Writes can be glob patterns, e.g. "audit.*" routes the entries of loggers
+// named "audit.login" and "audit.payment". An exact key takes precedence over patterns,
+// and the pattern with the longest literal prefix wins among them, ties are broken by
+// the lexical order of patterns.
 func (w *MultiFileWriter) WriteEntry(e *Entry) (n int, err error) {
+	var n1 int
 	var err1 error
+	w.mu.RLock()
+	defer w.mu.RUnlock()
 	loggerFiles := e.loggerFiles
 	if w.Writes == nil || len(w.Writes) < 1 {
 		return
 	}
-	find := false
-	if loggerFiles != nil && len(loggerFiles) > 0 {
-		for _, loggerFileName := range loggerFiles {
-			if writer, ok := w.Writes[loggerFileName]; ok {
-				find = true
-				n, err1 = writer.WriteEntry(e)
-				if err1 != nil && err == nil {
-					err = err1
-				}
-			}
+	var tmp [8]string
+	keys := tmp[:0]
+	for _, loggerFileName := range loggerFiles {
+		key, writer := w.lookup(loggerFileName)
+		if writer == nil || duplicated(keys, key) {
+			continue
+		}
+		keys = append(keys, key)
+		if e.Level < w.Levels[key] {
+			continue
+		}
+		n1, err1 = writer.WriteEntry(e)
+		n += n1
+		if err1 != nil && err == nil {
+			err = err1
 		}
 	}
-	if !find {
-		if writer, ok := w.Writes["default"]; ok {
-			find = true
-			n, err1 = writer.WriteEntry(e)
-			if err1 != nil && err == nil {
-				err = err1
-			}
+	if len(keys) == 0 {
+		if writer, ok := w.Writes["default"]; ok && e.Level >= w.Levels["default"] {
+			n, err = writer.WriteEntry(e)
+		}
+	}
+	return
+}
+
+// lookup returns the key and writer of name, exact > longest prefix pattern.
+func (w *MultiFileWriter) lookup(name string) (key string, writer Writer) {
+	if writer, ok := w.Writes[name]; ok {
+		return name, writer
+	}
+	prefix := -1
+	for pattern, pw := range w.Writes {
+		i := strings.IndexAny(pattern, "*?[\\")
+		if i < 0 || i < prefix || (i == prefix && pattern > key) {
+			continue
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			key, writer, prefix = pattern, pw, i
 		}
 	}
 	return
 }
 
-// wlprintf is a helper function for tests
-func loggerPrintf(w Writer, loggerName string, level Level, format string, args ...interface{}) (int, error) {
-	entry := &Entry{
-		Level: level,
-		buf:   []byte(fmt.Sprintf(format, args...)),
+func duplicated(names []string, name string) bool {
+	for _, s := range names {
+		if s == name {
+			return true
+		}
 	}
-	entry.LoggerFile(loggerName)
-	return w.WriteEntry(entry)
+	return false
 }
 
 var _ Writer = (*MultiWriter)(nil)
@@ -1,6 +1,10 @@
 package log
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -31,3 +35,178 @@ func TestMultiFileWriter(t *testing.T) {
 		t.Errorf("test close mutli writer error: %+v", err)
 	}
 }
+
+func TestMultiFileWriterNamed(t *testing.T) {
+	var heartbeat, other bytes.Buffer
+
+	logger := Logger{
+		Writer: &MultiFileWriter{
+			Writes: map[string]Writer{
+				"heartbeat": IOWriter{&heartbeat},
+				"default":   IOWriter{&other},
+			},
+		},
+	}
+
+	logger.Named("heartbeat").Info().Msg("hello heartbeat")
+	logger.Info().Msg("hello default")
+
+	if s := heartbeat.String(); !strings.Contains(s, "hello heartbeat") || strings.Contains(s, "hello default") {
+		t.Errorf("heartbeat logger output mismatch: %s", s)
+	}
+	if s := other.String(); !strings.Contains(s, "hello default") || strings.Contains(s, "hello heartbeat") {
+		t.Errorf("default logger output mismatch: %s", s)
+	}
+}
+
+// loggerPrintf is a helper function for tests
+func loggerPrintf(w Writer, loggerName string, level Level, format string, args ...interface{}) (int, error) {
+	entry := &Entry{
+		Level: level,
+		buf:   []byte(fmt.Sprintf(format, args...)),
+	}
+	entry.LoggerFile(loggerName)
+	return w.WriteEntry(entry)
+}
+
+func TestMultiFileWriterTo(t *testing.T) {
+	var heartbeat, audit, other bytes.Buffer
+
+	logger := Logger{
+		Writer: &MultiFileWriter{
+			Writes: map[string]Writer{
+				"heartbeat": IOWriter{&heartbeat},
+				"audit":     IOWriter{&audit},
+				"default":   IOWriter{&other},
+			},
+		},
+	}
+
+	e := logger.Info().To("heartbeat", "audit", "heartbeat", "unknown")
+	if names := e.LoggerFiles(); len(names) != 4 {
+		t.Errorf("entry logger files mismatch: %v", names)
+	}
+	e.Msg("hello both")
+
+	logger.Named("audit").Info().To("audit").Msg("hello audit")
+	logger.Info().To("unknown").Msg("hello default")
+
+	if s := heartbeat.String(); strings.Count(s, "hello both") != 1 || strings.Contains(s, "hello audit") {
+		t.Errorf("heartbeat output mismatch: %s", s)
+	}
+	if s := audit.String(); strings.Count(s, "hello both") != 1 || strings.Count(s, "hello audit") != 1 {
+		t.Errorf("audit output mismatch: %s", s)
+	}
+	if s := other.String(); !strings.Contains(s, "hello default") || strings.Contains(s, "hello both") {
+		t.Errorf("default output mismatch: %s", s)
+	}
+
+	entry := &Entry{buf: []byte("hello\n")}
+	if n, _ := logger.Writer.WriteEntry(entry.To("heartbeat", "audit")); n != 12 {
+		t.Errorf("multi file writer should return the sum of bytes written: %d", n)
+	}
+}
+
+func TestMultiFileWriterRegister(t *testing.T) {
+	var tenant1, tenant2 bytes.Buffer
+
+	w := &MultiFileWriter{}
+	logger := Logger{Writer: w}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info().To("tenant3").Msg("hello race")
+			}
+		}()
+	}
+
+	if old := w.Register("tenant1", IOWriter{&tenant1}); old != nil {
+		t.Errorf("register should return nil writer: %+v", old)
+	}
+	logger.Info().To("tenant1").Msg("hello tenant1")
+
+	if old := w.Register("tenant1", IOWriter{&tenant2}); old == nil {
+		t.Errorf("register should return the replaced writer")
+	}
+	logger.Info().To("tenant1").Msg("hello tenant2")
+
+	if old := w.Unregister("tenant1"); old == nil {
+		t.Errorf("unregister should return the removed writer")
+	}
+	logger.Info().To("tenant1").Msg("hello removed")
+	wg.Wait()
+
+	if s := tenant1.String(); !strings.Contains(s, "hello tenant1") || strings.Contains(s, "hello tenant2") {
+		t.Errorf("tenant1 output mismatch: %s", s)
+	}
+	if s := tenant2.String(); !strings.Contains(s, "hello tenant2") || strings.Contains(s, "hello removed") {
+		t.Errorf("tenant2 output mismatch: %s", s)
+	}
+}
+
+func TestMultiFileWriterLevels(t *testing.T) {
+	var heartbeat, other bytes.Buffer
+
+	w := &MultiFileWriter{
+		Writes: map[string]Writer{
+			"heartbeat": IOWriter{&heartbeat},
+			"default":   IOWriter{&other},
+		},
+		Levels: map[string]Level{
+			"heartbeat": InfoLevel,
+		},
+	}
+	logger := Logger{Level: TraceLevel, Writer: w}
+
+	logger.Named("heartbeat").Debug().Msg("hello heartbeat debug")
+	logger.Named("heartbeat").Info().Msg("hello heartbeat info")
+	logger.Debug().Msg("hello default debug")
+
+	w.SetLevel("default", WarnLevel)
+	logger.Info().Msg("hello default info")
+
+	if s := heartbeat.String(); strings.Contains(s, "hello heartbeat debug") || !strings.Contains(s, "hello heartbeat info") {
+		t.Errorf("heartbeat output mismatch: %s", s)
+	}
+	if s := other.String(); !strings.Contains(s, "hello default debug") || strings.Contains(s, "hello heartbeat") || strings.Contains(s, "hello default info") {
+		t.Errorf("default output mismatch: %s", s)
+	}
+}
+
+func TestMultiFileWriterPattern(t *testing.T) {
+	var audit, payment, exact, other bytes.Buffer
+
+	logger := Logger{
+		Writer: &MultiFileWriter{
+			Writes: map[string]Writer{
+				"audit.*":         IOWriter{&audit},
+				"audit.payment.*": IOWriter{&payment},
+				"audit.special":   IOWriter{&exact},
+				"default":         IOWriter{&other},
+			},
+		},
+	}
+
+	logger.Named("audit").Named("login").Info().Msg("hello login")
+	logger.Named("audit").Named("payment").Named("card").Info().Msg("hello card")
+	logger.Named("audit").Named("special").Info().Msg("hello special")
+	logger.Named("auditor").Info().Msg("hello auditor")
+	logger.Info().To("audit.login", "audit.logout").Msg("hello once")
+
+	if s := audit.String(); !strings.Contains(s, "hello login") || strings.Contains(s, "hello card") || strings.Contains(s, "hello special") || strings.Count(s, "hello once") != 1 {
+		t.Errorf("audit output mismatch: %s", s)
+	}
+	if s := payment.String(); !strings.Contains(s, "hello card") || strings.Contains(s, "hello login") {
+		t.Errorf("payment output mismatch: %s", s)
+	}
+	if s := exact.String(); !strings.Contains(s, "hello special") || strings.Contains(s, "hello login") {
+		t.Errorf("special output mismatch: %s", s)
+	}
+	if s := other.String(); !strings.Contains(s, "hello auditor") || strings.Contains(s, "hello login") {
+		t.Errorf("default output mismatch: %s", s)
+	}
+}
@@ -0,0 +1,234 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSWriter is an Writer that publishes entries to a NATS subject, e.g. "logs.{level}",
+// where {name} is replaced with the value of field name of entry.
+type NATSWriter struct {
+	// Address specifies address of the NATS server, e.g. 127.0.0.1:4222
+	Address string
+
+	// Subject specifies the subject template, e.g. "logs.{level}.{service}". A missing
+	// field is replaced with "_", and the wildcard and separator chars of values with '_'.
+	Subject string
+
+	// Name specifies the client name.
+	Name string
+
+	// User, Password and Token specify the credentials of connecting.
+	User     string
+	Password string
+	Token    string
+
+	// Dial specifies the dial function for creating TCP/TLS connections.
+	Dial func(network, addr string) (net.Conn, error)
+
+	// DialTimeout specifies the timeout of connecting and handshaking, the default is 10s.
+	DialTimeout time.Duration
+
+	// WriteTimeout specifies the timeout of publishing a log, the default is no timeout.
+	WriteTimeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	err  error
+}
+
+// Healthy implements HealthChecker, returns the error of last connecting or publishing.
+func (w *NATSWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close closes the connection to the NATS server.
+func (w *NATSWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	return
+}
+
+// connect makes a connection to the NATS server, and answers the pings of server in background.
+func (w *NATSWriter) connect() (err error) {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+
+	timeout := w.DialTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	var dial = w.Dial
+	if dial == nil {
+		dial = func(network, addr string) (net.Conn, error) {
+			return net.DialTimeout(network, addr, timeout)
+		}
+	}
+
+	conn, err := dial("tcp", w.Address)
+	if err != nil {
+		return
+	}
+
+	conn.SetDeadline(timeNow().Add(timeout))
+	reader := bufio.NewReader(conn)
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		if err == nil {
+			err = errors.New("log: invalid nats info: " + strings.TrimSpace(line))
+		}
+		return err
+	}
+
+	e := &Entry{}
+	e.buf = append(e.buf, `CONNECT {"verbose":false,"pedantic":false,"lang":"go","name":"`...)
+	e.string(w.Name)
+	if w.User != "" {
+		e.buf = append(e.buf, `","user":"`...)
+		e.string(w.User)
+		e.buf = append(e.buf, `","pass":"`...)
+		e.string(w.Password)
+	}
+	if w.Token != "" {
+		e.buf = append(e.buf, `","auth_token":"`...)
+		e.string(w.Token)
+	}
+	e.buf = append(e.buf, "\"}\r\nPING\r\n"...)
+	if _, err = conn.Write(e.buf); err != nil {
+		conn.Close()
+		return
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		if strings.HasPrefix(line, "PONG") {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			conn.Close()
+			return errors.New("log: nats " + strings.TrimSpace(line))
+		}
+	}
+	conn.SetDeadline(time.Time{})
+
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(line, "PING") {
+				conn.Write([]byte("PONG\r\n"))
+			}
+		}
+	}()
+
+	w.conn = conn
+	return
+}
+
+// WriteEntry implements Writer, publishes the entry to the subject.
+func (w *NATSWriter) WriteEntry(e *Entry) (n int, err error) {
+	payload := e.buf
+	if len(payload) != 0 && payload[len(payload)-1] == '\n' {
+		payload = payload[:len(payload)-1]
+	}
+
+	b := bbget()
+	defer bbput(b)
+
+	b.B = append(b.B, "PUB "...)
+	b.B = appendTopic(b.B, w.Subject, e, " \t\r\n.*>")
+	b.B = append(b.B, ' ')
+	b.B = strconv.AppendInt(b.B, int64(len(payload)), 10)
+	b.B = append(b.B, '\r', '\n')
+	b.B = append(b.B, payload...)
+	b.B = append(b.B, '\r', '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if n, err = w.write(b.B); err == nil {
+			return
+		}
+	}
+	if err = w.connect(); err != nil {
+		w.err = err
+		return
+	}
+	n, err = w.write(b.B)
+	w.err = err
+	return
+}
+
+func (w *NATSWriter) write(b []byte) (int, error) {
+	if w.WriteTimeout > 0 {
+		w.conn.SetWriteDeadline(timeNow().Add(w.WriteTimeout))
+	}
+	return w.conn.Write(b)
+}
+
+// appendTopic appends the topic template to dst, the {name} placeholders are replaced with
+// the values of fields of entry, and the chars of values in invalid with '_'.
+func appendTopic(dst []byte, template string, e *Entry, invalid string) []byte {
+	for template != "" {
+		i := strings.IndexByte(template, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(template[i+1:], '}')
+		if j < 0 {
+			break
+		}
+		dst = append(dst, template[:i]...)
+		name := template[i+1 : i+1+j]
+		template = template[i+j+2:]
+
+		var value []byte
+		jsonObjectEach(e.buf, func(k, v []byte, typ byte) {
+			if value == nil && string(k) == name {
+				switch typ {
+				case 's':
+					value = v[1 : len(v)-1]
+				case 'S':
+					value = jsonUnescape(v[1:len(v)-1], nil)
+				default:
+					value = v
+				}
+			}
+		})
+		if len(value) == 0 {
+			dst = append(dst, '_')
+			continue
+		}
+		for _, c := range value {
+			if strings.IndexByte(invalid, c) >= 0 {
+				c = '_'
+			}
+			dst = append(dst, c)
+		}
+	}
+	return append(dst, template...)
+}
+
+var _ Writer = (*NATSWriter)(nil)
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestNATSWriter(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %+v", err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			if line == "PING\r\n" {
+				conn.Write([]byte("PONG\r\n"))
+				continue
+			}
+			lines <- line
+		}
+	}()
+
+	w := &NATSWriter{
+		Address: ln.Addr().String(),
+		Subject: "logs.{level}.{service}",
+		Name:    "test",
+		Token:   "secret",
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Str("service", "a.b").Msg("hello nats")
+	logger.Warn().Msg("hello missing")
+	w.Close()
+
+	var got []string
+	for line := range lines {
+		got = append(got, strings.TrimSpace(line))
+	}
+
+	if len(got) != 5 || !strings.HasPrefix(got[0], `CONNECT {"verbose":false,"pedantic":false,"lang":"go","name":"test","auth_token":"secret"}`) {
+		t.Fatalf("nats writer lines mismatch: %q", got)
+	}
+	if !strings.HasPrefix(got[1], "PUB logs.info.a_b ") || !strings.HasSuffix(got[2], `"service":"a.b","message":"hello nats"}`) {
+		t.Errorf("nats writer publish mismatch: %q", got[1:3])
+	}
+	if !strings.HasPrefix(got[3], "PUB logs.warn._ ") {
+		t.Errorf("nats writer missing field mismatch: %q", got[3])
+	}
+}
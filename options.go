@@ -0,0 +1,99 @@
+package log
+
+import (
+	"os"
+	"time"
+)
+
+// Option configures a Logger created by New.
+type Option func(*Logger)
+
+// New returns a Logger configured by options, the level defaults to InfoLevel and
+// the writer defaults to os.Stderr. The struct literal of Logger is still supported.
+func New(opts ...Option) *Logger {
+	l := &Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{os.Stderr},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// WithLevel sets the level of logger.
+func WithLevel(level Level) Option {
+	return func(l *Logger) {
+		l.Level = level
+	}
+}
+
+// WithWriter sets the writer of logger.
+func WithWriter(w Writer) Option {
+	return func(l *Logger) {
+		l.Writer = w
+	}
+}
+
+// WithCaller sets the caller depth of logger, 0 to disable.
+func WithCaller(depth int) Option {
+	return func(l *Logger) {
+		l.Caller = depth
+	}
+}
+
+// WithTimeField sets the time field name of logger.
+func WithTimeField(field string) Option {
+	return func(l *Logger) {
+		l.TimeField = field
+	}
+}
+
+// WithTimeFormat sets the time format of logger, e.g. time.RFC3339 or TimeFormatUnix.
+func WithTimeFormat(format string) Option {
+	return func(l *Logger) {
+		l.TimeFormat = format
+	}
+}
+
+// WithName sets the name of logger.
+func WithName(name string) Option {
+	return func(l *Logger) {
+		l.Name = name
+	}
+}
+
+// WithFields appends the key/value pairs to the context of logger, see Entry.KeysAndValues.
+func WithFields(keysAndValues ...interface{}) Option {
+	return func(l *Logger) {
+		l.Context = NewContext(append(Context(nil), l.Context...)).KeysAndValues(keysAndValues...).Value()
+	}
+}
+
+// WithHooks appends the hooks to logger.
+func WithHooks(hooks ...Hook) Option {
+	return func(l *Logger) {
+		l.Hooks = append(l.Hooks, hooks...)
+	}
+}
+
+// WithFieldProviders appends the field providers to logger, see Logger.FieldProviders.
+func WithFieldProviders(providers ...FieldProvider) Option {
+	return func(l *Logger) {
+		l.FieldProviders = append(l.FieldProviders, providers...)
+	}
+}
+
+// WithCoarseClock makes logger use the cached wall time of coarse clock, see Logger.CoarseClock.
+func WithCoarseClock() Option {
+	return func(l *Logger) {
+		l.CoarseClock = true
+	}
+}
+
+// WithTimeLocation sets the location of times in output, see Logger.TimeLocation.
+func WithTimeLocation(loc *time.Location) Option {
+	return func(l *Logger) {
+		l.TimeLocation = loc
+	}
+}
@@ -0,0 +1,33 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := New(
+		WithLevel(WarnLevel),
+		WithWriter(IOWriter{&buf}),
+		WithTimeField("ts"),
+		WithTimeFormat(TimeFormatUnix),
+		WithName("options"),
+		WithFields("foo", "bar"),
+		WithFields("n", 42),
+		WithFieldProviders(func(e *Entry) { e.Str("tenant", "acme") }),
+	)
+
+	logger.Info().Msg("hello info")
+	logger.Warn().Msg("hello options")
+
+	if s := buf.String(); !bytes.Contains(buf.Bytes(), []byte(`"level":"warn","foo":"bar","n":42,"tenant":"acme","message":"hello options"}`)) ||
+		!bytes.HasPrefix(buf.Bytes(), []byte(`{"ts":`)) {
+		t.Errorf("options logger output mismatch: %s", s)
+	}
+
+	if l := New(); l.Level != InfoLevel || l.Writer == nil {
+		t.Errorf("options logger defaults mismatch: %+v", l)
+	}
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"context"
+)
+
+// SpanContextFromContext returns the trace id, span id and trace flags of the active span in ctx.
+// It is nil by default to keep dependency free, to correlate with OpenTelemetry traces, set it as
+//
+//	log.SpanContextFromContext = func(ctx context.Context) ([16]byte, [8]byte, byte) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		return sc.TraceID(), sc.SpanID(), byte(sc.TraceFlags())
+//	}
+var SpanContextFromContext func(ctx context.Context) (traceID [16]byte, spanID [8]byte, traceFlags byte)
+
+// Ctx adds the "trace_id", "span_id" and "trace_flags" fields of the active span in ctx to the entry.
+// To extract them automatically, use it in Logger.ContextExtractor.
+func (e *Entry) Ctx(ctx context.Context) *Entry {
+	if e == nil || ctx == nil || SpanContextFromContext == nil {
+		return e
+	}
+	traceID, spanID, traceFlags := SpanContextFromContext(ctx)
+	if traceID == [16]byte{} || spanID == [8]byte{} {
+		return e
+	}
+	e.buf = append(e.buf, ",\"trace_id\":\""...)
+	for _, v := range traceID {
+		e.buf = append(e.buf, hex[v>>4], hex[v&0x0f])
+	}
+	e.buf = append(e.buf, "\",\"span_id\":\""...)
+	for _, v := range spanID {
+		e.buf = append(e.buf, hex[v>>4], hex[v&0x0f])
+	}
+	e.buf = append(e.buf, "\",\"trace_flags\":\""...)
+	e.buf = append(e.buf, hex[traceFlags>>4], hex[traceFlags&0x0f], '"')
+	return e
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type testSpanContextKey struct{}
+
+func TestEntryCtx(t *testing.T) {
+	SpanContextFromContext = func(ctx context.Context) (traceID [16]byte, spanID [8]byte, traceFlags byte) {
+		if ctx.Value(testSpanContextKey{}) == nil {
+			return
+		}
+		for i := range traceID {
+			traceID[i] = byte(i)
+		}
+		for i := range spanID {
+			spanID[i] = byte(0xf0 + i)
+		}
+		traceFlags = 1
+		return
+	}
+	defer func() { SpanContextFromContext = nil }()
+
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  InfoLevel,
+		Writer: IOWriter{&buf},
+	}
+
+	ctx := context.WithValue(context.Background(), testSpanContextKey{}, true)
+	logger.Info().Ctx(ctx).Msg("hello otel")
+
+	if s := buf.String(); !strings.Contains(s, `"trace_id":"000102030405060708090a0b0c0d0e0f","span_id":"f0f1f2f3f4f5f6f7","trace_flags":"01"`) {
+		t.Errorf("otel entry output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	logger.Info().Ctx(context.Background()).Msg("hello otel without span")
+	if s := buf.String(); strings.Contains(s, "trace_id") {
+		t.Errorf("otel entry should not contains trace_id: %s", s)
+	}
+
+	buf.Reset()
+	logger.ContextExtractor = func(ctx context.Context, e *Entry) { e.Ctx(ctx) }
+	FromContext(logger.WithContext(ctx)).Info().Msg("hello otel context logger")
+	if s := buf.String(); !strings.Contains(s, `"span_id":"f0f1f2f3f4f5f6f7"`) {
+		t.Errorf("otel context logger output mismatch: %s", s)
+	}
+}
@@ -0,0 +1,368 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// OTLPWriter is an Writer that converts entries to OpenTelemetry LogRecords and exports
+// them in batches to an OTLP/HTTP endpoint with JSON encoding. The "level" field is mapped
+// to severity, "message" to body, "trace_id" and "span_id" to the trace context and the
+// other fields to attributes.
+type OTLPWriter struct {
+	// Endpoint is the url of OTLP/HTTP logs endpoint, e.g. http://localhost:4318/v1/logs
+	Endpoint string
+
+	// Headers specifies the extra http headers of export requests, e.g. authorization.
+	Headers map[string]string
+
+	// Resource specifies the resource attributes, e.g. service.name
+	Resource map[string]string
+
+	// Scope specifies the instrumentation scope name.
+	Scope string
+
+	// BatchSize is the max number of records in a batch, the default size is 512.
+	BatchSize int
+
+	// FlushInterval is the interval of exporting incomplete batches, the default is 1s.
+	FlushInterval time.Duration
+
+	// Client specifies the http client of exporting, using http.DefaultClient if nil.
+	Client *http.Client
+
+	// Timeout specifies the timeout of exporting a batch if Client is nil, the default is 10s.
+	Timeout time.Duration
+
+	// Context specifies the base context of requests, the in-flight flushes are aborted when it
+	// is canceled. It uses context.Background() if nil.
+	Context context.Context
+
+	mu      sync.Mutex
+	once    sync.Once
+	closing sync.Once
+	records []byte
+	count   int
+	done    chan struct{}
+	closed  chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	err     error
+}
+
+// Healthy implements HealthChecker, returns the error of last exporting.
+func (w *OTLPWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close implements io.Closer, exports the pending records and stops the flushing.
+// The subsequent calls are no-ops.
+func (w *OTLPWriter) Close() (err error) {
+	w.init()
+	w.closing.Do(func() {
+		unregisterWriter(w)
+		close(w.done)
+		<-w.closed
+		err = w.Flush()
+		w.cancel()
+	})
+	return
+}
+
+// Shutdown is like Close, but if ctx is done before Close returns, it aborts the in-flight
+// requests and returns ctx.Err(), so it never blocks the shutdown beyond the deadline of ctx.
+func (w *OTLPWriter) Shutdown(ctx context.Context) error {
+	w.init()
+	return shutdown(ctx, w.Close, w.cancel)
+}
+
+// Flush exports the pending records.
+func (w *OTLPWriter) Flush() error {
+	w.init()
+	w.mu.Lock()
+	body := w.batch()
+	w.mu.Unlock()
+	if body == nil {
+		return nil
+	}
+	return w.export(body)
+}
+
+// WriteEntry implements Writer. The full batch is exported outside the lock, so the other
+// goroutines are not blocked by the export.
+func (w *OTLPWriter) WriteEntry(e *Entry) (n int, err error) {
+	w.init()
+
+	w.mu.Lock()
+	if w.count != 0 {
+		w.records = append(w.records, ',')
+	}
+	w.records = appendOTLPRecord(w.records, e)
+	w.count++
+
+	var body []byte
+	if batch := w.BatchSize; (batch > 0 && w.count >= batch) || (batch <= 0 && w.count >= 512) {
+		body = w.batch()
+	}
+	w.mu.Unlock()
+
+	n = len(e.buf)
+	if body != nil {
+		err = w.export(body)
+	}
+	return
+}
+
+func (w *OTLPWriter) init() {
+	w.once.Do(func() {
+		base := w.Context
+		if base == nil {
+			base = context.Background()
+		}
+		w.ctx, w.cancel = context.WithCancel(base)
+		w.done = make(chan struct{})
+		w.closed = make(chan struct{})
+		interval := w.FlushInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			defer close(w.closed)
+			for {
+				select {
+				case <-ticker.C:
+					w.Flush()
+				case <-w.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// batch returns the export request body of the pending records and resets them, or nil if
+// there is no pending record. It is called with w.mu held.
+func (w *OTLPWriter) batch() []byte {
+	if w.count == 0 {
+		return nil
+	}
+
+	e := &Entry{buf: make([]byte, 0, len(w.records)+256)}
+	e.buf = append(e.buf, `{"resourceLogs":[{"resource":{"attributes":[`...)
+	first := true
+	for key, value := range w.Resource {
+		if !first {
+			e.buf = append(e.buf, ',')
+		}
+		first = false
+		e.buf = append(e.buf, `{"key":"`...)
+		e.string(key)
+		e.buf = append(e.buf, `","value":{"stringValue":"`...)
+		e.string(value)
+		e.buf = append(e.buf, `"}}`...)
+	}
+	e.buf = append(e.buf, `]},"scopeLogs":[{"scope":{"name":"`...)
+	e.string(w.Scope)
+	e.buf = append(e.buf, `"},"logRecords":[`...)
+	e.buf = append(e.buf, w.records...)
+	e.buf = append(e.buf, "]}]}]}"...)
+
+	w.records = w.records[:0]
+	w.count = 0
+
+	return e.buf
+}
+
+// export posts the request body to Endpoint, and records the error for Healthy.
+func (w *OTLPWriter) export(body []byte) (err error) {
+	defer func() {
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, w.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(w.ctx)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := w.Client
+	if client == nil {
+		timeout := w.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return errors.New("log: otlp export failed with status " + resp.Status)
+	}
+	return nil
+}
+
+// appendOTLPRecord appends the entry to dst as an OTLP LogRecord in json.
+func appendOTLPRecord(dst []byte, e *Entry) []byte {
+	now := timeNow()
+
+	dst = append(dst, `{"observedTimeUnixNano":"`...)
+	dst = strconv.AppendInt(dst, now.UnixNano(), 10)
+	dst = append(dst, '"')
+	if severity := otlpSeverity(e.Level); severity != 0 {
+		dst = append(dst, `,"severityNumber":`...)
+		dst = strconv.AppendInt(dst, int64(severity), 10)
+		dst = append(dst, `,"severityText":"`...)
+		for _, c := range []byte(e.Level.String()) {
+			dst = append(dst, c-('a'-'A'))
+		}
+		dst = append(dst, '"')
+	}
+
+	attrs := bbget()
+	defer bbput(attrs)
+
+	first := true
+	jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if first {
+			first = false
+			if t, ok := jsonParseTime(value, typ); ok {
+				dst = append(dst, `,"timeUnixNano":"`...)
+				dst = strconv.AppendInt(dst, t.UnixNano(), 10)
+				dst = append(dst, '"')
+				return
+			}
+		}
+		switch b2s(key) {
+		case "level":
+			return
+		case "message":
+			dst = append(dst, `,"body":`...)
+			dst = appendOTLPValue(dst, value, typ)
+			return
+		case "trace_id":
+			if typ == 's' {
+				dst = append(dst, `,"traceId":`...)
+				dst = append(dst, value...)
+				return
+			}
+		case "span_id":
+			if typ == 's' {
+				dst = append(dst, `,"spanId":`...)
+				dst = append(dst, value...)
+				return
+			}
+		}
+		if len(attrs.B) != 0 {
+			attrs.B = append(attrs.B, ',')
+		}
+		attrs.B = append(attrs.B, `{"key":"`...)
+		attrs.B = append(attrs.B, key...)
+		attrs.B = append(attrs.B, `","value":`...)
+		attrs.B = appendOTLPValue(attrs.B, value, typ)
+		attrs.B = append(attrs.B, '}')
+	})
+	if len(attrs.B) != 0 {
+		dst = append(dst, `,"attributes":[`...)
+		dst = append(dst, attrs.B...)
+		dst = append(dst, ']')
+	}
+
+	return append(dst, '}')
+}
+
+// appendOTLPValue appends the json value with typ to dst as an OTLP AnyValue.
+func appendOTLPValue(dst, value []byte, typ byte) []byte {
+	switch typ {
+	case 's', 'S':
+		dst = append(dst, `{"stringValue":`...)
+		dst = append(dst, value...)
+	case 'n':
+		if bytes.IndexAny(value, ".eE") < 0 {
+			dst = append(dst, `{"intValue":"`...)
+			dst = append(dst, value...)
+			dst = append(dst, '"')
+		} else {
+			dst = append(dst, `{"doubleValue":`...)
+			dst = append(dst, value...)
+		}
+	case 't', 'f':
+		dst = append(dst, `{"boolValue":`...)
+		dst = append(dst, value...)
+	case 'o':
+		if value[0] == '[' {
+			dst = append(dst, `{"arrayValue":{"values":[`...)
+			first := true
+			jsonArrayEach(value, func(value []byte, typ byte) {
+				if !first {
+					dst = append(dst, ',')
+				}
+				first = false
+				dst = appendOTLPValue(dst, value, typ)
+			})
+		} else {
+			dst = append(dst, `{"kvlistValue":{"values":[`...)
+			first := true
+			jsonObjectEach(value, func(key, value []byte, typ byte) {
+				if !first {
+					dst = append(dst, ',')
+				}
+				first = false
+				dst = append(dst, `{"key":"`...)
+				dst = append(dst, key...)
+				dst = append(dst, `","value":`...)
+				dst = appendOTLPValue(dst, value, typ)
+				dst = append(dst, '}')
+			})
+		}
+		dst = append(dst, "]}"...)
+	default:
+		return append(dst, "{}"...)
+	}
+	return append(dst, '}')
+}
+
+// otlpSeverity maps level to OpenTelemetry SeverityNumber.
+func otlpSeverity(level Level) int {
+	switch level {
+	case TraceLevel:
+		return 1
+	case DebugLevel:
+		return 5
+	case InfoLevel:
+		return 9
+	case WarnLevel:
+		return 13
+	case ErrorLevel:
+		return 17
+	case FatalLevel:
+		return 21
+	case PanicLevel:
+		return 24
+	}
+	return 0
+}
+
+var _ Writer = (*OTLPWriter)(nil)
@@ -0,0 +1,249 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOTLPWriter(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/logs" || req.Header.Get("Content-Type") != "application/json" || req.Header.Get("X-Token") != "secret" {
+			t.Errorf("otlp writer request mismatch: %s %+v", req.URL, req.Header)
+		}
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies <- b
+	}))
+	defer server.Close()
+
+	w := &OTLPWriter{
+		Endpoint:      server.URL + "/v1/logs",
+		Headers:       map[string]string{"X-Token": "secret"},
+		Resource:      map[string]string{"service.name": "test"},
+		Scope:         "otlp_test",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Str("trace_id", "0af7651916cd43dd8448eb211c80319c").Int("n", 42).Float64("f", 1.5).Bool("b", true).Strs("a", []string{"x"}).Dict("d", NewContext(nil).Str("k", "v").Value()).Msg("hello otlp")
+	logger.Error().Str("foo", "a\"b").Msg("second")
+	logger.Warn().Msg("third")
+
+	var batch struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []struct {
+					Key   string
+					Value map[string]interface{}
+				}
+			}
+			ScopeLogs []struct {
+				Scope      struct{ Name string }
+				LogRecords []struct {
+					TimeUnixNano   string
+					SeverityNumber int
+					SeverityText   string
+					TraceID        string `json:"traceId"`
+					Body           map[string]interface{}
+					Attributes     []struct {
+						Key   string
+						Value map[string]interface{}
+					}
+				}
+			}
+		}
+	}
+
+	select {
+	case b := <-bodies:
+		if err := json.Unmarshal(b, &batch); err != nil {
+			t.Fatalf("otlp writer invalid json %s: %+v", b, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("otlp writer did not export the batch")
+	}
+
+	rl := batch.ResourceLogs[0]
+	if a := rl.Resource.Attributes; len(a) != 1 || a[0].Key != "service.name" || a[0].Value["stringValue"] != "test" {
+		t.Errorf("otlp writer resource mismatch: %+v", a)
+	}
+	if rl.ScopeLogs[0].Scope.Name != "otlp_test" {
+		t.Errorf("otlp writer scope mismatch: %+v", rl.ScopeLogs[0].Scope)
+	}
+
+	records := rl.ScopeLogs[0].LogRecords
+	if len(records) != 2 {
+		t.Fatalf("otlp writer records mismatch: %+v", records)
+	}
+
+	r := records[0]
+	if r.TimeUnixNano == "" || r.SeverityNumber != 9 || r.SeverityText != "INFO" || r.TraceID != "0af7651916cd43dd8448eb211c80319c" || r.Body["stringValue"] != "hello otlp" {
+		t.Errorf("otlp writer record mismatch: %+v", r)
+	}
+	attrs := map[string]map[string]interface{}{}
+	for _, a := range r.Attributes {
+		attrs[a.Key] = a.Value
+	}
+	if attrs["n"]["intValue"] != "42" || attrs["f"]["doubleValue"] != 1.5 || attrs["b"]["boolValue"] != true || attrs["a"]["arrayValue"] == nil || attrs["d"]["kvlistValue"] == nil {
+		t.Errorf("otlp writer attributes mismatch: %+v", attrs)
+	}
+	if r := records[1]; r.SeverityNumber != 17 || r.Attributes[0].Value["stringValue"] != "a\"b" {
+		t.Errorf("otlp writer record mismatch: %+v", r)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Errorf("otlp writer close error: %+v", err)
+	}
+	select {
+	case b := <-bodies:
+		if err := json.Unmarshal(b, &batch); err != nil || len(batch.ResourceLogs[0].ScopeLogs[0].LogRecords) != 1 {
+			t.Errorf("otlp writer close should flush pending records: %s", b)
+		}
+	default:
+		t.Errorf("otlp writer close should flush pending records")
+	}
+}
+
+func TestOTLPWriterHealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	w := &OTLPWriter{
+		Endpoint:  server.URL,
+		BatchSize: 1,
+		Timeout:   time.Second,
+	}
+	defer w.Close()
+
+	var checker HealthChecker = w
+	if checker.Healthy() != nil {
+		t.Errorf("otlp writer should be healthy before exporting")
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello otlp healthy")
+
+	if err := checker.Healthy(); err == nil {
+		t.Errorf("otlp writer should be unhealthy")
+	}
+}
+
+func TestOTLPWriterShutdown(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	w := &OTLPWriter{
+		Endpoint:      server.URL + "/v1/logs",
+		FlushInterval: time.Hour,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello otlp shutdown")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := w.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("otlp writer shutdown should return deadline exceeded: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("otlp writer shutdown should not block beyond the deadline: %v", d)
+	}
+
+	// the aborted flush releases the lock promptly
+	for i := 0; i < 100 && w.Healthy() == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := w.Healthy(); err == nil {
+		t.Errorf("otlp writer should record the aborted export error")
+	}
+}
+
+func TestOTLPWriterContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &OTLPWriter{
+		Endpoint:      server.URL + "/v1/logs",
+		FlushInterval: time.Hour,
+		Context:       ctx,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello otlp context")
+
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if err := w.Flush(); err == nil {
+		t.Errorf("otlp writer flush should be aborted by the canceled context")
+	}
+	w.Close()
+}
+
+func TestOTLPWriterExportUnlocked(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	w := &OTLPWriter{
+		Endpoint:      server.URL + "/v1/logs",
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+	}
+
+	logger := Logger{Writer: w}
+	exported := make(chan struct{})
+	go func() {
+		logger.Info().Msg("hello otlp export")
+		close(exported)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		w.Healthy()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("otlp writer should not hold the lock while exporting")
+	}
+
+	close(release)
+	<-exported
+	if err := w.Close(); err != nil {
+		t.Errorf("otlp writer close error: %+v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("otlp writer close twice error: %+v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// printfVerb is a literal followed by a verb of a parsed format, the verb of last one is 0.
+type printfVerb struct {
+	literal string
+	verb    byte
+}
+
+// printfFormat is a parsed format string, it is nil if the format is not supported by appendPrintf.
+type printfFormat []printfVerb
+
+// printfCacheSize is the max number of format strings cached by appendPrintf.
+const printfCacheSize = 1024
+
+var (
+	printfCache   atomic.Value // map[string]printfFormat
+	printfCacheMu sync.Mutex
+)
+
+// parsePrintfFormat parses the format which only contains %s, %d, %v and %% verbs.
+func parsePrintfFormat(format string) (f printfFormat, ok bool) {
+	var literal []byte
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			literal = append(literal, c)
+			continue
+		}
+		if i++; i == len(format) {
+			return nil, false
+		}
+		switch c = format[i]; c {
+		case '%':
+			literal = append(literal, '%')
+		case 's', 'd', 'v':
+			f = append(f, printfVerb{string(literal), c})
+			literal = literal[:0]
+		default:
+			return nil, false
+		}
+	}
+	f = append(f, printfVerb{string(literal), 0})
+	return f, true
+}
+
+// getPrintfFormat returns the cached parsed format, or parses and caches it.
+func getPrintfFormat(format string) printfFormat {
+	cache, _ := printfCache.Load().(map[string]printfFormat)
+	if f, ok := cache[format]; ok {
+		return f
+	}
+
+	f, _ := parsePrintfFormat(format)
+
+	printfCacheMu.Lock()
+	defer printfCacheMu.Unlock()
+	cache, _ = printfCache.Load().(map[string]printfFormat)
+	if len(cache) < printfCacheSize {
+		// copy on write, the readers do not take the lock.
+		m := make(map[string]printfFormat, len(cache)+1)
+		for k, v := range cache {
+			m[k] = v
+		}
+		m[format] = f
+		printfCache.Store(m)
+	}
+	return f
+}
+
+// appendPrintf appends the formatted args to dst in the manner of fmt.Sprintf. It avoids fmt for
+// the formats of %s, %d and %v verbs with the args of strings, bytes, integers, floats and bools,
+// and falls back to fmt.Fprintf for the others.
+func appendPrintf(dst []byte, format string, args []interface{}) []byte {
+	f := getPrintfFormat(format)
+	if f == nil || len(f)-1 != len(args) || !printfSupported(f, args) {
+		b := bb{B: dst}
+		fmt.Fprintf(&b, format, args...)
+		return b.B
+	}
+	for i, v := range f {
+		dst = append(dst, v.literal...)
+		if v.verb == 0 {
+			break
+		}
+		switch arg := args[i].(type) {
+		case string:
+			dst = append(dst, arg...)
+		case []byte:
+			dst = append(dst, arg...)
+		case bool:
+			dst = strconv.AppendBool(dst, arg)
+		case int:
+			dst = strconv.AppendInt(dst, int64(arg), 10)
+		case int8:
+			dst = strconv.AppendInt(dst, int64(arg), 10)
+		case int16:
+			dst = strconv.AppendInt(dst, int64(arg), 10)
+		case int32:
+			dst = strconv.AppendInt(dst, int64(arg), 10)
+		case int64:
+			dst = strconv.AppendInt(dst, arg, 10)
+		case uint:
+			dst = strconv.AppendUint(dst, uint64(arg), 10)
+		case uint8:
+			dst = strconv.AppendUint(dst, uint64(arg), 10)
+		case uint16:
+			dst = strconv.AppendUint(dst, uint64(arg), 10)
+		case uint32:
+			dst = strconv.AppendUint(dst, uint64(arg), 10)
+		case uint64:
+			dst = strconv.AppendUint(dst, arg, 10)
+		case uintptr:
+			dst = strconv.AppendUint(dst, uint64(arg), 10)
+		case float32:
+			dst = strconv.AppendFloat(dst, float64(arg), 'g', -1, 32)
+		case float64:
+			dst = strconv.AppendFloat(dst, arg, 'g', -1, 64)
+		}
+	}
+	return dst
+}
+
+// printfSupported reports whether every arg can be formatted by its verb as fmt does.
+func printfSupported(f printfFormat, args []interface{}) bool {
+	for i, arg := range args {
+		switch arg.(type) {
+		case string:
+			if f[i].verb == 'd' {
+				return false
+			}
+		case []byte:
+			if f[i].verb != 's' {
+				return false
+			}
+		case bool, float32, float64:
+			if f[i].verb != 'v' {
+				return false
+			}
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+			if f[i].verb == 's' {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"testing"
+)
+
+func TestAppendPrintf(t *testing.T) {
+	cases := []struct {
+		Format string
+		Args   []interface{}
+	}{
+		{"hello world", nil},
+		{"hello %s", []interface{}{"world"}},
+		{"hello %s", []interface{}{[]byte("bytes")}},
+		{"%d%% done, %v left", []interface{}{42, uint8(7)}},
+		{"%v %v %v %v", []interface{}{true, 1.5, float32(0.1), 1e21}},
+		{"%v %v %v", []interface{}{math.Inf(1), math.NaN(), -0.000001}},
+		{"%d %d %d %d", []interface{}{int8(-1), int64(math.MinInt64), uint64(math.MaxUint64), uintptr(9)}},
+		{"%s", []interface{}{42}},
+		{"%d", []interface{}{"str"}},
+		{"%v", []interface{}{[]byte("b")}},
+		{"%v", []interface{}{errors.New("an error")}},
+		{"%x %5d", []interface{}{255, 3}},
+		{"%s %s", []interface{}{"missing"}},
+		{"%s", []interface{}{"extra", "args"}},
+		{"trailing %", nil},
+	}
+
+	for _, c := range cases {
+		for i := 0; i < 2; i++ {
+			if got, want := string(appendPrintf(nil, c.Format, c.Args)), fmt.Sprintf(c.Format, c.Args...); got != want {
+				t.Errorf("appendPrintf(%q) = %q, want %q", c.Format, got, want)
+			}
+		}
+	}
+}
+
+func TestAppendPrintfAllocs(t *testing.T) {
+	dst := make([]byte, 0, 256)
+	s, n := "world", 42
+	args := []interface{}{s, n}
+	allocs := testing.AllocsPerRun(100, func() {
+		dst = appendPrintf(dst[:0], "hello %s, %d", args)
+	})
+	if allocs != 0 {
+		t.Errorf("appendPrintf should not allocate: %v", allocs)
+	}
+}
+
+func BenchmarkLoggerMsgf(b *testing.B) {
+	logger := Logger{Writer: IOWriter{ioutil.Discard}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info().Msgf("hello %s, %d", "world", 42)
+	}
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"io"
+	"os"
+)
+
+// PriorityWriter is an Writer that prefixes each line with the sd-daemon(3) priority
+// of level, e.g. "<6>" for info, so that journald classifies severities of the stdout
+// or stderr of systemd services correctly.
+type PriorityWriter struct {
+	// Detect determines if prefixing only when the output is connected to journald,
+	// by checking the JOURNAL_STREAM environment variable.
+	Detect bool
+
+	// Writer is the output destination. using os.Stderr if empty.
+	Writer io.Writer
+}
+
+// Close implements io.Closer, will closes the underlying Writer if not empty.
+func (w *PriorityWriter) Close() (err error) {
+	if w.Writer != nil {
+		if closer, ok := w.Writer.(io.Closer); ok {
+			err = closer.Close()
+		}
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *PriorityWriter) WriteEntry(e *Entry) (int, error) {
+	out := w.Writer
+	if out == nil {
+		out = os.Stderr
+	}
+
+	if w.Detect && os.Getenv("JOURNAL_STREAM") == "" {
+		return out.Write(e.buf)
+	}
+
+	// convert level to sd-daemon priority
+	var priority byte
+	switch e.Level {
+	case TraceLevel:
+		priority = '7' // SD_DEBUG
+	case DebugLevel:
+		priority = '7' // SD_DEBUG
+	case InfoLevel:
+		priority = '6' // SD_INFO
+	case WarnLevel:
+		priority = '4' // SD_WARNING
+	case ErrorLevel:
+		priority = '3' // SD_ERR
+	case FatalLevel:
+		priority = '2' // SD_CRIT
+	case PanicLevel:
+		priority = '1' // SD_ALERT
+	default:
+		priority = '6' // SD_INFO
+	}
+
+	b := bbget()
+	defer bbput(b)
+
+	b.B = append(b.B, '<', priority, '>')
+	b.B = append(b.B, e.buf...)
+
+	return out.Write(b.B)
+}
+
+var _ Writer = (*PriorityWriter)(nil)
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPriorityWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Level:  TraceLevel,
+		Writer: &PriorityWriter{Writer: &buf},
+	}
+
+	logger.Info().Msg("hello info")
+	logger.Warn().Msg("hello warn")
+	logger.Error().Msg("hello error")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, prefix := range []string{`<6>{"time":`, `<4>{"time":`, `<3>{"time":`} {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("priority writer output mismatch: %s", lines[i])
+		}
+	}
+
+	buf.Reset()
+	logger.Writer = &PriorityWriter{Detect: true, Writer: &buf}
+
+	os.Unsetenv("JOURNAL_STREAM")
+	logger.Info().Msg("hello detect")
+	if s := buf.String(); !strings.HasPrefix(s, `{"time":`) {
+		t.Errorf("priority writer should not prefix without journald: %s", s)
+	}
+
+	buf.Reset()
+	os.Setenv("JOURNAL_STREAM", "8:12345")
+	defer os.Unsetenv("JOURNAL_STREAM")
+	logger.Info().Msg("hello detect")
+	if s := buf.String(); !strings.HasPrefix(s, `<6>{"time":`) {
+		t.Errorf("priority writer should prefix with journald: %s", s)
+	}
+}
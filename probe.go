@@ -0,0 +1,35 @@
+package log
+
+// ProbeWriter is an Writer which fires a probe per entry at Level or above before writing
+// the entry to Writer, it enables bpftrace-based live debugging of production error rates
+// without changing the sinks.
+//
+// The probe is only compiled with the usdt build tag, e.g. `go build -tags usdt`, and it is
+// a no-op otherwise. The probe is the uprobe-friendly function logProbe, which receives the
+// level and the json of entry in registers of the Go internal ABI, e.g. on amd64
+//
+//	bpftrace -e 'uprobe:./app:"github.com/phuslu/log.logProbe" { @[reg("ax")] = count(); }'
+//	bpftrace -e 'uprobe:./app:"github.com/phuslu/log.logProbe" { printf("%s", str(reg("bx"), reg("cx"))); }'
+type ProbeWriter struct {
+	// Level is the minimum level of the entries firing the probe, it uses ErrorLevel if empty.
+	Level Level
+
+	// Writer specifies the writer of output.
+	Writer Writer
+}
+
+// WriteEntry implements Writer.
+func (w *ProbeWriter) WriteEntry(e *Entry) (int, error) {
+	if probeEnabled {
+		level := w.Level
+		if level == 0 {
+			level = ErrorLevel
+		}
+		if e.Level >= level && len(e.buf) != 0 {
+			logProbe(e.Level, &e.buf[0], len(e.buf))
+		}
+	}
+	return w.Writer.WriteEntry(e)
+}
+
+var _ Writer = (*ProbeWriter)(nil)
@@ -0,0 +1,22 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProbeWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Writer: &ProbeWriter{
+			Writer: IOWriter{&buf},
+		},
+	}
+
+	logger.Info().Msg("hello probe")
+	logger.Error().Str("foo", "bar").Msg("hello probe error")
+
+	if n := bytes.Count(buf.Bytes(), []byte("hello probe")); n != 2 {
+		t.Errorf("probe writer should write all entries: %s", buf.String())
+	}
+}
@@ -0,0 +1,15 @@
+// +build usdt
+
+package log
+
+const probeEnabled = true
+
+// logProbe is the probe point of ProbeWriter, it must not be inlined to be attached by uprobes.
+//
+//go:noinline
+func logProbe(level Level, data *byte, n int) {
+	probeSink = uintptr(level) + uintptr(n)
+}
+
+// probeSink keeps logProbe from being optimized out.
+var probeSink uintptr
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"os"
+	"strconv"
+)
+
+// ProtoWriter is an Writer that writes entries as varint length-prefixed protobuf messages
+// (the delimited format of protobuf-java writeDelimitedTo) to Writer. The schema is
+//
+//	syntax = "proto3";
+//
+//	message Field {
+//	  string key = 1;
+//	  oneof value {
+//	    string string = 2;
+//	    sint64 int = 3;
+//	    uint64 uint = 4;
+//	    double float = 5;
+//	    bool bool = 6;
+//	    bool null = 7;
+//	    string json = 8; // arrays and objects
+//	  }
+//	}
+//
+//	message Entry {
+//	  int32 level = 1;
+//	  repeated Field fields = 2;
+//	}
+type ProtoWriter struct {
+	// Writer is the output destination. using os.Stderr if empty.
+	Writer io.Writer
+}
+
+// Close implements io.Closer, will closes the underlying Writer if not empty.
+func (w *ProtoWriter) Close() (err error) {
+	if w.Writer != nil {
+		if closer, ok := w.Writer.(io.Closer); ok {
+			err = closer.Close()
+		}
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *ProtoWriter) WriteEntry(e *Entry) (int, error) {
+	out := w.Writer
+	if out == nil {
+		out = os.Stderr
+	}
+
+	msg := bbget()
+	defer bbput(msg)
+
+	field := bbget()
+	defer bbput(field)
+
+	if e.Level != 0 && e.Level != noLevel {
+		msg.B = append(msg.B, 1<<3|0)
+		msg.B = appendUvarint(msg.B, uint64(e.Level))
+	}
+
+	jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if bytes.IndexByte(key, '\\') >= 0 {
+			b := bbget()
+			b.B = jsonUnescape(key, b.B)
+			field.B = appendProtoBytes(field.B[:0], 1, b.B)
+			bbput(b)
+		} else {
+			field.B = appendProtoBytes(field.B[:0], 1, key)
+		}
+		field.B = appendProtoValue(field.B, value, typ)
+		msg.B = appendProtoBytes(msg.B, 2, field.B)
+	})
+
+	field.B = appendUvarint(field.B[:0], uint64(len(msg.B)))
+	field.B = append(field.B, msg.B...)
+
+	return out.Write(field.B)
+}
+
+func appendProtoValue(dst, value []byte, typ byte) []byte {
+	switch typ {
+	case 's':
+		return appendProtoBytes(dst, 2, value[1:len(value)-1])
+	case 'S':
+		b := bbget()
+		b.B = jsonUnescape(value[1:len(value)-1], b.B)
+		dst = appendProtoBytes(dst, 2, b.B)
+		bbput(b)
+		return dst
+	case 'n':
+		if i, err := strconv.ParseInt(b2s(value), 10, 64); err == nil {
+			dst = append(dst, 3<<3|0)
+			return appendUvarint(dst, uint64(i<<1)^uint64(i>>63))
+		}
+		if u, err := strconv.ParseUint(b2s(value), 10, 64); err == nil {
+			dst = append(dst, 4<<3|0)
+			return appendUvarint(dst, u)
+		}
+		f, _ := strconv.ParseFloat(b2s(value), 64)
+		dst = append(dst, 5<<3|1)
+		u := math.Float64bits(f)
+		return append(dst, byte(u), byte(u>>8), byte(u>>16), byte(u>>24), byte(u>>32), byte(u>>40), byte(u>>48), byte(u>>56))
+	case 't':
+		return append(dst, 6<<3|0, 1)
+	case 'f':
+		return append(dst, 6<<3|0, 0)
+	case 'o':
+		return appendProtoBytes(dst, 8, value)
+	default:
+		return append(dst, 7<<3|0, 1)
+	}
+}
+
+func appendProtoBytes(dst []byte, num int, b []byte) []byte {
+	dst = append(dst, byte(num<<3|2))
+	dst = appendUvarint(dst, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func appendUvarint(dst []byte, u uint64) []byte {
+	for u >= 0x80 {
+		dst = append(dst, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(dst, byte(u))
+}
+
+var _ Writer = (*ProtoWriter)(nil)
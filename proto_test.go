@@ -0,0 +1,137 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestProtoWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Writer: &ProtoWriter{Writer: &buf},
+	}
+
+	logger.Warn().
+		Str("foo", "a\"b").
+		Int("n", -42).
+		Uint64("huge", 18446744073709551615).
+		Float64("pi", 3.14).
+		Bool("t", true).
+		Err(nil).
+		Strs("tags", []string{"a", "b"}).
+		Msg("hello proto")
+
+	data := buf.Bytes()
+	size, n := binary.Uvarint(data)
+	if n <= 0 || int(size) != len(data)-n {
+		t.Fatalf("proto length prefix mismatch: %d %d %d", size, n, len(data))
+	}
+	data = data[n:]
+
+	var level uint64
+	fields := map[string]string{}
+	for len(data) > 0 {
+		tag, value, rest := testProtoNext(t, data)
+		data = rest
+		switch tag >> 3 {
+		case 1:
+			level = binary.LittleEndian.Uint64(value)
+		case 2:
+			var key, val string
+			for len(value) > 0 {
+				tag, v, rest := testProtoNext(t, value)
+				value = rest
+				switch tag >> 3 {
+				case 1:
+					key = string(v)
+				case 2, 8:
+					val = string(v)
+				case 3:
+					u := binary.LittleEndian.Uint64(v)
+					if int64(u>>1)^-int64(u&1) == -42 {
+						val = "-42"
+					}
+				case 4:
+					if binary.LittleEndian.Uint64(v) == math.MaxUint64 {
+						val = "max"
+					}
+				case 5:
+					if math.Float64frombits(binary.LittleEndian.Uint64(v)) == 3.14 {
+						val = "3.14"
+					}
+				case 6:
+					if v[0] == 1 {
+						val = "true"
+					}
+				case 7:
+					val = "null"
+				}
+			}
+			fields[key] = val
+		}
+	}
+
+	if level != uint64(WarnLevel) {
+		t.Errorf("proto level mismatch: %d", level)
+	}
+	for key, value := range map[string]string{
+		"level":   "warn",
+		"foo":     "a\"b",
+		"n":       "-42",
+		"huge":    "max",
+		"pi":      "3.14",
+		"t":       "true",
+		"error":   "null",
+		"tags":    `["a","b"]`,
+		"message": "hello proto",
+	} {
+		if fields[key] != value {
+			t.Errorf("proto field %s mismatch: %#v != %#v", key, fields[key], value)
+		}
+	}
+}
+
+// testProtoNext reads a protobuf field, varint and fixed64 values are returned as 8 bytes little endian.
+func testProtoNext(t *testing.T, data []byte) (tag uint64, value []byte, rest []byte) {
+	tag, n := binary.Uvarint(data)
+	if n <= 0 {
+		t.Fatalf("proto invalid tag: %v", data)
+	}
+	data = data[n:]
+	switch tag & 7 {
+	case 0:
+		u, n := binary.Uvarint(data)
+		value = make([]byte, 8)
+		binary.LittleEndian.PutUint64(value, u)
+		return tag, value, data[n:]
+	case 1:
+		return tag, data[:8], data[8:]
+	case 2:
+		size, n := binary.Uvarint(data)
+		return tag, data[n : n+int(size)], data[n+int(size):]
+	}
+	t.Fatalf("proto unexpected wire type: %d", tag&7)
+	return
+}
+
+func TestProtoWriterEscapedKey(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &ProtoWriter{Writer: &buf}
+	if _, err := w.WriteEntry(&Entry{Level: noLevel, buf: []byte(`{"a\"b\u00e9":1}`)}); err != nil {
+		t.Fatalf("proto writer error: %+v", err)
+	}
+
+	data := buf.Bytes()
+	_, n := binary.Uvarint(data)
+	tag, value, rest := testProtoNext(t, data[n:])
+	if tag>>3 != 2 || len(rest) != 0 {
+		t.Fatalf("proto writer should omit the level of noLevel: %d %v", tag>>3, rest)
+	}
+	if tag, key, _ := testProtoNext(t, value); tag>>3 != 1 || string(key) != "a\"bé" {
+		t.Errorf("proto writer should unescape the keys: %q", key)
+	}
+}
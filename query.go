@@ -0,0 +1,215 @@
+package log
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EntryReader is an iterator over the json entries of log files, in the order of
+// file modification time, e.g.
+//
+//	r, err := log.Open("/var/log/app/main.*.log*")
+//	if err != nil {
+//		return err
+//	}
+//	defer r.Close()
+//
+//	r.Level = log.WarnLevel
+//	r.Since = time.Now().Add(-time.Hour)
+//	for r.Next() {
+//		args := r.Entry()
+//		fmt.Println(args.Time, args.Level, args.Message)
+//	}
+//	return r.Err()
+type EntryReader struct {
+	// Level filters out the entries below Level, the default is no filtering.
+	Level Level
+
+	// Since filters out the entries before Since if it is not zero.
+	Since time.Time
+
+	// Until filters out the entries after Until if it is not zero.
+	Until time.Time
+
+	// Fields filters out the entries whose fields are not equal to Fields.
+	Fields map[string]string
+
+	files   []string
+	file    *os.File
+	reader  io.Reader
+	scanner *bufio.Scanner
+	line    []byte
+	args    FormatterArgs
+	err     error
+}
+
+// Open returns an EntryReader over the log files matching pattern, including the
+// rotated backups and gzipped files. Symlinks are resolved, and the same file is
+// read only once.
+func Open(pattern string) (*EntryReader, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	type file struct {
+		name    string
+		modTime time.Time
+	}
+
+	seen := make(map[string]bool)
+	files := make([]file, 0, len(matches))
+	for _, name := range matches {
+		if name1, err := filepath.EvalSymlinks(name); err == nil {
+			name = name1
+		}
+		if seen[name] || strings.HasSuffix(name, ".enc") || strings.HasSuffix(name, ".manifest") {
+			continue
+		}
+		seen[name] = true
+		fi, err := os.Stat(name)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, file{name, fi.ModTime()})
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+
+	r := &EntryReader{}
+	for _, f := range files {
+		r.files = append(r.files, f.name)
+	}
+
+	return r, nil
+}
+
+// Next advances to the next entry matching the filters, it returns false at the end
+// of files or on error.
+func (r *EntryReader) Next() bool {
+	for r.err == nil {
+		if r.scanner == nil {
+			if len(r.files) == 0 {
+				return false
+			}
+			r.err = r.open(r.files[0])
+			r.files = r.files[1:]
+			continue
+		}
+
+		if !r.scanner.Scan() {
+			r.err = r.scanner.Err()
+			r.closeFile()
+			continue
+		}
+
+		line := r.scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+
+		r.line = append(make([]byte, 0, len(line)), line...)
+		r.args = FormatterArgs{}
+		parseFormatterArgs(append(make([]byte, 0, len(line)), line...), &r.args)
+
+		if r.match() {
+			return true
+		}
+	}
+	return false
+}
+
+// Entry returns the parsed fields of current entry.
+func (r *EntryReader) Entry() *FormatterArgs {
+	return &r.args
+}
+
+// Bytes returns the json of current entry.
+func (r *EntryReader) Bytes() []byte {
+	return r.line
+}
+
+// Err returns the first error encountered during iteration.
+func (r *EntryReader) Err() error {
+	return r.err
+}
+
+// Close closes the current file.
+func (r *EntryReader) Close() error {
+	r.files = nil
+	return r.closeFile()
+}
+
+func (r *EntryReader) open(name string) (err error) {
+	r.file, err = os.Open(name)
+	if err != nil {
+		return
+	}
+	r.reader = r.file
+	if strings.HasSuffix(name, ".gz") {
+		r.reader, err = gzip.NewReader(r.file)
+		if err != nil {
+			r.file.Close()
+			r.file = nil
+			return
+		}
+	}
+	r.scanner = bufio.NewScanner(r.reader)
+	r.scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return
+}
+
+func (r *EntryReader) closeFile() (err error) {
+	if closer, ok := r.reader.(*gzip.Reader); ok {
+		closer.Close()
+	}
+	if r.file != nil {
+		err = r.file.Close()
+	}
+	r.file, r.reader, r.scanner = nil, nil, nil
+	return
+}
+
+func (r *EntryReader) match() bool {
+	if r.Level > 0 && ParseLevel(r.args.Level) < r.Level {
+		return false
+	}
+
+	if !r.Since.IsZero() || !r.Until.IsZero() {
+		t, ok := parseEntryTime(r.args.Time)
+		if !ok || (!r.Since.IsZero() && t.Before(r.Since)) || (!r.Until.IsZero() && t.After(r.Until)) {
+			return false
+		}
+	}
+
+	for key, value := range r.Fields {
+		if r.args.Get(key) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseEntryTime parses the time of entry, in RFC3339 or UNIX timestamp format.
+func parseEntryTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, true
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if len(s) >= 13 {
+			return time.Unix(n/1000, n%1000*1000000), true
+		}
+		return time.Unix(n, 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEntryReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-query")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := &FileWriter{
+		Filename:    filepath.Join(dir, "main.log"),
+		MaxBackups:  10,
+		WaitCleanup: true,
+	}
+	logger := Logger{Level: TraceLevel, Writer: w}
+
+	logger.Info().Str("user", "alice").Msg("first")
+	logger.Warn().Str("user", "bob").Msg("second")
+	backup := w.file.Name()
+	time.Sleep(10 * time.Millisecond)
+	w.Rotate()
+	logger.Error().Str("user", "alice").Msg("third")
+	logger.Debug().Msg("fourth")
+	w.Close()
+
+	// gzip the backup
+	data, _ := ioutil.ReadFile(backup)
+	gz, _ := os.Create(backup + ".gz")
+	zw := gzip.NewWriter(gz)
+	zw.Write(data)
+	zw.Close()
+	gz.Close()
+	os.Remove(backup)
+	os.Chtimes(backup+".gz", time.Now().Add(-time.Minute), time.Now().Add(-time.Minute))
+
+	query := func(setup func(r *EntryReader)) (messages []string) {
+		r, err := Open(filepath.Join(dir, "main*"))
+		if err != nil {
+			t.Fatalf("open error: %+v", err)
+		}
+		defer r.Close()
+		setup(r)
+		for r.Next() {
+			messages = append(messages, r.Entry().Message)
+			if len(r.Bytes()) == 0 {
+				t.Errorf("entry reader bytes should not be empty")
+			}
+		}
+		if err := r.Err(); err != nil {
+			t.Errorf("entry reader error: %+v", err)
+		}
+		return
+	}
+
+	for _, c := range []struct {
+		setup    func(r *EntryReader)
+		expected string
+	}{
+		{func(r *EntryReader) {}, "first,second,third,fourth,"},
+		{func(r *EntryReader) { r.Level = WarnLevel }, "second,third,"},
+		{func(r *EntryReader) { r.Fields = map[string]string{"user": "alice"} }, "first,third,"},
+		{func(r *EntryReader) { r.Since = time.Now().Add(time.Hour) }, ""},
+		{func(r *EntryReader) { r.Until = time.Now().Add(time.Hour) }, "first,second,third,fourth,"},
+	} {
+		var s string
+		for _, m := range query(c.setup) {
+			s += m + ","
+		}
+		if s != c.expected {
+			t.Errorf("entry reader mismatch: %s, expected %s", s, c.expected)
+		}
+	}
+}
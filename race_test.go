@@ -0,0 +1,5 @@
+// +build race
+
+package log
+
+const raceEnabled = true
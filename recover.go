@@ -0,0 +1,67 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Recover logs the recovered panic value and stack at level, and re-panics if repanic is true.
+// It must be called directly by defer, e.g.
+//
+//	go func() {
+//		defer logger.Recover(log.ErrorLevel, false)
+//		// ...
+//	}()
+//
+// Use repanic instead of PanicLevel to re-panic with the original value.
+func (l *Logger) Recover(level Level, repanic bool) {
+	if r := recover(); r != nil {
+		l.recover(level, r, repanic)
+	}
+}
+
+// Recover is like Logger.Recover with the Default logger.
+func Recover(level Level, repanic bool) {
+	if r := recover(); r != nil {
+		Default().recover(level, r, repanic)
+	}
+}
+
+func (l *Logger) recover(level Level, r interface{}, repanic bool) {
+	e := l.header(level)
+	if e != nil {
+		if l.Caller > 0 {
+			e.caller(runtime.Caller(panicking()))
+		}
+		if err, ok := r.(error); ok {
+			e = e.AnErr("panic", err)
+		} else {
+			e = e.Str("panic", fmt.Sprint(r))
+		}
+		e.Stack().Msg("recovered from panic")
+	}
+	if repanic {
+		panic(r)
+	}
+}
+
+// panicking returns the skip of runtime.Caller for the function which panicked.
+func panicking() int {
+	found := false
+	for i := 1; ; i++ {
+		pc, _, _, ok := runtime.Caller(i)
+		if !ok {
+			return 1
+		}
+		name := runtime.FuncForPC(pc).Name()
+		if name == "runtime.gopanic" {
+			found = true
+			continue
+		}
+		if found && !strings.HasPrefix(name, "runtime.") {
+			// skip the frame of panicking itself
+			return i - 1
+		}
+	}
+}
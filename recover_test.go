@@ -0,0 +1,50 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoggerRecover(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Caller: 1,
+		Writer: IOWriter{&buf},
+	}
+
+	func() {
+		defer logger.Recover(ErrorLevel, false)
+		panic("boom")
+	}()
+
+	s := buf.String()
+	if !strings.Contains(s, `"level":"error","caller":"recover_test.go:20"`) ||
+		!strings.Contains(s, `"panic":"boom","stack":"`) ||
+		!strings.Contains(s, `"message":"recovered from panic"`) {
+		t.Errorf("logger recover output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	func() {
+		defer func() {
+			if r := recover(); r == nil || r.(error).Error() != "an error" {
+				t.Errorf("logger recover should re-panic: %v", r)
+			}
+		}()
+		defer logger.Recover(WarnLevel, true)
+		panic(errors.New("an error"))
+	}()
+
+	if s := buf.String(); !strings.Contains(s, `"level":"warn"`) || !strings.Contains(s, `"panic":"an error"`) {
+		t.Errorf("logger recover output mismatch: %s", s)
+	}
+
+	func() {
+		defer Recover(InfoLevel, false)
+		var m map[string]int
+		m["a"] = 1
+	}()
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// RedactEmail matches email addresses.
+var RedactEmail = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+
+// RedactCreditCard matches credit card numbers.
+var RedactCreditCard = regexp.MustCompile(`\b(?:\d[ \-]?){13,16}\b`)
+
+// RedactWriter is an Writer that masks secrets (e.g. passwords, tokens, emails and credit cards)
+// of entries before writing to the underlying Writer.
+type RedactWriter struct {
+	// Fields specifies the case-insensitive field names whose values are masked, e.g. "password".
+	Fields []string
+
+	// Patterns specifies the regexps whose matches in string values are masked.
+	Patterns []*regexp.Regexp
+
+	// Scrub specifies an optional function to scrub the string values.
+	Scrub func(key, value string) string
+
+	// Mask specifies the replacement of secrets, uses "***" if empty.
+	Mask string
+
+	// Writer specifies the writer of output.
+	Writer Writer
+}
+
+// Close implements io.Closer, and closes the underlying Writer.
+func (w *RedactWriter) Close() (err error) {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		err = closer.Close()
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *RedactWriter) WriteEntry(e *Entry) (int, error) {
+	e1 := epool.Get().(*Entry)
+	defer putEntry(e1)
+
+	e1.Level = e.Level
+	e1.loggerFiles = append(e1.loggerFiles[:0], e.loggerFiles...)
+	e1.buf = e1.buf[:0]
+	n := w.redact(e1, e.buf)
+	e1.buf = append(e1.buf, e.buf[n:]...)
+
+	return w.Writer.WriteEntry(e1)
+}
+
+func (w *RedactWriter) redact(dst *Entry, json []byte) int {
+	mask := w.Mask
+	if mask == "" {
+		mask = "***"
+	}
+
+	dst.buf = append(dst.buf, '{')
+	first := true
+	n := jsonObjectEach(json, func(key, value []byte, typ byte) {
+		if !first {
+			dst.buf = append(dst.buf, ',')
+		}
+		first = false
+		dst.buf = append(dst.buf, '"')
+		dst.buf = append(dst.buf, key...)
+		dst.buf = append(dst.buf, '"', ':')
+
+		for _, field := range w.Fields {
+			if strings.EqualFold(field, b2s(key)) {
+				dst.buf = append(dst.buf, '"')
+				dst.string(mask)
+				dst.buf = append(dst.buf, '"')
+				return
+			}
+		}
+
+		w.redactValue(dst, key, value, typ, mask)
+	})
+	dst.buf = append(dst.buf, '}')
+	return n
+}
+
+// redactValue appends the value of key to dst with the strings in objects and arrays redacted.
+func (w *RedactWriter) redactValue(dst *Entry, key, value []byte, typ byte, mask string) {
+	switch typ {
+	case 's', 'S':
+		s := value[1 : len(value)-1]
+		if typ == 'S' {
+			s = jsonUnescape(s, nil)
+		}
+		str := string(s)
+		for _, re := range w.Patterns {
+			str = re.ReplaceAllLiteralString(str, mask)
+		}
+		if w.Scrub != nil {
+			str = w.Scrub(string(key), str)
+		}
+		if str == b2s(s) && typ == 's' {
+			dst.buf = append(dst.buf, value...)
+		} else {
+			dst.buf = append(dst.buf, '"')
+			dst.string(str)
+			dst.buf = append(dst.buf, '"')
+		}
+	case 'o':
+		if value[0] == '{' {
+			w.redact(dst, value)
+			return
+		}
+		// the elements of array are redacted as the values of key
+		dst.buf = append(dst.buf, '[')
+		first := true
+		jsonArrayEach(value, func(value []byte, typ byte) {
+			if !first {
+				dst.buf = append(dst.buf, ',')
+			}
+			first = false
+			w.redactValue(dst, key, value, typ, mask)
+		})
+		dst.buf = append(dst.buf, ']')
+	default:
+		dst.buf = append(dst.buf, value...)
+	}
+}
+
+var _ Writer = (*RedactWriter)(nil)
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Writer: &RedactWriter{
+			Fields:   []string{"password", "Token"},
+			Patterns: []*regexp.Regexp{RedactEmail, RedactCreditCard},
+			Scrub: func(key, value string) string {
+				if key == "phone" {
+					return value[:3] + "****"
+				}
+				return value
+			},
+			Writer: IOWriter{&buf},
+		},
+	}
+
+	logger.Info().
+		Str("user", "neo").
+		Str("password", "123456").
+		Dict("auth", NewContext(nil).Str("token", "abcdef").Int("n", 42).Value()).
+		Str("note", "mail \"neo\" to neo@example.com").
+		Str("card", "4111 1111 1111 1111").
+		Str("phone", "13800138000").
+		Int("number", 42).
+		Strs("tags", []string{"a", "b"}).
+		Msg("login by neo@example.com")
+
+	s := buf.String()
+	for _, want := range []string{
+		`"user":"neo","password":"***","auth":{"token":"***","n":42},`,
+		`"note":"mail \"neo\" to ***","card":"***","phone":"138****","number":42,"tags":["a","b"],"message":"login by ***"}` + "\n",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("redact writer output mismatch: %s", s)
+		}
+	}
+	if strings.Contains(s, "123456") || strings.Contains(s, "abcdef") || strings.Contains(s, "@") {
+		t.Errorf("redact writer leaks secrets: %s", s)
+	}
+}
+
+func TestRedactWriterPooledEntry(t *testing.T) {
+	var buf bytes.Buffer
+
+	// leaves the entries with the settings of logger in pool
+	logger := Logger{MaxValueSize: 4, EscapeHTML: true, Writer: IOWriter{ioutil.Discard}}
+	e1, e2 := logger.Info(), logger.Info()
+	e1.Msg("")
+	e2.Msg("")
+
+	logger = Logger{
+		Writer: &RedactWriter{
+			Patterns: []*regexp.Regexp{RedactEmail},
+			Writer:   IOWriter{&buf},
+		},
+	}
+	logger.Info().Str("password", "<red>&blue").Str("mail", "neo@example.com").Msg("")
+	if s := buf.String(); !strings.Contains(s, `"password":"\u003cred>&blue","mail":"***"`) {
+		t.Errorf("redact writer should not inherit the settings of pooled entries: %s", s)
+	}
+}
+
+func TestRedactWriterArray(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := Logger{
+		Writer: &RedactWriter{
+			Fields:   []string{"password"},
+			Patterns: []*regexp.Regexp{RedactEmail},
+			Writer:   IOWriter{&buf},
+		},
+	}
+
+	logger.Info().
+		Strs("emails", []string{"neo@example.com", "trinity"}).
+		Errs("errors", []error{errors.New("no user neo@example.com"), nil}).
+		RawJSON("users", []byte(`[{"name":"neo","password":"123456"},[1,"smith@example.com"]]`)).
+		Msg("")
+
+	s := buf.String()
+	if !strings.Contains(s, `"emails":["***","trinity"],"errors":["no user ***",null],"users":[{"name":"neo","password":"***"},[1,"***"]]`) {
+		t.Errorf("redact writer array output mismatch: %s", s)
+	}
+	if strings.Contains(s, "123456") || strings.Contains(s, "@") {
+		t.Errorf("redact writer leaks secrets in arrays: %s", s)
+	}
+}
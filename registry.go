@@ -0,0 +1,116 @@
+package log
+
+import (
+	"path"
+	"sort"
+	"sync"
+)
+
+// LevelRegistry holds named loggers whose levels can be changed at runtime by name or glob.
+type LevelRegistry struct {
+	mu      sync.Mutex
+	loggers []*Logger
+	rules   []levelRule
+}
+
+type levelRule struct {
+	pattern string
+	level   Level
+}
+
+// DefaultLevelRegistry is the global level registry.
+var DefaultLevelRegistry = &LevelRegistry{}
+
+// Register adds the named logger to the registry, the level of the latest matched
+// pattern is applied to the logger.
+func (r *LevelRegistry) Register(l *Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, logger := range r.loggers {
+		if logger == l {
+			return
+		}
+	}
+	r.loggers = append(r.loggers, l)
+
+	for i := len(r.rules) - 1; i >= 0; i-- {
+		if ok, _ := path.Match(r.rules[i].pattern, l.Name); ok {
+			l.SetLevel(r.rules[i].level)
+			break
+		}
+	}
+}
+
+// Unregister removes the logger from the registry.
+func (r *LevelRegistry) Unregister(l *Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, logger := range r.loggers {
+		if logger == l {
+			r.loggers = append(r.loggers[:i], r.loggers[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetLevel changes the level of loggers whose name matches the glob pattern,
+// e.g. "db.*", and returns the number of matched loggers. The pattern syntax is
+// the same as path.Match.
+func (r *LevelRegistry) SetLevel(pattern string, level Level) (n int, err error) {
+	if _, err = path.Match(pattern, ""); err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, l := range r.loggers {
+		if ok, _ := path.Match(pattern, l.Name); ok {
+			l.SetLevel(level)
+			n++
+		}
+	}
+
+	for i, rule := range r.rules {
+		if rule.pattern == pattern {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			break
+		}
+	}
+	r.rules = append(r.rules, levelRule{pattern, level})
+
+	return
+}
+
+// Levels returns the current levels of registered loggers by name.
+func (r *LevelRegistry) Levels() map[string]Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	levels := make(map[string]Level, len(r.loggers))
+	for _, l := range r.loggers {
+		levels[l.Name] = l.GetLevel()
+	}
+	return levels
+}
+
+// Names returns the sorted names of registered loggers.
+func (r *LevelRegistry) Names() (names []string) {
+	for name := range r.Levels() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// Register adds the named logger to DefaultLevelRegistry.
+func Register(l *Logger) {
+	DefaultLevelRegistry.Register(l)
+}
+
+// SetLevel changes the level of loggers in DefaultLevelRegistry whose name matches the glob pattern.
+func SetLevel(pattern string, level Level) (int, error) {
+	return DefaultLevelRegistry.SetLevel(pattern, level)
+}
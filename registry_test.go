@@ -0,0 +1,52 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestLevelRegistry(t *testing.T) {
+	r := &LevelRegistry{}
+
+	logger := Logger{Level: InfoLevel}
+	sql := logger.Named("db").Named("sql")
+	redis := logger.Named("db").Named("redis")
+	http := logger.Named("http")
+
+	r.Register(sql)
+	r.Register(redis)
+	r.Register(http)
+	r.Register(http)
+
+	n, err := r.SetLevel("db.*", DebugLevel)
+	if err != nil || n != 2 {
+		t.Errorf("level registry set level mismatch: n=%d err=%+v", n, err)
+	}
+	if sql.Level != DebugLevel || redis.Level != DebugLevel || http.Level != InfoLevel {
+		t.Errorf("level registry levels mismatch: %+v", r.Levels())
+	}
+
+	if _, err := r.SetLevel("[", DebugLevel); err == nil {
+		t.Errorf("level registry should return bad pattern error")
+	}
+
+	mongo := logger.Named("db").Named("mongo")
+	r.Register(mongo)
+	if mongo.Level != DebugLevel {
+		t.Errorf("level registry should apply level to later registered logger")
+	}
+
+	r.Unregister(http)
+	if names := r.Names(); len(names) != 3 || names[0] != "db.mongo" {
+		t.Errorf("level registry names mismatch: %+v", names)
+	}
+}
+
+func TestLevelRegistryDefault(t *testing.T) {
+	logger := Logger{Level: InfoLevel, Name: "default_registry_test"}
+	Register(&logger)
+	defer DefaultLevelRegistry.Unregister(&logger)
+
+	if n, _ := SetLevel("default_registry_*", ErrorLevel); n != 1 || logger.GetLevel() != ErrorLevel {
+		t.Errorf("default level registry set level mismatch")
+	}
+}
@@ -0,0 +1,67 @@
+package log
+
+// SamplerWriter is an Writer that samples the entries consistently by the value of a field, e.g.
+// user_id or trace_id, so all entries of the sampled-in keys are kept fully while the others are
+// dropped, preserving the complete narratives for a subset of requests. The decision only depends
+// on the value, so it is consistent across processes and restarts.
+type SamplerWriter struct {
+	// Key specifies the top-level field to hash, e.g. "trace_id".
+	Key string
+
+	// Rate specifies the fraction of keys to keep, from 0 to 1, e.g. 0.1 keeps 10% of keys.
+	Rate float64
+
+	// Level specifies the level at or above which entries are always written, e.g. ErrorLevel.
+	// All levels are sampled if it is zero.
+	Level Level
+
+	// Writer specifies the writer of output.
+	Writer Writer
+}
+
+// WriteEntry implements Writer. The entries without the field are always written.
+func (w *SamplerWriter) WriteEntry(e *Entry) (int, error) {
+	if w.Level != 0 && e.Level >= w.Level {
+		return w.Writer.WriteEntry(e)
+	}
+
+	var value []byte
+	found := false
+	jsonObjectEach(e.buf, func(k, v []byte, typ byte) {
+		if !found && b2s(k) == w.Key {
+			value, found = v, true
+			// hashes the content of strings, so Sampled(user_id, rate) matches it
+			if (typ == 's' || typ == 'S') && len(value) >= 2 && value[0] == '"' {
+				value = value[1 : len(value)-1]
+			}
+		}
+	})
+	if !found || Sampled(b2s(value), w.Rate) {
+		return w.Writer.WriteEntry(e)
+	}
+	return len(e.buf), nil
+}
+
+// samplerPrecision is the number of buckets of the sampling rate.
+const samplerPrecision = 1 << 24
+
+// Sampled reports whether the key is sampled in at rate, it hashes the key by FNV-1a, e.g. to
+// decide whether to add the debug fields of a request consistently with SamplerWriter.
+func Sampled(key string, rate float64) bool {
+	switch {
+	case rate <= 0:
+		return false
+	case rate >= 1:
+		return true
+	}
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(key); i++ {
+		h ^= uint64(key[i])
+		h *= 1099511628211
+	}
+	// folds the high bits, which are better mixed by FNV-1a than the low bits
+	h ^= h >> 32
+	return h%samplerPrecision < uint64(rate*samplerPrecision)
+}
+
+var _ Writer = (*SamplerWriter)(nil)
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSamplerWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{
+		Writer: &SamplerWriter{
+			Key:    "user_id",
+			Rate:   0.25,
+			Level:  ErrorLevel,
+			Writer: IOWriter{&buf},
+		},
+	}
+
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		id := "user-" + strconv.Itoa(i)
+		buf.Reset()
+		logger.Info().Str("user_id", id).Msg("request start")
+		logger.Debug().Str("user_id", id).Int("step", 1).Msg("request step")
+		logger.Info().Str("user_id", id).Msg("request end")
+		n := strings.Count(buf.String(), "\n")
+		if n != 0 && n != 3 {
+			t.Fatalf("sampler writer should keep all or none of the entries of a key: %s", buf.String())
+		}
+		if (n == 3) != Sampled(id, 0.25) {
+			t.Fatalf("sampler writer should be consistent with Sampled(%q): %d", id, n)
+		}
+		if n == 3 {
+			kept++
+		}
+	}
+	if kept < 200 || kept > 300 {
+		t.Errorf("sampler writer should keep about 25%% keys, got %d/1000", kept)
+	}
+
+	buf.Reset()
+	logger.Info().Msg("no user")
+	logger.Error().Str("user_id", "user-dropped").Msg("error is always kept")
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Errorf("sampler writer should keep entries without key and errors: %s", buf.String())
+	}
+}
+
+func TestSampled(t *testing.T) {
+	if Sampled("abc", 0) || !Sampled("abc", 1) {
+		t.Errorf("Sampled should respect rate 0 and 1")
+	}
+	for i := 0; i < 100; i++ {
+		key := strconv.Itoa(i)
+		if Sampled(key, 0.1) && !Sampled(key, 0.5) {
+			t.Errorf("Sampled keys of lower rate should be sampled at higher rate: %s", key)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"sync"
+)
+
+// SchemaAction specifies how SchemaHook handles the entries missing the required fields.
+type SchemaAction int
+
+const (
+	// SchemaAnnotate adds the missing field names to the entry, i.e. "schema_missing":["actor"].
+	SchemaAnnotate SchemaAction = iota
+	// SchemaFlag adds "schema_violation":true to the entry.
+	SchemaFlag
+	// SchemaReject discards the entry.
+	SchemaReject
+)
+
+// Schema specifies the required fields of the entries of a logger name.
+type Schema struct {
+	// Required specifies the required top-level fields, e.g. actor, action and resource.
+	Required []string
+
+	// Action specifies how the entries missing the required fields are handled.
+	Action SchemaAction
+}
+
+// SchemaHook is a Hook that validates the entries against the schemas registered per logger
+// name, to enforce the logging standards in large codebases, e.g.
+//
+//	schemas := &log.SchemaHook{}
+//	schemas.Register("audit", log.Schema{Required: []string{"actor", "action", "resource"}})
+//	logger := log.Logger{Name: "audit", Hooks: []log.Hook{schemas}, Writer: writer}
+//
+// The names are the Logger.Name and the destinations added by Entry.To.
+type SchemaHook struct {
+	// OnViolation specifies an optional callback of violations, e.g. counting metrics.
+	OnViolation func(name string, missing []string)
+
+	mu      sync.RWMutex
+	schemas map[string]Schema
+}
+
+// Register registers the schema of the entries of logger name, it replaces the previous one.
+func (h *SchemaHook) Register(name string, schema Schema) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.schemas == nil {
+		h.schemas = make(map[string]Schema)
+	}
+	h.schemas[name] = schema
+}
+
+// Run implements Hook.
+func (h *SchemaHook) Run(e *Entry, level Level, msg string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.schemas) == 0 {
+		return
+	}
+
+	for _, name := range e.loggerFiles {
+		schema, ok := h.schemas[name]
+		if !ok || len(schema.Required) == 0 {
+			continue
+		}
+
+		var missing []string
+		for _, field := range schema.Required {
+			if (field == "message" && msg != "") || schemaHasField(e.buf, field) {
+				continue
+			}
+			missing = append(missing, field)
+		}
+		if missing == nil {
+			continue
+		}
+
+		if h.OnViolation != nil {
+			h.OnViolation(name, missing)
+		}
+		switch schema.Action {
+		case SchemaReject:
+			e.Discard()
+			return
+		case SchemaFlag:
+			e.Bool("schema_violation", true)
+		default:
+			e.Strs("schema_missing", missing)
+		}
+		return
+	}
+}
+
+// schemaHasField reports whether the top-level field is present in the json of entry.
+func schemaHasField(json []byte, field string) (found bool) {
+	jsonObjectEach(json, func(key, _ []byte, _ byte) {
+		found = found || b2s(key) == field
+	})
+	return
+}
+
+var _ Hook = (*SchemaHook)(nil)
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSchemaHook(t *testing.T) {
+	var buf bytes.Buffer
+	var violations []string
+
+	schemas := &SchemaHook{
+		OnViolation: func(name string, missing []string) {
+			violations = append(violations, name+":"+strings.Join(missing, ","))
+		},
+	}
+	schemas.Register("audit", Schema{Required: []string{"actor", "action", "resource"}})
+	schemas.Register("billing", Schema{Required: []string{"amount", "message"}, Action: SchemaFlag})
+	schemas.Register("security", Schema{Required: []string{"actor"}, Action: SchemaReject})
+
+	audit := Logger{Name: "audit", Hooks: []Hook{schemas}, Writer: IOWriter{&buf}}
+	audit.Info().Str("actor", "bob").Str("action", "delete").Str("resource", "doc/1").Msg("audit")
+	if s := buf.String(); strings.Contains(s, "schema_") {
+		t.Errorf("schema hook should pass valid entries: %s", s)
+	}
+
+	buf.Reset()
+	audit.Info().Str("actor", "bob").Msg("audit")
+	if s := buf.String(); !strings.Contains(s, `"actor":"bob","schema_missing":["action","resource"],"message":"audit"}`) {
+		t.Errorf("schema hook should annotate the missing fields: %s", s)
+	}
+
+	buf.Reset()
+	billing := Logger{Name: "billing", Hooks: []Hook{schemas}, Writer: IOWriter{&buf}}
+	billing.Info().Msg("")
+	if s := buf.String(); !strings.Contains(s, `"schema_violation":true`) {
+		t.Errorf("schema hook should flag the entry: %s", s)
+	}
+
+	buf.Reset()
+	security := Logger{Name: "security", Hooks: []Hook{schemas}, Writer: IOWriter{&buf}}
+	security.Warn().Msg("login failed")
+	security.Warn().Str("actor", "eve").Msg("login failed")
+	if s := buf.String(); strings.Count(s, "\n") != 1 || !strings.Contains(s, `"actor":"eve"`) {
+		t.Errorf("schema hook should reject the invalid entry: %s", s)
+	}
+
+	buf.Reset()
+	app := Logger{Hooks: []Hook{schemas}, Writer: IOWriter{&buf}}
+	app.Info().Msg("not validated")
+	app.Info().To("audit").Str("actor", "bob").Str("action", "read").Msg("routed to audit")
+	if s := buf.String(); !strings.Contains(s, `"action":"read","schema_missing":["resource"],"message":"routed to audit"}`) {
+		t.Errorf("schema hook should validate the destinations of To: %s", s)
+	}
+
+	want := "audit:action,resource billing:amount,message security:actor audit:resource"
+	if got := strings.Join(violations, " "); got != want {
+		t.Errorf("schema hook violations mismatch: %s", got)
+	}
+}
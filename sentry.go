@@ -0,0 +1,400 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentryWriter is an Writer that reports entries at or above Level to Sentry as events
+// via the envelope api, without depending on the Sentry SDK. The "message" field is mapped
+// to message, "error" and "stack" to an exception with stacktrace, string fields to tags
+// and the other fields to extra. Events are sent in background in batches and rate-limited.
+type SentryWriter struct {
+	// DSN is the Sentry DSN, e.g. https://public@o0.ingest.sentry.io/123
+	DSN string
+
+	// Level is the minimum level of entries to report, the default is ErrorLevel.
+	Level Level
+
+	// Environment specifies the environment of events, e.g. production.
+	Environment string
+
+	// Release specifies the release of events, e.g. myapp@1.0.0.
+	Release string
+
+	// BatchSize is the max number of events in a batch, the default size is 100.
+	BatchSize int
+
+	// FlushInterval is the interval of sending incomplete batches, the default is 1s.
+	FlushInterval time.Duration
+
+	// RateLimit is the max number of events reported per minute, the default is 60.
+	// The events beyond it are dropped, and sending is paused if Sentry returns 429.
+	RateLimit int
+
+	// Client specifies the http client of sending, using a client with 10s timeout if nil.
+	Client *http.Client
+
+	// Context specifies the base context of requests, the in-flight flushes are aborted when it
+	// is canceled. It uses context.Background() if nil.
+	Context context.Context
+
+	mu      sync.Mutex
+	once    sync.Once
+	closing sync.Once
+	events  [][]byte
+	window  time.Time
+	count   int
+	until   time.Time
+	dropped int64
+	done    chan struct{}
+	closed  chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	err     error
+}
+
+// Healthy implements HealthChecker, returns the error of last sending.
+func (w *SentryWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Dropped returns the number of events dropped by rate limiting.
+func (w *SentryWriter) Dropped() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dropped
+}
+
+// Close implements io.Closer, sends the pending events and stops the flushing.
+func (w *SentryWriter) Close() (err error) {
+	w.init()
+	w.closing.Do(func() {
+		unregisterWriter(w)
+		close(w.done)
+		<-w.closed
+		err = w.Flush()
+		w.cancel()
+	})
+	return
+}
+
+// Shutdown is like Close, but if ctx is done before Close returns, it aborts the in-flight
+// requests and returns ctx.Err(), so it never blocks the shutdown beyond the deadline of ctx.
+func (w *SentryWriter) Shutdown(ctx context.Context) error {
+	w.init()
+	return shutdown(ctx, w.Close, w.cancel)
+}
+
+// Flush sends the pending events.
+func (w *SentryWriter) Flush() error {
+	w.init()
+	w.mu.Lock()
+	events := w.events
+	w.events = nil
+	w.mu.Unlock()
+
+	var err error
+	for _, event := range events {
+		if err1 := w.send(event); err1 != nil && err == nil {
+			err = err1
+		}
+		if w.ctx.Err() != nil {
+			break
+		}
+	}
+	if len(events) != 0 {
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+	}
+	return err
+}
+
+// WriteEntry implements Writer.
+func (w *SentryWriter) WriteEntry(e *Entry) (n int, err error) {
+	level := w.Level
+	if level == 0 {
+		level = ErrorLevel
+	}
+	if e.Level < level {
+		return
+	}
+
+	w.init()
+
+	w.mu.Lock()
+	now := timeNow()
+	if now.Sub(w.window) >= time.Minute {
+		w.window = now
+		w.count = 0
+	}
+	limit := w.RateLimit
+	if limit <= 0 {
+		limit = 60
+	}
+	if w.count >= limit || now.Before(w.until) {
+		w.dropped++
+		w.mu.Unlock()
+		return
+	}
+	w.count++
+	w.events = append(w.events, w.event(e))
+	full := len(w.events) >= w.BatchSize || (w.BatchSize <= 0 && len(w.events) >= 100)
+	w.mu.Unlock()
+
+	n = len(e.buf)
+	if full {
+		err = w.Flush()
+	}
+	return
+}
+
+func (w *SentryWriter) init() {
+	w.once.Do(func() {
+		base := w.Context
+		if base == nil {
+			base = context.Background()
+		}
+		w.ctx, w.cancel = context.WithCancel(base)
+		w.done = make(chan struct{})
+		w.closed = make(chan struct{})
+		interval := w.FlushInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			defer close(w.closed)
+			for {
+				select {
+				case <-ticker.C:
+					w.Flush()
+				case <-w.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+// send posts the event in an envelope to Sentry.
+func (w *SentryWriter) send(event []byte) error {
+	u, err := url.Parse(w.DSN)
+	if err != nil {
+		return err
+	}
+	if u.User == nil || len(u.Path) < 2 {
+		return errors.New("log: invalid sentry dsn")
+	}
+	project := u.Path[strings.LastIndexByte(u.Path, '/')+1:]
+	endpoint := u.Scheme + "://" + u.Host + u.Path[:len(u.Path)-len(project)] + "api/" + project + "/envelope/"
+
+	e := &Entry{buf: make([]byte, 0, len(event)+128)}
+	e.buf = append(e.buf, `{"sent_at":"`...)
+	e.buf = timeNow().UTC().AppendFormat(e.buf, time.RFC3339)
+	e.buf = append(e.buf, "\"}\n{\"type\":\"event\",\"length\":"...)
+	e.buf = strconv.AppendInt(e.buf, int64(len(event)), 10)
+	e.buf = append(e.buf, "}\n"...)
+	e.buf = append(e.buf, event...)
+	e.buf = append(e.buf, '\n')
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(e.buf))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(w.ctx)
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=phuslu-log/1.0, sentry_key="+u.User.Username())
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if retry <= 0 {
+			retry = 60
+		}
+		w.mu.Lock()
+		w.until = timeNow().Add(time.Duration(retry) * time.Second)
+		w.mu.Unlock()
+	}
+	if resp.StatusCode/100 != 2 {
+		return errors.New("log: sentry send failed with status " + resp.Status)
+	}
+	return nil
+}
+
+// event converts the entry to a Sentry event in json.
+func (w *SentryWriter) event(e *Entry) []byte {
+	var id [16]byte
+	rand.Read(id[:])
+
+	ev := &Entry{buf: make([]byte, 0, len(e.buf)+256)}
+	ev.buf = append(ev.buf, `{"event_id":"`...)
+	for _, c := range id {
+		ev.buf = append(ev.buf, hex[c>>4], hex[c&0xf])
+	}
+	ev.buf = append(ev.buf, `","platform":"go","level":"`...)
+	switch e.Level {
+	case TraceLevel, DebugLevel:
+		ev.buf = append(ev.buf, "debug"...)
+	case InfoLevel:
+		ev.buf = append(ev.buf, "info"...)
+	case WarnLevel:
+		ev.buf = append(ev.buf, "warning"...)
+	case FatalLevel, PanicLevel:
+		ev.buf = append(ev.buf, "fatal"...)
+	default:
+		ev.buf = append(ev.buf, "error"...)
+	}
+	ev.buf = append(ev.buf, '"')
+	if w.Environment != "" {
+		ev.buf = append(ev.buf, `,"environment":"`...)
+		ev.string(w.Environment)
+		ev.buf = append(ev.buf, '"')
+	}
+	if w.Release != "" {
+		ev.buf = append(ev.buf, `,"release":"`...)
+		ev.string(w.Release)
+		ev.buf = append(ev.buf, '"')
+	}
+
+	tags, extra := bbget(), bbget()
+	defer bbput(tags)
+	defer bbput(extra)
+
+	var errmsg, stack []byte
+	first := true
+	jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if first {
+			first = false
+			if t, ok := jsonParseTime(value, typ); ok {
+				ev.buf = append(ev.buf, `,"timestamp":"`...)
+				ev.buf = t.UTC().AppendFormat(ev.buf, time.RFC3339Nano)
+				ev.buf = append(ev.buf, '"')
+				return
+			}
+		}
+		switch b2s(key) {
+		case "level", "goid":
+			return
+		case "message":
+			ev.buf = append(ev.buf, `,"message":{"formatted":`...)
+			ev.buf = append(ev.buf, value...)
+			ev.buf = append(ev.buf, '}')
+			return
+		case "error":
+			if typ == 's' || typ == 'S' {
+				errmsg = value
+				return
+			}
+		case "stack":
+			if typ == 'S' {
+				stack = jsonUnescape(value[1:len(value)-1], nil)
+				return
+			}
+		}
+		b := extra
+		if typ == 's' || typ == 'S' {
+			b = tags
+		}
+		if len(b.B) != 0 {
+			b.B = append(b.B, ',')
+		}
+		b.B = append(b.B, '"')
+		b.B = append(b.B, key...)
+		b.B = append(b.B, '"', ':')
+		b.B = append(b.B, value...)
+	})
+	if len(tags.B) != 0 {
+		ev.buf = append(ev.buf, `,"tags":{`...)
+		ev.buf = append(ev.buf, tags.B...)
+		ev.buf = append(ev.buf, '}')
+	}
+	if len(extra.B) != 0 {
+		ev.buf = append(ev.buf, `,"extra":{`...)
+		ev.buf = append(ev.buf, extra.B...)
+		ev.buf = append(ev.buf, '}')
+	}
+	if errmsg != nil || stack != nil {
+		ev.buf = append(ev.buf, `,"exception":{"values":[{"type":"error","value":`...)
+		if errmsg != nil {
+			ev.buf = append(ev.buf, errmsg...)
+		} else {
+			ev.buf = append(ev.buf, `""`...)
+		}
+		if stack != nil {
+			ev.buf = append(ev.buf, `,"stacktrace":{"frames":[`...)
+			ev.buf = appendSentryFrames(ev, stack)
+			ev.buf = append(ev.buf, "]}"...)
+		}
+		ev.buf = append(ev.buf, "}]}"...)
+	}
+
+	return append(ev.buf, '}')
+}
+
+// appendSentryFrames parses the goroutine stack in the format of runtime.Stack, and appends
+// the frames to entry in the order of Sentry, i.e. the most recent call last.
+func appendSentryFrames(e *Entry, stack []byte) []byte {
+	lines := strings.Split(b2s(stack), "\n")
+	for i := len(lines) - 1; i > 0; i-- {
+		if !strings.HasPrefix(lines[i], "\t") {
+			continue
+		}
+		function := lines[i-1]
+		if strings.HasPrefix(function, "created by ") {
+			i--
+			continue
+		}
+		if j := strings.LastIndexByte(function, '('); j > 0 {
+			function = function[:j]
+		}
+		filename := strings.TrimPrefix(lines[i], "\t")
+		if j := strings.LastIndex(filename, " +0x"); j > 0 {
+			filename = filename[:j]
+		}
+		lineno := 0
+		if j := strings.LastIndexByte(filename, ':'); j > 0 {
+			lineno, _ = strconv.Atoi(filename[j+1:])
+			filename = filename[:j]
+		}
+		if e.buf[len(e.buf)-1] == '}' {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = append(e.buf, `{"function":"`...)
+		e.string(function)
+		e.buf = append(e.buf, `","filename":"`...)
+		e.string(filename)
+		e.buf = append(e.buf, `","lineno":`...)
+		e.buf = strconv.AppendInt(e.buf, int64(lineno), 10)
+		e.buf = append(e.buf, '}')
+		i--
+	}
+	return e.buf
+}
+
+var _ Writer = (*SentryWriter)(nil)
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSentryWriter(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/42/envelope/" || !strings.Contains(req.Header.Get("X-Sentry-Auth"), "sentry_key=public") {
+			t.Errorf("sentry writer request mismatch: %s %+v", req.URL, req.Header)
+		}
+		b, _ := ioutil.ReadAll(req.Body)
+		bodies <- b
+	}))
+	defer server.Close()
+
+	w := &SentryWriter{
+		DSN:           strings.Replace(server.URL, "http://", "http://public@", 1) + "/42",
+		Environment:   "test",
+		RateLimit:     2,
+		FlushInterval: time.Hour,
+	}
+
+	logger := Logger{Writer: w, ExitFunc: func(int) {}}
+	logger.Info().Msg("hello info")
+	logger.Error().Err(errors.New("boom")).Str("user", "alice").Int("n", 42).Stack().Msg("hello sentry")
+	logger.Fatal().Msg("hello fatal")
+	logger.Error().Msg("hello dropped")
+
+	if n := w.Dropped(); n != 1 {
+		t.Errorf("sentry writer should drop events beyond rate limit: %d", n)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("sentry writer close error: %+v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("sentry writer close again error: %+v", err)
+	}
+
+	var event struct {
+		EventID     string `json:"event_id"`
+		Level       string
+		Timestamp   time.Time
+		Environment string
+		Message     struct{ Formatted string }
+		Tags        map[string]string
+		Extra       map[string]interface{}
+		Exception   struct {
+			Values []struct {
+				Type       string
+				Value      string
+				Stacktrace struct {
+					Frames []struct {
+						Function string
+						Filename string
+						Lineno   int
+					}
+				}
+			}
+		}
+	}
+
+	body := <-bodies
+	parts := bytes.SplitN(body, []byte("\n"), 3)
+	if len(parts) != 3 || !bytes.Contains(parts[1], []byte(`"type":"event"`)) {
+		t.Fatalf("sentry envelope mismatch: %s", body)
+	}
+	if err := json.Unmarshal(parts[2], &event); err != nil {
+		t.Fatalf("sentry event unmarshal error: %+v, %s", err, parts[2])
+	}
+
+	if len(event.EventID) != 32 || event.Level != "error" || event.Environment != "test" || event.Timestamp.IsZero() {
+		t.Errorf("sentry event mismatch: %+v", event)
+	}
+	if event.Message.Formatted != "hello sentry" || event.Tags["user"] != "alice" || event.Extra["n"] != float64(42) {
+		t.Errorf("sentry event fields mismatch: %+v", event)
+	}
+	if values := event.Exception.Values; len(values) != 1 || values[0].Value != "boom" || len(values[0].Stacktrace.Frames) == 0 {
+		t.Fatalf("sentry event exception mismatch: %+v", event.Exception)
+	}
+	frames := event.Exception.Values[0].Stacktrace.Frames
+	if first := frames[0]; first.Function != "testing.tRunner" {
+		t.Errorf("sentry event frames should be the most recent call last: %+v", frames)
+	}
+	found := false
+	for _, frame := range frames {
+		if strings.HasSuffix(frame.Function, "TestSentryWriter") && strings.HasSuffix(frame.Filename, "sentry_test.go") && frame.Lineno != 0 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("sentry event frames mismatch: %+v", frames)
+	}
+
+	body = <-bodies
+	if !bytes.Contains(body, []byte(`"level":"fatal"`)) {
+		t.Errorf("sentry fatal event mismatch: %s", body)
+	}
+}
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// ServiceInfo specifies the field names of the process and build information object, which is
+// pinned to every entry by Logger.Context, e.g.
+//
+//	logger := log.Logger{
+//		Context: log.ServiceInfo{}.Context(),
+//		Writer:  &log.FileWriter{Filename: "main.log"},
+//	}
+//
+// It writes the object as
+//
+//	"service":{"name":"github.com/foo/bar","version":"v1.2.3","revision":"8f0c2e1",
+//	"go_version":"go1.13.8","goos":"linux","goarch":"amd64","pid":1234,"hostname":"web-1"}
+//
+// The module and revision fields are read from runtime/debug.ReadBuildInfo and omitted if absent,
+// the vcs revision is available in the binaries built by Go 1.18 or later.
+type ServiceInfo struct {
+	// Key specifies the key of object, uses "service" if empty.
+	Key string
+
+	// NameField specifies the field name of main module path, uses "name" if empty.
+	NameField string
+
+	// VersionField specifies the field name of main module version, uses "version" if empty.
+	VersionField string
+
+	// RevisionField specifies the field name of vcs revision, uses "revision" if empty.
+	RevisionField string
+
+	// GoVersionField specifies the field name of go version, uses "go_version" if empty.
+	GoVersionField string
+
+	// GOOSField specifies the field name of GOOS, uses "goos" if empty.
+	GOOSField string
+
+	// GOARCHField specifies the field name of GOARCH, uses "goarch" if empty.
+	GOARCHField string
+
+	// PIDField specifies the field name of process id, uses "pid" if empty.
+	PIDField string
+
+	// HostnameField specifies the field name of hostname, uses "hostname" if empty.
+	HostnameField string
+}
+
+// Context returns the contextual field of the information object.
+func (s ServiceInfo) Context() Context {
+	name := func(field, value string) string {
+		if field == "" {
+			return value
+		}
+		return field
+	}
+
+	e := NewContext(nil)
+	start := e.objectStart(name(s.Key, "service"))
+	if info, ok := debug.ReadBuildInfo(); ok {
+		e.StrNonEmpty(name(s.NameField, "name"), info.Main.Path)
+		e.StrNonEmpty(name(s.VersionField, "version"), info.Main.Version)
+		e.StrNonEmpty(name(s.RevisionField, "revision"), buildRevision(info))
+	}
+	e.Str(name(s.GoVersionField, "go_version"), runtime.Version())
+	e.Str(name(s.GOOSField, "goos"), runtime.GOOS)
+	e.Str(name(s.GOARCHField, "goarch"), runtime.GOARCH)
+	e.Int(name(s.PIDField, "pid"), pid)
+	e.Str(name(s.HostnameField, "hostname"), hostname)
+	e.objectEnd(start)
+	return e.Value()
+}
@@ -0,0 +1,24 @@
+// +build go1.18
+
+package log
+
+import (
+	"runtime/debug"
+)
+
+// buildRevision returns the vcs revision of build, with suffix "-dirty" if modified.
+func buildRevision(info *debug.BuildInfo) (revision string) {
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return
+}
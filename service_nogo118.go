@@ -0,0 +1,12 @@
+// +build !go1.18
+
+package log
+
+import (
+	"runtime/debug"
+)
+
+// buildRevision returns empty, the vcs information is not stamped before Go 1.18.
+func buildRevision(info *debug.BuildInfo) string {
+	return ""
+}
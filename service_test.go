@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestServiceInfo(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Context: ServiceInfo{}.Context(), Writer: IOWriter{&buf}}
+	logger.Info().Msg("hello")
+
+	var entry struct {
+		Service map[string]interface{} `json:"service"`
+		Message string                 `json:"message"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("service info should be valid json: %v: %s", err, buf.String())
+	}
+	if entry.Service["goos"] != runtime.GOOS || entry.Service["goarch"] != runtime.GOARCH ||
+		entry.Service["go_version"] != runtime.Version() || entry.Service["hostname"] != hostname ||
+		entry.Service["pid"] != float64(pid) || entry.Message != "hello" {
+		t.Errorf("service info mismatch: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.Context = ServiceInfo{Key: "svc", PIDField: "process_id", HostnameField: "host"}.Context()
+	logger.Info().Msg("")
+	if s := buf.String(); !strings.Contains(s, `"svc":{`) || !strings.Contains(s, `"process_id":`) || !strings.Contains(s, `"host":"`) || strings.Contains(s, `"pid":`) {
+		t.Errorf("service info field names mismatch: %s", s)
+	}
+}
@@ -0,0 +1,166 @@
+package log
+
+import (
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ShardedWriter is an Writer that buffers entries in shards by goroutine, and writes each
+// shard buffer to Writer in one large write when it is full or on FlushInterval. It reduces
+// the contention of a single mutex, e.g. of FileWriter, under heavy concurrency on many cores.
+//
+// The entries of a goroutine keep their order, but the entries of different goroutines may
+// be reordered across shards. Call Flush or Close before the process exits, the entries written
+// after Close are written to Writer directly.
+type ShardedWriter struct {
+	// Writer specifies the writer of the coalesced shard buffers.
+	Writer io.Writer
+
+	// Shards is the number of shards, the default is runtime.GOMAXPROCS(0).
+	Shards int
+
+	// BufferSize is the size of each shard buffer, the default is 32KB.
+	BufferSize int
+
+	// FlushInterval is the interval of flushing shard buffers, the default is 100ms.
+	FlushInterval time.Duration
+
+	once    sync.Once
+	closing sync.Once
+	mu      sync.Mutex
+	shards  []shardBuffer
+	size    int
+	stopped int32
+	done    chan struct{}
+	closed  chan struct{}
+}
+
+type shardBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	_   [64]byte // avoid false sharing
+}
+
+// Close implements io.Closer, flushes the shard buffers and closes the underlying Writer.
+func (w *ShardedWriter) Close() (err error) {
+	w.init()
+	w.closing.Do(func() {
+		unregisterWriter(w)
+		atomic.StoreInt32(&w.stopped, 1)
+		close(w.done)
+		<-w.closed
+		err = w.Flush()
+		if closer, ok := w.Writer.(io.Closer); ok {
+			if err1 := closer.Close(); err == nil {
+				err = err1
+			}
+		}
+	})
+	return
+}
+
+// Flush writes all shard buffers to Writer, and flushes Writer if it is flushable.
+func (w *ShardedWriter) Flush() (err error) {
+	w.init()
+	for i := range w.shards {
+		s := &w.shards[i]
+		s.mu.Lock()
+		if len(s.buf) != 0 {
+			if err1 := w.write(s.buf); err == nil {
+				err = err1
+			}
+			s.buf = s.buf[:0]
+		}
+		s.mu.Unlock()
+	}
+	if f, ok := w.Writer.(interface{ Flush() error }); ok {
+		if err1 := f.Flush(); err == nil {
+			err = err1
+		}
+	}
+	return
+}
+
+// Rotate writes all shard buffers to Writer, and rotates Writer, e.g. FileWriter.
+func (w *ShardedWriter) Rotate() (err error) {
+	err = w.Flush()
+	if r, ok := w.Writer.(interface{ Rotate() error }); ok {
+		if err1 := r.Rotate(); err == nil {
+			err = err1
+		}
+	}
+	return
+}
+
+func (w *ShardedWriter) underlying() interface{} {
+	return w.Writer
+}
+
+// WriteEntry implements Writer.
+func (w *ShardedWriter) WriteEntry(e *Entry) (n int, err error) {
+	w.init()
+
+	s := &w.shards[uint64(Goid())%uint64(len(w.shards))]
+	s.mu.Lock()
+	if atomic.LoadInt32(&w.stopped) != 0 {
+		s.mu.Unlock()
+		return len(e.buf), w.write(e.buf)
+	}
+	s.buf = append(s.buf, e.buf...)
+	if len(s.buf) >= w.size {
+		err = w.write(s.buf)
+		s.buf = s.buf[:0]
+	}
+	s.mu.Unlock()
+
+	return len(e.buf), err
+}
+
+func (w *ShardedWriter) write(b []byte) (err error) {
+	w.mu.Lock()
+	_, err = w.Writer.Write(b)
+	w.mu.Unlock()
+	return
+}
+
+func (w *ShardedWriter) init() {
+	w.once.Do(func() {
+		shards := w.Shards
+		if shards <= 0 {
+			shards = runtime.GOMAXPROCS(0)
+		}
+		w.size = w.BufferSize
+		if w.size <= 0 {
+			w.size = 32 * 1024
+		}
+		w.shards = make([]shardBuffer, shards)
+		for i := range w.shards {
+			w.shards[i].buf = make([]byte, 0, 2*w.size)
+		}
+
+		w.done = make(chan struct{})
+		w.closed = make(chan struct{})
+		interval := w.FlushInterval
+		if interval <= 0 {
+			interval = 100 * time.Millisecond
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			defer close(w.closed)
+			for {
+				select {
+				case <-ticker.C:
+					w.Flush()
+				case <-w.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+var _ Writer = (*ShardedWriter)(nil)
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-sharded")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "sharded.log")
+	w := &ShardedWriter{
+		Writer:     &FileWriter{Filename: filename},
+		Shards:     4,
+		BufferSize: 1024,
+	}
+
+	logger := Logger{Writer: w}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Info().Int("g", i).Int("n", j).Msg("hello sharded")
+			}
+		}(i)
+	}
+	wg.Wait()
+	w.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "sharded.*.log"))
+	if len(matches) != 1 {
+		t.Fatalf("sharded writer files mismatch: %v", matches)
+	}
+	data, _ := ioutil.ReadFile(matches[0])
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	if len(lines) != 800 {
+		t.Fatalf("sharded writer lines mismatch: %d", len(lines))
+	}
+
+	// the entries of a goroutine keep their order
+	next := make(map[string]int)
+	for _, line := range lines {
+		var args FormatterArgs
+		parseFormatterArgs(line, &args)
+		g, n := args.Get("g"), args.Get("n")
+		if strconv.Itoa(next[g]) != n {
+			t.Fatalf("sharded writer order mismatch of goroutine %s: %s", g, line)
+		}
+		next[g]++
+	}
+}
+
+func TestShardedWriterFlushInterval(t *testing.T) {
+	var buf bytes.Buffer
+	var mu sync.Mutex
+	w := &ShardedWriter{
+		Writer:        &testLockedWriter{&mu, &buf},
+		FlushInterval: 10 * time.Millisecond,
+	}
+	defer w.Close()
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello interval")
+
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		n := buf.Len()
+		mu.Unlock()
+		if n != 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("sharded writer should flush on interval")
+}
+
+type testLockedWriter struct {
+	mu  *sync.Mutex
+	buf *bytes.Buffer
+}
+
+func (w *testLockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func BenchmarkShardedWriter(b *testing.B) {
+	w := &ShardedWriter{Writer: ioutil.Discard}
+	defer w.Close()
+	logger := Logger{Writer: w}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello world")
+		}
+	})
+}
+
+func TestShardedWriterClose(t *testing.T) {
+	var buf bytes.Buffer
+	w := &ShardedWriter{
+		Writer: &buf,
+		Shards: 2,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello before close")
+	if err := w.Close(); err != nil {
+		t.Errorf("sharded writer close error: %+v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("sharded writer close again error: %+v", err)
+	}
+
+	logger.Info().Msg("hello after close")
+	if s := buf.String(); !strings.Contains(s, "hello before close") || !strings.Contains(s, "hello after close") {
+		t.Errorf("sharded writer should write the entries after close directly: %s", s)
+	}
+}
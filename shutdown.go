@@ -0,0 +1,187 @@
+package log
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+)
+
+var writers struct {
+	sync.Mutex
+	list []Writer
+}
+
+// RegisterWriter adds the writer to the registry of Flush, Close and CatchSignals. The writers
+// do not register themselves on first use, so the registry never keeps alive the writers which
+// the application has dropped. The wrappers AsyncWriter and ShardedWriter forward Flush and
+// Rotate to the writers they wrap, and a registered writer wrapped by another registered one is
+// rotated once through its wrapper. Register the wrappers after the writers they wrap, e.g.
+//
+//	file := &log.FileWriter{Filename: "main.log"}
+//	async := &log.AsyncWriter{Writer: file}
+//	log.RegisterWriter(file)
+//	log.RegisterWriter(async)
+//
+// so Close closes the wrappers before the writers they wrap. The registered writer is removed
+// on its Close.
+func RegisterWriter(w Writer) {
+	writers.Lock()
+	defer writers.Unlock()
+	for _, x := range writers.list {
+		if x == w {
+			return
+		}
+	}
+	writers.list = append(writers.list, w)
+}
+
+func unregisterWriter(w Writer) {
+	writers.Lock()
+	defer writers.Unlock()
+	for i, x := range writers.list {
+		if x == w {
+			writers.list = append(writers.list[:i], writers.list[i+1:]...)
+			return
+		}
+	}
+}
+
+// Flush drains the queues of async writers and flushes the buffers of the registered writers,
+// in the reverse order of registration.
+func Flush() error {
+	return flushWriters(registeredWriters())
+}
+
+// Close flushes and closes the registered writers in the reverse order of registration, and
+// returns the first error. It is used in main() by
+//
+//	defer log.Close()
+func Close() error {
+	list := registeredWriters()
+	writers.Lock()
+	writers.list = nil
+	writers.Unlock()
+
+	err := flushWriters(list)
+	for _, w := range list {
+		if c, ok := w.(io.Closer); ok {
+			if err1 := c.Close(); err == nil {
+				err = err1
+			}
+		}
+	}
+	return err
+}
+
+// shutdown runs close in background, and calls cancel to abort the in-flight requests of
+// the writer if ctx is done before close returns.
+func shutdown(ctx context.Context, close func() error, cancel context.CancelFunc) error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- close()
+	}()
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// registeredWriters returns the registered writers in the reverse order of registration,
+// so the wrappers registered after their underlying writers are handled first.
+func registeredWriters() []Writer {
+	writers.Lock()
+	defer writers.Unlock()
+	list := make([]Writer, len(writers.list))
+	for i, w := range writers.list {
+		list[len(list)-1-i] = w
+	}
+	return list
+}
+
+func flushWriters(list []Writer) (err error) {
+	for _, w := range list {
+		if f, ok := w.(interface{ WaitFlush() }); ok {
+			f.WaitFlush()
+		}
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err1 := f.Flush(); err == nil {
+				err = err1
+			}
+		}
+	}
+	return
+}
+
+// rotateWriters rotates the registered writers which implement Rotate, e.g. FileWriter.
+func rotateWriters() (err error) {
+	list := registeredWriters()
+	wrapped := make(map[interface{}]bool)
+	for _, w := range list {
+		if u, ok := w.(interface{ underlying() interface{} }); ok {
+			if x := u.underlying(); x != nil && reflect.TypeOf(x).Comparable() {
+				wrapped[x] = true
+			}
+		}
+	}
+	for _, w := range list {
+		if wrapped[w] {
+			continue
+		}
+		if r, ok := w.(interface{ Rotate() error }); ok {
+			if err1 := r.Rotate(); err == nil {
+				err = err1
+			}
+		}
+	}
+	return
+}
+
+// CatchSignals installs signal handlers for the registered writers, so the buffered and async
+// entries are not lost on shutdown and the postrotate "kill -HUP" of logrotate just works. SIGHUP
+// rotates the writers, and the other signals Close the writers then raise the signal again, which
+// terminates the process unless the application handles it too. It catches SIGTERM, SIGINT and
+// SIGHUP if sigs is empty, and returns a function which stops it.
+func CatchSignals(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if sig == syscall.SIGHUP {
+					rotateWriters()
+					continue
+				}
+				Close()
+				signal.Stop(ch)
+				if p, err := os.FindProcess(os.Getpid()); err != nil || p.Signal(sig) != nil {
+					os.Exit(1)
+				}
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type testShutdownWriter struct {
+	name    string
+	order   *[]string
+	flushed int32
+	closed  int32
+	rotated int32
+}
+
+func (w *testShutdownWriter) WriteEntry(e *Entry) (int, error) {
+	return len(e.buf), nil
+}
+
+func (w *testShutdownWriter) Flush() error {
+	atomic.AddInt32(&w.flushed, 1)
+	return nil
+}
+
+func (w *testShutdownWriter) Close() error {
+	atomic.AddInt32(&w.closed, 1)
+	if w.order != nil {
+		*w.order = append(*w.order, w.name)
+	}
+	return errors.New(w.name)
+}
+
+func (w *testShutdownWriter) Rotate() error {
+	atomic.AddInt32(&w.rotated, 1)
+	return nil
+}
+
+func resetWriters() {
+	writers.Lock()
+	writers.list = nil
+	writers.Unlock()
+}
+
+func TestRegisterWriter(t *testing.T) {
+	resetWriters()
+	defer resetWriters()
+
+	var order []string
+	w1 := &testShutdownWriter{name: "file", order: &order}
+	w2 := &testShutdownWriter{name: "console", order: &order}
+	RegisterWriter(w1)
+	RegisterWriter(w2)
+	RegisterWriter(w1)
+
+	if err := rotateWriters(); err != nil || w1.rotated != 1 || w2.rotated != 1 {
+		t.Errorf("rotateWriters mismatch: %v %d %d", err, w1.rotated, w2.rotated)
+	}
+
+	if err := Flush(); err != nil || w1.flushed != 1 || w2.flushed != 1 {
+		t.Errorf("Flush mismatch: %v %d %d", err, w1.flushed, w2.flushed)
+	}
+
+	if err := Close(); err == nil || err.Error() != "console" {
+		t.Errorf("Close should return the first error: %v", err)
+	}
+	if w1.flushed != 2 || w1.closed != 1 || w2.flushed != 2 || w2.closed != 1 {
+		t.Errorf("Close should flush and close writers once: %+v %+v", w1, w2)
+	}
+	if len(order) != 2 || order[0] != "console" || order[1] != "file" {
+		t.Errorf("Close should close writers in the reverse order: %v", order)
+	}
+	if n := len(registeredWriters()); n != 0 {
+		t.Errorf("Close should clear the registered writers, got %d", n)
+	}
+}
+
+func TestCloseDependencyOrder(t *testing.T) {
+	resetWriters()
+	defer resetWriters()
+
+	filename := "file-shutdown.log"
+	defer os.Remove(filename)
+
+	file := &FileWriter{Filename: filename, BufferSize: 4096}
+	async := &AsyncWriter{ChannelSize: 100, Writer: file}
+	other := &testShutdownWriter{name: "other"}
+	RegisterWriter(other)
+	RegisterWriter(file)
+	RegisterWriter(async)
+
+	logger := Logger{Writer: async}
+	for i := 0; i < 10; i++ {
+		logger.Info().Int("i", i).Msg("hello shutdown")
+	}
+
+	list := registeredWriters()
+	if len(list) != 3 || list[0] != Writer(async) || list[1] != Writer(file) || list[2] != Writer(other) {
+		t.Fatalf("writers should be registered explicitly: %v", list)
+	}
+
+	if err := Close(); err == nil || err.Error() != "other" {
+		t.Errorf("Close should return the error of other writer: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("read file error: %+v", err)
+	}
+	if n := strings.Count(string(data), "hello shutdown"); n != 10 {
+		t.Errorf("Close should drain the async writer before closing file, got %d entries", n)
+	}
+}
+
+func TestUnregisterWriter(t *testing.T) {
+	resetWriters()
+	defer resetWriters()
+
+	filename := "file-unregister.log"
+	defer os.Remove(filename)
+
+	file := &FileWriter{Filename: filename}
+	fmt.Fprint(file, "hello file writer!\n")
+	if n := len(registeredWriters()); n != 0 {
+		t.Errorf("file writer should not register itself, got %d", n)
+	}
+
+	RegisterWriter(file)
+	name := file.file.Name()
+	defer os.Remove(name)
+	file.Close()
+	if n := len(registeredWriters()); n != 0 {
+		t.Errorf("file writer should unregister on Close, got %d", n)
+	}
+}
+
+func TestRotateWrappedWriters(t *testing.T) {
+	resetWriters()
+	defer resetWriters()
+
+	w := &testShutdownWriter{name: "file"}
+	async := &AsyncWriter{Writer: w}
+	sharded := &ShardedWriter{Writer: ioutil.Discard}
+	defer async.Close()
+	defer sharded.Close()
+	RegisterWriter(async)
+	RegisterWriter(sharded)
+
+	if err := rotateWriters(); err != nil || w.rotated != 1 {
+		t.Errorf("rotateWriters should rotate through the async writer: %v %d", err, w.rotated)
+	}
+	if err := Flush(); err != nil || w.flushed != 1 {
+		t.Errorf("Flush should flush through the async writer: %v %d", err, w.flushed)
+	}
+
+	RegisterWriter(w)
+	if err := rotateWriters(); err != nil || w.rotated != 2 {
+		t.Errorf("rotateWriters should rotate the wrapped writer once: %v %d", err, w.rotated)
+	}
+}
@@ -0,0 +1,40 @@
+// +build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleLevelSignals installs signal handlers to change the level of logger at runtime,
+// SIGUSR1 increases the verbosity (e.g. info to debug) and SIGUSR2 decreases it.
+// It returns a function which stops the signal handling.
+func (l *Logger) HandleLevelSignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				level := l.GetLevel()
+				switch {
+				case sig == syscall.SIGUSR1 && level > TraceLevel:
+					l.SetLevel(level - 1)
+				case sig == syscall.SIGUSR2 && level < PanicLevel:
+					l.SetLevel(level + 1)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
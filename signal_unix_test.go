@@ -0,0 +1,72 @@
+// +build !windows
+
+package log
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoggerHandleLevelSignals(t *testing.T) {
+	logger := Logger{Level: InfoLevel}
+
+	stop := logger.HandleLevelSignals()
+	defer stop()
+
+	wait := func(level Level) {
+		for i := 0; i < 100 && logger.GetLevel() != level; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if v := logger.GetLevel(); v != level {
+			t.Fatalf("logger level should be %s, not %s", level, v)
+		}
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	wait(DebugLevel)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	wait(InfoLevel)
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	wait(WarnLevel)
+}
+
+func TestCatchSignals(t *testing.T) {
+	resetWriters()
+	defer resetWriters()
+
+	w := &testShutdownWriter{name: "file"}
+	RegisterWriter(w)
+
+	// the application handles SIGTERM too, so the raised SIGTERM does not terminate the test.
+	app := make(chan os.Signal, 2)
+	signal.Notify(app, syscall.SIGTERM)
+	defer signal.Stop(app)
+
+	stop := CatchSignals()
+	defer stop()
+
+	wait := func(p *int32) {
+		for i := 0; i < 100 && atomic.LoadInt32(p) == 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	wait(&w.rotated)
+	if atomic.LoadInt32(&w.rotated) != 1 || atomic.LoadInt32(&w.closed) != 0 {
+		t.Fatalf("SIGHUP should rotate writers: %+v", w)
+	}
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	wait(&w.closed)
+	if atomic.LoadInt32(&w.flushed) != 1 || atomic.LoadInt32(&w.closed) != 1 {
+		t.Fatalf("SIGTERM should flush and close writers: %+v", w)
+	}
+	<-app
+}
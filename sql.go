@@ -0,0 +1,226 @@
+package log
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQLWriter is an Writer that inserts entries in batches into a database table via
+// database/sql, e.g. SQLite or Postgres, for small applications that want queryable logs.
+// The "message" and "caller" fields are stored in their columns, and the other fields
+// except "goid" are stored as a json object in the fields column. The table schema is like
+//
+//	CREATE TABLE logs (
+//	    time    TIMESTAMP NOT NULL,
+//	    level   TEXT NOT NULL,
+//	    message TEXT NOT NULL,
+//	    caller  TEXT NOT NULL,
+//	    fields  JSONB NOT NULL  -- TEXT in SQLite
+//	);
+type SQLWriter struct {
+	// DB is the database handle.
+	DB *sql.DB
+
+	// Table is the table name, the default is "logs".
+	Table string
+
+	// Dialect specifies the placeholder style, "postgres" for $1, otherwise ?.
+	Dialect string
+
+	// BatchSize is the max number of entries in a batch insert, the default size is 100.
+	BatchSize int
+
+	// FlushInterval is the interval of inserting incomplete batches, the default is 1s.
+	FlushInterval time.Duration
+
+	// Context specifies the base context of inserts, the in-flight flushes are aborted when it
+	// is canceled. It uses context.Background() if nil.
+	Context context.Context
+
+	mu      sync.Mutex
+	once    sync.Once
+	closing sync.Once
+	args    []interface{}
+	done    chan struct{}
+	closed  chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	err     error
+}
+
+// Healthy implements HealthChecker, returns the error of last inserting.
+func (w *SQLWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close implements io.Closer, inserts the pending entries and stops the flushing.
+// The DB is not closed.
+func (w *SQLWriter) Close() (err error) {
+	w.init()
+	w.closing.Do(func() {
+		unregisterWriter(w)
+		close(w.done)
+		<-w.closed
+		err = w.Flush()
+		w.cancel()
+	})
+	return
+}
+
+// Shutdown is like Close, but if ctx is done before Close returns, it aborts the in-flight
+// inserting and returns ctx.Err(), so it never blocks the shutdown beyond the deadline of ctx.
+func (w *SQLWriter) Shutdown(ctx context.Context) error {
+	w.init()
+	return shutdown(ctx, w.Close, w.cancel)
+}
+
+// Flush inserts the pending entries.
+func (w *SQLWriter) Flush() error {
+	w.init()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// WriteEntry implements Writer.
+func (w *SQLWriter) WriteEntry(e *Entry) (n int, err error) {
+	w.init()
+
+	var t time.Time
+	var message, caller string
+	fields := bbget()
+	defer bbput(fields)
+	fields.B = append(fields.B, '{')
+
+	first := true
+	jsonObjectEach(e.buf, func(key, value []byte, typ byte) {
+		if first {
+			first = false
+			if t1, ok := jsonParseTime(value, typ); ok {
+				t = t1
+				return
+			}
+		}
+		switch b2s(key) {
+		case "level", "goid":
+			return
+		case "message", "caller":
+			var s string
+			switch typ {
+			case 's':
+				s = string(value[1 : len(value)-1])
+			case 'S':
+				s = string(jsonUnescape(value[1:len(value)-1], nil))
+			default:
+				s = string(value)
+			}
+			if key[0] == 'm' {
+				message = s
+			} else {
+				caller = s
+			}
+			return
+		}
+		if len(fields.B) != 1 {
+			fields.B = append(fields.B, ',')
+		}
+		fields.B = append(fields.B, '"')
+		fields.B = append(fields.B, key...)
+		fields.B = append(fields.B, '"', ':')
+		fields.B = append(fields.B, value...)
+	})
+	fields.B = append(fields.B, '}')
+	if t.IsZero() {
+		t = timeNow()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.args = append(w.args, t.UTC(), e.Level.String(), message, caller, string(fields.B))
+
+	n = len(e.buf)
+	if batch := w.BatchSize; (batch > 0 && len(w.args)/5 >= batch) || (batch <= 0 && len(w.args)/5 >= 100) {
+		err = w.flush()
+	}
+	return
+}
+
+func (w *SQLWriter) init() {
+	w.once.Do(func() {
+		base := w.Context
+		if base == nil {
+			base = context.Background()
+		}
+		w.ctx, w.cancel = context.WithCancel(base)
+		w.done = make(chan struct{})
+		w.closed = make(chan struct{})
+		interval := w.FlushInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			defer close(w.closed)
+			for {
+				select {
+				case <-ticker.C:
+					w.Flush()
+				case <-w.done:
+					return
+				}
+			}
+		}()
+	})
+}
+
+func (w *SQLWriter) flush() (err error) {
+	if len(w.args) == 0 {
+		return nil
+	}
+	defer func() {
+		w.err = err
+	}()
+
+	table := w.Table
+	if table == "" {
+		table = "logs"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(table)
+	sb.WriteString(" (time, level, message, caller, fields) VALUES ")
+	for i := range w.args {
+		switch {
+		case i == 0:
+			sb.WriteString("(")
+		case i%5 == 0:
+			sb.WriteString("), (")
+		default:
+			sb.WriteString(", ")
+		}
+		if w.Dialect == "postgres" {
+			sb.WriteString("$")
+			sb.WriteString(strconv.Itoa(i + 1))
+		} else {
+			sb.WriteString("?")
+		}
+	}
+	sb.WriteString(")")
+
+	args := w.args
+	w.args = w.args[:0]
+
+	_, err = w.DB.ExecContext(w.ctx, sb.String(), args...)
+	return
+}
+
+var _ Writer = (*SQLWriter)(nil)
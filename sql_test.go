@@ -0,0 +1,121 @@
+package log
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testSQLDriver struct {
+	mu    sync.Mutex
+	query []string
+	args  [][]driver.Value
+}
+
+func (d *testSQLDriver) Open(name string) (driver.Conn, error) { return &testSQLConn{d}, nil }
+
+type testSQLConn struct{ d *testSQLDriver }
+
+func (c *testSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &testSQLStmt{c.d, query}, nil
+}
+func (c *testSQLConn) Close() error              { return nil }
+func (c *testSQLConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type testSQLStmt struct {
+	d     *testSQLDriver
+	query string
+}
+
+func (s *testSQLStmt) Close() error  { return nil }
+func (s *testSQLStmt) NumInput() int { return -1 }
+func (s *testSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+func (s *testSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.query = append(s.d.query, s.query)
+	s.d.args = append(s.d.args, args)
+	return driver.RowsAffected(len(args) / 5), nil
+}
+
+func TestSQLWriter(t *testing.T) {
+	d := &testSQLDriver{}
+	sql.Register("testsql", d)
+	db, err := sql.Open("testsql", "")
+	if err != nil {
+		t.Fatalf("sql open error: %+v", err)
+	}
+	defer db.Close()
+
+	w := &SQLWriter{
+		DB:            db,
+		Dialect:       "postgres",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	}
+
+	logger := Logger{Writer: w, Caller: 1}
+	logger.Info().Str("foo", "bar").Int("n", 42).Msg("hello \"sql\"")
+	logger.Warn().Msg("hello batch")
+	logger.Error().Msg("hello close")
+	if err := w.Close(); err != nil {
+		t.Fatalf("sql writer close error: %+v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("sql writer close again error: %+v", err)
+	}
+
+	if len(d.query) != 2 {
+		t.Fatalf("sql writer should insert in batches: %q", d.query)
+	}
+	if d.query[0] != "INSERT INTO logs (time, level, message, caller, fields) VALUES ($1, $2, $3, $4, $5), ($6, $7, $8, $9, $10)" {
+		t.Errorf("sql writer query mismatch: %s", d.query[0])
+	}
+
+	args := d.args[0]
+	if ts, ok := args[0].(time.Time); !ok || time.Since(ts) > time.Minute {
+		t.Errorf("sql writer time mismatch: %v", args[0])
+	}
+	if args[1] != "info" || args[2] != `hello "sql"` || !strings.HasPrefix(args[3].(string), "sql_test.go:") || args[4] != `{"foo":"bar","n":42}` {
+		t.Errorf("sql writer args mismatch: %v", args)
+	}
+	if args := d.args[1]; len(args) != 5 || args[1] != "error" || args[4] != `{}` {
+		t.Errorf("sql writer args mismatch: %v", args)
+	}
+}
+
+func TestSQLWriterContext(t *testing.T) {
+	d := &testSQLDriver{}
+	sql.Register("testsqlctx", d)
+	db, err := sql.Open("testsqlctx", "")
+	if err != nil {
+		t.Fatalf("sql open error: %+v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &SQLWriter{
+		DB:            db,
+		FlushInterval: time.Hour,
+		Context:       ctx,
+	}
+
+	logger := Logger{Writer: w}
+	logger.Info().Msg("hello sql context")
+	cancel()
+
+	if err := w.Shutdown(context.Background()); err != context.Canceled {
+		t.Errorf("sql writer should not insert with the canceled context: %v", err)
+	}
+	if len(d.query) != 0 {
+		t.Errorf("sql writer should abort inserting: %q", d.query)
+	}
+	w.Close()
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// StatsLogger periodically logs an entry of the runtime stats, i.e. goroutines, heap, GC pauses
+// and open file descriptors, for the lightweight deployments without Prometheus. The entries are
+// routed to a named destination of MultiFileWriter by Logger.Name, e.g.
+//
+//	stats := &log.StatsLogger{
+//		Logger:   log.Logger{Name: "stats", Writer: multiFileWriter},
+//		Interval: time.Minute,
+//	}
+//	stats.Start()
+//	defer stats.Close()
+type StatsLogger struct {
+	// Logger specifies the logger which the entries are written to.
+	Logger Logger
+
+	// Interval specifies the interval of entries, the default is 1 minute.
+	Interval time.Duration
+
+	// Level specifies the level of entries, the default is InfoLevel.
+	Level Level
+
+	// Message specifies the message of entries, the default is "runtime stats".
+	Message string
+
+	mu     sync.Mutex
+	once   sync.Once
+	numGC  uint32
+	done   chan struct{}
+	closed chan struct{}
+}
+
+// Start starts logging the stats every Interval in background.
+func (s *StatsLogger) Start() {
+	s.init()
+}
+
+// Close implements io.Closer, stops logging the stats.
+func (s *StatsLogger) Close() error {
+	s.init()
+	s.mu.Lock()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	s.mu.Unlock()
+	<-s.closed
+	return nil
+}
+
+// Log logs an entry of the current runtime stats. The GC fields are counted since the last entry.
+func (s *StatsLogger) Log() {
+	level := s.Level
+	if level == 0 {
+		level = InfoLevel
+	}
+	e := s.Logger.header(level)
+	if e == nil {
+		return
+	}
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	s.mu.Lock()
+	last := s.numGC
+	s.numGC = m.NumGC
+	s.mu.Unlock()
+
+	// the recent pauses are in the circular buffer PauseNs
+	n := m.NumGC - last
+	if n > uint32(len(m.PauseNs)) {
+		n = uint32(len(m.PauseNs))
+	}
+	var pauseMax uint64
+	for i := uint32(0); i < n; i++ {
+		if p := m.PauseNs[(m.NumGC-i+255)%256]; p > pauseMax {
+			pauseMax = p
+		}
+	}
+
+	e.Int("goroutines", runtime.NumGoroutine()).
+		Uint64("heap_alloc", m.HeapAlloc).
+		Uint64("heap_inuse", m.HeapInuse).
+		Uint64("heap_sys", m.HeapSys).
+		Uint64("heap_objects", m.HeapObjects).
+		Uint32("gc_count", m.NumGC-last).
+		Dur("gc_pause_max", time.Duration(pauseMax)).
+		Dur("gc_pause_total", time.Duration(m.PauseTotalNs))
+	if fds, err := ioutil.ReadDir("/proc/self/fd"); err == nil {
+		e.Int("open_fds", len(fds))
+	}
+
+	msg := s.Message
+	if msg == "" {
+		msg = "runtime stats"
+	}
+	e.Msg(msg)
+}
+
+func (s *StatsLogger) init() {
+	s.once.Do(func() {
+		s.done = make(chan struct{})
+		s.closed = make(chan struct{})
+		interval := s.Interval
+		if interval <= 0 {
+			interval = time.Minute
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			defer close(s.closed)
+			for {
+				select {
+				case <-ticker.C:
+					s.Log()
+				case <-s.done:
+					return
+				}
+			}
+		}()
+	})
+}
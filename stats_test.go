@@ -0,0 +1,65 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStatsLogger(t *testing.T) {
+	var buf bytes.Buffer
+	s := &StatsLogger{
+		Logger: Logger{Writer: IOWriter{&buf}},
+	}
+
+	runtime.GC()
+	s.Log()
+	for _, field := range []string{`"level":"info"`, `"goroutines":`, `"heap_alloc":`, `"heap_inuse":`, `"heap_sys":`, `"heap_objects":`, `"gc_count":`, `"gc_pause_max":`, `"gc_pause_total":`, `"message":"runtime stats"`} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("stats logger output should contain %s: %s", field, buf.String())
+		}
+	}
+
+	if s.numGC == 0 {
+		t.Errorf("stats logger should record the gc count of last entry")
+	}
+}
+
+func TestStatsLoggerStart(t *testing.T) {
+	var buf syncBuffer
+	s := &StatsLogger{
+		Logger:   Logger{Writer: IOWriter{&buf}},
+		Interval: 10 * time.Millisecond,
+		Level:    DebugLevel,
+		Message:  "stats",
+	}
+
+	s.Start()
+	time.Sleep(50 * time.Millisecond)
+	s.Close()
+	s.Close()
+
+	if n := strings.Count(buf.String(), `"level":"debug"`); n == 0 || n != strings.Count(buf.String(), `"message":"stats"}`) {
+		t.Errorf("stats logger should log periodically: %s", buf.String())
+	}
+}
@@ -32,3 +32,19 @@ func (w *stdLogWriter) Write(p []byte) (int, error) {
 	e.Context(w.context).Msg(b2s(p))
 	return len(p), nil
 }
+
+// Std returns a *stdLog.Logger which writes to Default() via TextWriter, so that the legacy code
+// using stdlib log.Printf is captured with the level prefixes like "[WARN] " or "ERROR: " parsed,
+// and written to the writer of Default(), e.g. a rotating FileWriter. The text without level prefix
+// is logged at level. The prefix is kept in the message, and the time is added by Default().
+//
+//	stdLog.SetOutput(log.Std(log.InfoLevel, "").Writer())
+//	stdLog.SetFlags(0)
+func Std(level Level, prefix string) *stdLog.Logger {
+	w := &TextWriter{
+		Logger: *Default(),
+		Level:  level,
+		Prefix: prefix,
+	}
+	return stdLog.New(w, prefix, 0)
+}
@@ -1,9 +1,11 @@
 package log
 
 import (
+	"bytes"
 	"fmt"
 	stdLog "log"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -43,3 +45,32 @@ func TestStdLogger(t *testing.T) {
 	stdLog.Println("hello from stdLog Println")
 	stdLog.Printf("hello from stdLog %s", "Printf")
 }
+
+func TestStd(t *testing.T) {
+	var buf bytes.Buffer
+	old := *Default()
+	defer SetDefault(old)
+	SetDefault(Logger{Level: DebugLevel, Writer: IOWriter{&buf}})
+
+	cases := []struct {
+		Prefix  string
+		Text    string
+		Level   string
+		Message string
+	}{
+		{"", "hello std", "info", "hello std"},
+		{"", "[WARN] hello std", "warn", "hello std"},
+		{"app: ", "hello std", "info", "app: hello std"},
+		{"app: ", "ERROR: hello std", "error", "app: hello std"},
+		{"[app] ", "[D] hello std", "debug", "[app] hello std"},
+		{"app: ", "panic: hello std", "error", "app: hello std"},
+	}
+
+	for _, c := range cases {
+		buf.Reset()
+		Std(InfoLevel, c.Prefix).Print(c.Text)
+		if s := buf.String(); !strings.Contains(s, `"level":"`+c.Level+`","message":"`+c.Message+`"}`) {
+			t.Errorf("std output of %#v mismatch: %s", c.Text, s)
+		}
+	}
+}
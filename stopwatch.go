@@ -0,0 +1,40 @@
+package log
+
+import (
+	"time"
+)
+
+// Stopwatch records the elapsed duration since it starts, it uses the monotonic clock so
+// the durations are precise across the wall clock changes, e.g.
+//
+//	sw := log.Since("latency")
+//	handle(req)
+//	log.Info().Elapsed(sw).Msg("request done")
+type Stopwatch struct {
+	key   string
+	start time.Time
+}
+
+// Since starts a Stopwatch whose elapsed duration is logged by Entry.Elapsed with key.
+func Since(key string) Stopwatch {
+	return Stopwatch{key: key, start: timeNow()}
+}
+
+// Elapsed returns the elapsed duration since the stopwatch starts.
+func (s Stopwatch) Elapsed() time.Duration {
+	return timeNow().Sub(s.start)
+}
+
+// Start returns the start time of stopwatch.
+func (s Stopwatch) Start() time.Time {
+	return s.start
+}
+
+// Elapsed adds the field key of stopwatch with the elapsed duration to the entry, the duration
+// format follows the same principle as Dur().
+func (e *Entry) Elapsed(s Stopwatch) *Entry {
+	if e == nil {
+		return nil
+	}
+	return e.Dur(s.key, s.Elapsed())
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStopwatch(t *testing.T) {
+	sw := Since("latency")
+	time.Sleep(20 * time.Millisecond)
+
+	if d := sw.Elapsed(); d < 20*time.Millisecond || d > time.Second {
+		t.Errorf("stopwatch elapsed mismatch: %s", d)
+	}
+	if sw.Start().IsZero() {
+		t.Errorf("stopwatch start should not be zero")
+	}
+
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	logger.Info().Elapsed(sw).Msg("done")
+
+	var entry struct {
+		Latency string `json:"latency"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("stopwatch entry invalid: %+v %s", err, buf.String())
+	}
+	if d, err := time.ParseDuration(entry.Latency); err != nil || d < 20*time.Millisecond {
+		t.Errorf("stopwatch entry mismatch: %s", buf.String())
+	}
+
+	var e *Entry
+	e.Elapsed(sw).Msg("nil entry")
+}
@@ -0,0 +1,169 @@
+package log
+
+import (
+	"runtime"
+)
+
+// SugarLogger implements the printf-style methods of web framework logger
+// interfaces, e.g. fiber log.CommonLogger and echo.Logger. To keep dependency
+// free, the methods with framework types (e.g. SetLevel) are left to a thin wrapper.
+type SugarLogger struct {
+	logger  Logger
+	context Context
+}
+
+// Sugar wraps the Logger to provide a printf-style logger
+func (l *Logger) Sugar(context Context) *SugarLogger {
+	return &SugarLogger{
+		logger:  *l,
+		context: context,
+	}
+}
+
+// Print logs with no level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Print(args ...interface{}) {
+	s.print(noLevel, args)
+}
+
+// Printf logs with no level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Printf(format string, args ...interface{}) {
+	s.printf(noLevel, format, args)
+}
+
+// Trace logs at trace level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Trace(args ...interface{}) {
+	s.print(TraceLevel, args)
+}
+
+// Tracef logs at trace level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Tracef(format string, args ...interface{}) {
+	s.printf(TraceLevel, format, args)
+}
+
+// Tracew logs at trace level with the given key/value pairs as context.
+func (s *SugarLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	s.printw(TraceLevel, msg, keysAndValues)
+}
+
+// Debug logs at debug level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Debug(args ...interface{}) {
+	s.print(DebugLevel, args)
+}
+
+// Debugf logs at debug level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Debugf(format string, args ...interface{}) {
+	s.printf(DebugLevel, format, args)
+}
+
+// Debugw logs at debug level with the given key/value pairs as context.
+func (s *SugarLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	s.printw(DebugLevel, msg, keysAndValues)
+}
+
+// Info logs at info level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Info(args ...interface{}) {
+	s.print(InfoLevel, args)
+}
+
+// Infof logs at info level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Infof(format string, args ...interface{}) {
+	s.printf(InfoLevel, format, args)
+}
+
+// Infow logs at info level with the given key/value pairs as context.
+func (s *SugarLogger) Infow(msg string, keysAndValues ...interface{}) {
+	s.printw(InfoLevel, msg, keysAndValues)
+}
+
+// Warn logs at warn level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Warn(args ...interface{}) {
+	s.print(WarnLevel, args)
+}
+
+// Warnf logs at warn level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Warnf(format string, args ...interface{}) {
+	s.printf(WarnLevel, format, args)
+}
+
+// Warnw logs at warn level with the given key/value pairs as context.
+func (s *SugarLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	s.printw(WarnLevel, msg, keysAndValues)
+}
+
+// Error logs at error level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Error(args ...interface{}) {
+	s.print(ErrorLevel, args)
+}
+
+// Errorf logs at error level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Errorf(format string, args ...interface{}) {
+	s.printf(ErrorLevel, format, args)
+}
+
+// Errorw logs at error level with the given key/value pairs as context.
+func (s *SugarLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	s.printw(ErrorLevel, msg, keysAndValues)
+}
+
+// Fatal logs at fatal level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Fatal(args ...interface{}) {
+	s.print(FatalLevel, args)
+}
+
+// Fatalf logs at fatal level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Fatalf(format string, args ...interface{}) {
+	s.printf(FatalLevel, format, args)
+}
+
+// Fatalw logs at fatal level with the given key/value pairs as context.
+func (s *SugarLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	s.printw(FatalLevel, msg, keysAndValues)
+}
+
+// Panic logs at panic level. Arguments are handled in the manner of fmt.Print.
+func (s *SugarLogger) Panic(args ...interface{}) {
+	s.print(PanicLevel, args)
+}
+
+// Panicf logs at panic level. Arguments are handled in the manner of fmt.Printf.
+func (s *SugarLogger) Panicf(format string, args ...interface{}) {
+	s.printf(PanicLevel, format, args)
+}
+
+// Panicw logs at panic level with the given key/value pairs as context.
+func (s *SugarLogger) Panicw(msg string, keysAndValues ...interface{}) {
+	s.printw(PanicLevel, msg, keysAndValues)
+}
+
+func (s *SugarLogger) print(level Level, args []interface{}) {
+	e := s.logger.header(level)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller + 1))
+	}
+	e.Context(s.context).Msgs(args...)
+}
+
+func (s *SugarLogger) printf(level Level, format string, args []interface{}) {
+	e := s.logger.header(level)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller + 1))
+	}
+	e.Context(s.context).Msgf(format, args...)
+}
+
+func (s *SugarLogger) printw(level Level, msg string, keysAndValues []interface{}) {
+	e := s.logger.header(level)
+	if e == nil {
+		return
+	}
+	if s.logger.Caller > 0 {
+		e.caller(runtime.Caller(s.logger.Caller + 1))
+	}
+	e.Context(s.context).KeysAndValues(keysAndValues...).Msg(msg)
+}
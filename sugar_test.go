@@ -0,0 +1,69 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type fiberCommonLogger interface {
+	Trace(v ...interface{})
+	Debug(v ...interface{})
+	Info(v ...interface{})
+	Warn(v ...interface{})
+	Error(v ...interface{})
+	Fatal(v ...interface{})
+	Panic(v ...interface{})
+	Tracef(format string, v ...interface{})
+	Debugf(format string, v ...interface{})
+	Infof(format string, v ...interface{})
+	Warnf(format string, v ...interface{})
+	Errorf(format string, v ...interface{})
+	Fatalf(format string, v ...interface{})
+	Panicf(format string, v ...interface{})
+	Tracew(msg string, keysAndValues ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Fatalw(msg string, keysAndValues ...interface{})
+	Panicw(msg string, keysAndValues ...interface{})
+}
+
+func TestSugarLogger(t *testing.T) {
+	notTest = false
+
+	var buf bytes.Buffer
+	logger := Logger{
+		Level:  TraceLevel,
+		Caller: 1,
+		Writer: IOWriter{&buf},
+	}
+
+	var sugar fiberCommonLogger = logger.Sugar(NewContext(nil).Str("tag", "hi sugar").Value())
+
+	sugar.Info("hello", " sugar")
+	if s := buf.String(); !strings.Contains(s, `"level":"info","caller":"sugar_test.go:`) || !strings.Contains(s, `"tag":"hi sugar","message":"hello sugar"`) {
+		t.Errorf("sugar logger output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	sugar.Warnf("hello %s", "sugar")
+	if s := buf.String(); !strings.Contains(s, `"level":"warn"`) || !strings.Contains(s, `"message":"hello sugar"`) {
+		t.Errorf("sugar logger printf output mismatch: %s", s)
+	}
+
+	buf.Reset()
+	sugar.Errorw("hello sugar", "foo", "bar", "number", 42)
+	if s := buf.String(); !strings.Contains(s, `"level":"error"`) || !strings.Contains(s, `"foo":"bar","number":42,"message":"hello sugar"`) {
+		t.Errorf("sugar logger printw output mismatch: %s", s)
+	}
+
+	sugar.Trace("hello", "sugar Trace message")
+	sugar.Debugf("hello %s", "sugar Debugf message")
+	sugar.Fatal("hello", "sugar Fatal message")
+	sugar.Fatalw("hello", "sugar", "Fatalw message")
+	sugar.Panicf("hello %s", "sugar Panicf message")
+	logger.Sugar(nil).Print("hello", "sugar Print message")
+	logger.Sugar(nil).Printf("hello %s", "sugar Printf message")
+}
@@ -27,9 +27,23 @@ type SyslogWriter struct {
 	// Dial specifies the dial function for creating TCP/TLS connections.
 	Dial func(network, addr string) (net.Conn, error)
 
+	// DialTimeout specifies the timeout of connecting if Dial is nil, the default is no timeout.
+	DialTimeout time.Duration
+
+	// WriteTimeout specifies the timeout of writing a log, the default is no timeout.
+	WriteTimeout time.Duration
+
 	mu    sync.Mutex
 	conn  net.Conn
 	local bool
+	err   error
+}
+
+// Healthy implements HealthChecker, returns the error of last connecting or writing.
+func (w *SyslogWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
 }
 
 // Close closes a connection to the syslog server.
@@ -54,7 +68,9 @@ func (w *SyslogWriter) connect() (err error) {
 
 	var dial = w.Dial
 	if dial == nil {
-		dial = net.Dial
+		dial = func(network, addr string) (net.Conn, error) {
+			return net.DialTimeout(network, addr, w.DialTimeout)
+		}
 	}
 
 	w.conn, err = dial(w.Network, w.Address)
@@ -82,6 +98,7 @@ func (w *SyslogWriter) WriteEntry(e *Entry) (n int, err error) {
 		if w.conn == nil {
 			err = w.connect()
 			if err != nil {
+				w.err = err
 				w.mu.Unlock()
 				return
 			}
@@ -89,6 +106,91 @@ func (w *SyslogWriter) WriteEntry(e *Entry) (n int, err error) {
 		w.mu.Unlock()
 	}
 
+	e1 := epool.Get().(*Entry)
+	defer putEntry(e1)
+
+	b := w.appendHeader(e1.buf[:0], e)
+	b = append(b, e.buf...)
+	e1.buf = b
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if n, err = w.write(b); err == nil {
+			return
+		}
+	}
+	if err = w.connect(); err != nil {
+		w.err = err
+		return
+	}
+	n, err = w.write(b)
+	w.err = err
+	return
+}
+
+// WriteEntries implements EntryBatchWriter, sends the entries in one writev syscall
+// if the network is a stream, e.g. tcp.
+func (w *SyslogWriter) WriteEntries(entries []*Entry) (n int, err error) {
+	switch w.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+	default:
+		for _, e := range entries {
+			m, err1 := w.WriteEntry(e)
+			n += m
+			if err1 != nil && err == nil {
+				err = err1
+			}
+		}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if err = w.connect(); err != nil {
+			w.err = err
+			return
+		}
+	}
+
+	b := bbget()
+	defer bbput(b)
+
+	// headers are appended to one buffer, and sliced after it stops growing.
+	offsets := make([]int, len(entries)+1)
+	for i, e := range entries {
+		b.B = w.appendHeader(b.B, e)
+		offsets[i+1] = len(b.B)
+	}
+	buffers := make(net.Buffers, 0, 2*len(entries))
+	for i, e := range entries {
+		buffers = append(buffers, b.B[offsets[i]:offsets[i+1]], e.buf)
+	}
+	retry := append(net.Buffers(nil), buffers...)
+
+	var n64 int64
+	if w.WriteTimeout > 0 {
+		w.conn.SetWriteDeadline(timeNow().Add(w.WriteTimeout))
+	}
+	if n64, err = buffers.WriteTo(w.conn); err != nil {
+		if err = w.connect(); err != nil {
+			w.err = err
+			return
+		}
+		if w.WriteTimeout > 0 {
+			w.conn.SetWriteDeadline(timeNow().Add(w.WriteTimeout))
+		}
+		n64, err = retry.WriteTo(w.conn)
+	}
+	w.err = err
+	return int(n64), err
+}
+
+// appendHeader appends the syslog header of entry to b, i.e. <PRI>TIMESTAMP HOSTNAME TAG[PID]: MARKER
+func (w *SyslogWriter) appendHeader(b []byte, e *Entry) []byte {
 	// convert level to syslog priority
 	var priority byte
 	switch e.Level {
@@ -108,13 +210,13 @@ func (w *SyslogWriter) WriteEntry(e *Entry) (n int, err error) {
 		priority = '1' // LOG_ALERT
 	default:
 		priority = '6' // LOG_INFO
+		if info, ok := customLevel(e.Level); ok {
+			priority = byte('0' + info.SyslogSeverity)
+		}
 	}
 
-	e1 := epool.Get().(*Entry)
-	defer epool.Put(e1)
-
 	// <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
-	b := append(e1.buf[:0], '<', priority, '>')
+	b = append(b, '<', priority, '>')
 	if w.local {
 		// Compared to the network form below, the changes are:
 		//	1. Use time.Stamp instead of time.RFC3339.
@@ -131,19 +233,12 @@ func (w *SyslogWriter) WriteEntry(e *Entry) (n int, err error) {
 	b = strconv.AppendInt(b, int64(pid), 10)
 	b = append(b, ']', ':', ' ')
 	b = append(b, w.Marker...)
-	b = append(b, e.buf...)
-	e1.buf = b
-
-	w.mu.Lock()
-	defer w.mu.Unlock()
+	return b
+}
 
-	if w.conn != nil {
-		if n, err := w.conn.Write(b); err == nil {
-			return n, err
-		}
-	}
-	if err := w.connect(); err != nil {
-		return 0, err
+func (w *SyslogWriter) write(b []byte) (int, error) {
+	if w.WriteTimeout > 0 {
+		w.conn.SetWriteDeadline(timeNow().Add(w.WriteTimeout))
 	}
 	return w.conn.Write(b)
 }
@@ -1,8 +1,10 @@
 package log
 
 import (
+	"io/ioutil"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -111,3 +113,70 @@ func TestSyslogWriterUnix(t *testing.T) {
 	_, err = wlprintf(w, InfoLevel, "a long long long long message again.\n")
 	t.Logf("write syslog writer error: %+v", err)
 }
+
+func TestSyslogWriterHealthy(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %+v", err)
+	}
+	addr := ln.Addr().String()
+
+	w := &SyslogWriter{
+		Network:      "tcp",
+		Address:      addr,
+		DialTimeout:  time.Second,
+		WriteTimeout: time.Second,
+	}
+
+	var checker HealthChecker = w
+	if _, err := wlprintf(w, InfoLevel, "hello syslog healthy\n"); err != nil || checker.Healthy() != nil {
+		t.Errorf("syslog writer should be healthy: %+v, %+v", err, checker.Healthy())
+	}
+
+	w.Close()
+	ln.Close()
+
+	wlprintf(w, InfoLevel, "hello syslog unhealthy\n")
+	if checker.Healthy() == nil {
+		t.Errorf("syslog writer should be unhealthy")
+	}
+}
+
+func TestSyslogWriterWriteEntries(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen error: %+v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		data, _ := ioutil.ReadAll(conn)
+		received <- string(data)
+	}()
+
+	w := &SyslogWriter{
+		Network: "tcp",
+		Address: ln.Addr().String(),
+		Tag:     "entries",
+		Dial:    net.Dial,
+	}
+	n, err := w.WriteEntries([]*Entry{
+		{Level: InfoLevel, buf: []byte("hello syslog 1\n")},
+		{Level: ErrorLevel, buf: []byte("hello syslog 2\n")},
+	})
+	if err != nil || n == 0 {
+		t.Fatalf("syslog writer write entries error: %d, %+v", n, err)
+	}
+	w.Close()
+
+	data := <-received
+	if len(data) != n || !strings.Contains(data, "<6>") || !strings.Contains(data, "entries[") ||
+		!strings.Contains(data, "hello syslog 1\n<3>") {
+		t.Errorf("syslog writer write entries mismatch: %q", data)
+	}
+}
@@ -0,0 +1,167 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// TailEntry is an entry read by Tail.
+type TailEntry struct {
+	// Args is the parsed fields of entry.
+	Args FormatterArgs
+
+	// JSON is the json of entry.
+	JSON []byte
+}
+
+// Tail follows a log file across rotations, and yields the appended entries, e.g.
+//
+//	t := &log.Tail{Filename: "/var/log/app/main.log"}
+//	defer t.Close()
+//	for entry := range t.Entries() {
+//		fmt.Printf("%s", entry.JSON)
+//	}
+//
+// Rotations are detected by checking whether Filename still refers to the opened file,
+// so it works for both symlink and rename rotations of FileWriter.
+type Tail struct {
+	// Filename is the log file to follow.
+	Filename string
+
+	// PollInterval specifies the interval of polling file changes, the default is 200ms.
+	PollInterval time.Duration
+
+	// FromStart determines if reading the existing entries of file, instead of only the new ones.
+	FromStart bool
+
+	once sync.Once
+	ch   chan *TailEntry
+	done chan struct{}
+	err  error
+}
+
+// Entries starts following and returns the channel of entries, the channel is closed by Close.
+func (t *Tail) Entries() <-chan *TailEntry {
+	t.once.Do(func() {
+		t.ch = make(chan *TailEntry, 64)
+		t.done = make(chan struct{})
+		go t.follow()
+	})
+	return t.ch
+}
+
+// Close stops following.
+func (t *Tail) Close() error {
+	t.Entries()
+	select {
+	case <-t.done:
+	default:
+		close(t.done)
+	}
+	return nil
+}
+
+func (t *Tail) follow() {
+	defer close(t.ch)
+
+	interval := t.PollInterval
+	if interval <= 0 {
+		interval = 200 * time.Millisecond
+	}
+
+	var file *os.File
+	var reader *bufio.Reader
+	var partial []byte
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	first := true
+	for {
+		if file == nil {
+			var err error
+			file, err = os.Open(t.Filename)
+			if err == nil {
+				if first && !t.FromStart {
+					file.Seek(0, io.SeekEnd)
+				}
+				reader = bufio.NewReader(file)
+				partial = partial[:0]
+			}
+			first = false
+		}
+
+		if file != nil {
+			for {
+				line, err := reader.ReadBytes('\n')
+				if err != nil {
+					partial = append(partial, line...)
+					break
+				}
+				if len(partial) != 0 {
+					line = append(partial, line...)
+					partial = partial[:0]
+				}
+				if !t.send(line) {
+					return
+				}
+			}
+		}
+
+		select {
+		case <-t.done:
+			return
+		case <-time.After(interval):
+		}
+
+		if file == nil {
+			continue
+		}
+
+		fi1, err1 := file.Stat()
+		fi2, err2 := os.Stat(t.Filename)
+		switch {
+		case err1 != nil || err2 != nil || !os.SameFile(fi1, fi2):
+			// rotated, reads the rest of the old file then reopens.
+			for {
+				line, err := reader.ReadBytes('\n')
+				partial = append(partial, line...)
+				if err != nil {
+					break
+				}
+				if !t.send(partial) {
+					return
+				}
+				partial = partial[:0]
+			}
+			file.Close()
+			file = nil
+		default:
+			if offset, err := file.Seek(0, io.SeekCurrent); err == nil && fi1.Size() < offset-int64(reader.Buffered()) {
+				// truncated
+				file.Seek(0, io.SeekStart)
+				reader.Reset(file)
+				partial = partial[:0]
+			}
+		}
+	}
+}
+
+func (t *Tail) send(line []byte) bool {
+	if len(line) == 0 || line[0] != '{' {
+		return true
+	}
+	entry := &TailEntry{JSON: append(make([]byte, 0, len(line)), line...)}
+	parseFormatterArgs(append(make([]byte, 0, len(line)), line...), &entry.Args)
+	select {
+	case t.ch <- entry:
+		return true
+	case <-t.done:
+		return false
+	}
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-tail")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, strategy := range []RotateStrategy{RotateSymlink, RotateRename} {
+		filename := filepath.Join(dir, "main.log")
+
+		w := &FileWriter{
+			Filename:   filename,
+			Strategy:   strategy,
+			MaxBackups: 10,
+		}
+		logger := Logger{Writer: w}
+		logger.Info().Msg("before tail")
+
+		tail := &Tail{Filename: filename, PollInterval: 10 * time.Millisecond}
+		entries := tail.Entries()
+		time.Sleep(50 * time.Millisecond)
+
+		logger.Info().Msg("hello 1")
+		time.Sleep(30 * time.Millisecond)
+		w.Rotate()
+		logger.Info().Msg("hello 2")
+
+		for _, expected := range []string{"hello 1", "hello 2"} {
+			select {
+			case entry := <-entries:
+				if entry.Args.Message != expected || entry.Args.Level != "info" || len(entry.JSON) == 0 {
+					t.Errorf("tail entry mismatch: %+v, expected %s", entry.Args, expected)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("tail timeout on strategy %d, expected %s", strategy, expected)
+			}
+		}
+
+		tail.Close()
+		tail.Close()
+		w.Close()
+
+		if _, ok := <-entries; ok {
+			t.Errorf("tail entries should be closed")
+		}
+	}
+}
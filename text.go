@@ -0,0 +1,126 @@
+package log
+
+import (
+	"io"
+	"regexp"
+	"runtime"
+)
+
+// TextWriter is an io.Writer that parses the level of plain text and writes it as
+// a leveled entry to Logger, e.g. for stdlib log, http.Server.ErrorLog and third-party
+// libraries writing plain text.
+type TextWriter struct {
+	// Logger specifies the logger which the entries are written to.
+	Logger Logger
+
+	// Level specifies the level of text which has no level parsed.
+	Level Level
+
+	// Context specifies the contextual fields of entries.
+	Context Context
+
+	// LevelRegexp specifies an optional regexp to parse the level of text, the first
+	// submatch is parsed by ParseLevel and the matched text is removed from message.
+	// If empty, the level prefix like "[WARN] ", "ERROR: " or "info " is parsed.
+	// The parsed fatal and panic levels are written as error, so the third-party text can not
+	// exit or panic the process.
+	LevelRegexp *regexp.Regexp
+
+	// Prefix specifies the prefix of text which is skipped when parsing the level prefix,
+	// e.g. the prefix of stdlib log.Logger. It is kept in the message.
+	Prefix string
+}
+
+// Write implements io.Writer.
+func (w *TextWriter) Write(p []byte) (int, error) {
+	level, prefix, msg := w.parse(p)
+
+	e := w.Logger.header(level)
+	if e == nil {
+		return len(p), nil
+	}
+	if w.Logger.Caller > 0 {
+		e.caller(runtime.Caller(w.Logger.Caller + 2))
+	}
+	if prefix != "" {
+		b := bbget()
+		b.B = append(append(b.B, prefix...), msg...)
+		e.Context(w.Context).Msg(b2s(b.B))
+		bbput(b)
+		return len(p), nil
+	}
+	e.Context(w.Context).Msg(b2s(msg))
+	return len(p), nil
+}
+
+// parse returns the level and message of text, the prefix is returned if it is split from message.
+func (w *TextWriter) parse(p []byte) (level Level, prefix string, msg []byte) {
+	level, msg = w.Level, p
+	if n := len(msg); n > 0 && msg[n-1] == '\n' {
+		msg = msg[:n-1]
+	}
+
+	if w.LevelRegexp != nil {
+		m := w.LevelRegexp.FindSubmatchIndex(msg)
+		if len(m) < 4 || m[2] < 0 {
+			return
+		}
+		if l := ParseLevel(b2s(msg[m[2]:m[3]])); l != noLevel {
+			level = textLevel(l)
+			b := make([]byte, 0, len(msg)-(m[1]-m[0]))
+			b = append(b, msg[:m[0]]...)
+			msg = append(b, msg[m[1]:]...)
+		}
+		return
+	}
+
+	// [LEVEL] message, LEVEL: message or LEVEL message
+	i := 0
+	if w.Prefix != "" && len(msg) >= len(w.Prefix) && b2s(msg[:len(w.Prefix)]) == w.Prefix {
+		i = len(w.Prefix)
+	}
+	n := i
+	for i < len(msg) && msg[i] == ' ' {
+		i++
+	}
+	bracket := i < len(msg) && msg[i] == '['
+	if bracket {
+		i++
+	}
+	j := i
+	for j < len(msg) && j-i <= 7 && (msg[j]|0x20 >= 'a' && msg[j]|0x20 <= 'z') {
+		j++
+	}
+	if j == i || j == len(msg) {
+		return
+	}
+	l := ParseLevel(b2s(msg[i:j]))
+	if l == noLevel {
+		return
+	}
+	switch {
+	case j-i == 1 && !bracket:
+		// the single letter levels are ambiguous, e.g. "I am done", so they must be in brackets.
+		return
+	case bracket && msg[j] == ']':
+		j++
+	case !bracket && (msg[j] == ':' || msg[j] == ' '):
+		j++
+	default:
+		return
+	}
+	for j < len(msg) && msg[j] == ' ' {
+		j++
+	}
+	return textLevel(l), w.Prefix[:n], msg[j:]
+}
+
+// textLevel caps the parsed level at ErrorLevel, to avoid the exit or panic of Entry.Msg.
+func textLevel(level Level) Level {
+	if level == FatalLevel || level == PanicLevel {
+		return ErrorLevel
+	}
+	return level
+}
+
+var _ io.Writer = (*TextWriter)(nil)
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	stdLog "log"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTextWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &TextWriter{
+		Logger: Logger{
+			Level:    DebugLevel,
+			Writer:   IOWriter{&buf},
+			ExitFunc: func(int) { t.Errorf("text writer should not exit") },
+		},
+		Level:   InfoLevel,
+		Context: NewContext(nil).Str("tag", "text").Value(),
+	}
+
+	cases := []struct {
+		Text    string
+		Level   string
+		Message string
+	}{
+		{"hello text writer\n", "info", "hello text writer"},
+		{"[WARN] hello text writer\n", "warn", "hello text writer"},
+		{"ERROR: hello text writer", "error", "hello text writer"},
+		{"debug hello text writer", "debug", "hello text writer"},
+		{"trace hello text writer", "", ""},
+		{"[infos] hello text writer", "info", "[infos] hello text writer"},
+		{"information: hello text writer", "info", "information: hello text writer"},
+		{"[W] hello text writer", "warn", "hello text writer"},
+		{"I am a text writer", "info", "I am a text writer"},
+		{"fatal: hello text writer", "error", "hello text writer"},
+		{"FATAL hello text writer", "error", "hello text writer"},
+		{"[PANIC] hello text writer", "error", "hello text writer"},
+		{"panic: hello text writer", "error", "hello text writer"},
+	}
+
+	for _, c := range cases {
+		buf.Reset()
+		fmt.Fprint(w, c.Text)
+		if c.Level == "" {
+			if buf.Len() != 0 {
+				t.Errorf("text writer should discard %#v: %s", c.Text, buf.String())
+			}
+			continue
+		}
+		if s := buf.String(); !strings.Contains(s, `"level":"`+c.Level+`","tag":"text","message":"`+c.Message+`"}`) {
+			t.Errorf("text writer output of %#v mismatch: %s", c.Text, s)
+		}
+	}
+}
+
+func TestTextWriterRegexp(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &TextWriter{
+		Logger: Logger{
+			Writer: IOWriter{&buf},
+		},
+		Level:       InfoLevel,
+		LevelRegexp: regexp.MustCompile(`level=(\w+) `),
+	}
+
+	stdLog.New(w, "", 0).Print("http: level=error TLS handshake error")
+	if s := buf.String(); !strings.Contains(s, `"level":"error","message":"http: TLS handshake error"`) {
+		t.Errorf("text writer regexp output mismatch: %s", s)
+	}
+}
+
+func TestTextWriterFatalRegexp(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := &TextWriter{
+		Logger: Logger{
+			Writer:   IOWriter{&buf},
+			ExitFunc: func(int) { t.Errorf("text writer should not exit") },
+		},
+		LevelRegexp: regexp.MustCompile(`level=(\w+) `),
+	}
+
+	stdLog.New(w, "", 0).Print("level=fatal boom")
+	if s := buf.String(); !strings.Contains(s, `"level":"error","message":"boom"`) {
+		t.Errorf("text writer regexp output mismatch: %s", s)
+	}
+}
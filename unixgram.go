@@ -0,0 +1,111 @@
+package log
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// UnixgramFraming specifies the framing of entries in datagrams of UnixgramWriter.
+type UnixgramFraming int
+
+const (
+	// FramingNewline sends an entry with the trailing newline per datagram.
+	FramingNewline UnixgramFraming = iota
+	// FramingNone sends an entry without the trailing newline per datagram.
+	FramingNone
+	// FramingOctetCounting prefixes an entry without the trailing newline by its length
+	// and a space, as RFC 6587 octet counting.
+	FramingOctetCounting
+)
+
+// UnixgramWriter is an Writer that sends entries to a unix datagram socket, e.g. the unix
+// input of sidecar collectors. An Address starting with '@' is in the Linux abstract namespace.
+type UnixgramWriter struct {
+	// Address specifies the path of the socket, e.g. /run/collector.sock or @collector
+	Address string
+
+	// Framing specifies the framing of entries in datagrams, the default is FramingNewline.
+	Framing UnixgramFraming
+
+	// WriteTimeout specifies the timeout of writing a log, the default is no timeout.
+	WriteTimeout time.Duration
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+	err  error
+}
+
+// Healthy implements HealthChecker, returns the error of last connecting or writing.
+func (w *UnixgramWriter) Healthy() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// Close closes the socket.
+func (w *UnixgramWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	return
+}
+
+// connect connects the socket to Address.
+func (w *UnixgramWriter) connect() (err error) {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	w.conn, err = net.DialUnix("unixgram", nil, &net.UnixAddr{Name: w.Address, Net: "unixgram"})
+	if err != nil {
+		w.conn = nil
+	}
+	return
+}
+
+// WriteEntry implements Writer.
+func (w *UnixgramWriter) WriteEntry(e *Entry) (n int, err error) {
+	p := e.buf
+	if w.Framing != FramingNewline && len(p) != 0 && p[len(p)-1] == '\n' {
+		p = p[:len(p)-1]
+	}
+	if w.Framing == FramingOctetCounting {
+		b := bbget()
+		defer bbput(b)
+		b.B = strconv.AppendInt(b.B, int64(len(p)), 10)
+		b.B = append(b.B, ' ')
+		b.B = append(b.B, p...)
+		p = b.B
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if n, err = w.write(p); err == nil {
+			return
+		}
+	}
+	if err = w.connect(); err != nil {
+		w.err = err
+		return
+	}
+	n, err = w.write(p)
+	w.err = err
+	return
+}
+
+func (w *UnixgramWriter) write(b []byte) (int, error) {
+	if w.WriteTimeout > 0 {
+		w.conn.SetWriteDeadline(timeNow().Add(w.WriteTimeout))
+	}
+	return w.conn.Write(b)
+}
+
+var _ Writer = (*UnixgramWriter)(nil)
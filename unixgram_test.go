@@ -0,0 +1,69 @@
+package log
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnixgramWriter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram is not supported on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "log-unixgram")
+	if err != nil {
+		t.Fatalf("temp dir error: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	addresses := []string{filepath.Join(dir, "collector.sock")}
+	if runtime.GOOS == "linux" {
+		addresses = append(addresses, "@phuslu-log-test-"+strconv.Itoa(os.Getpid()))
+	}
+
+	for _, address := range addresses {
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: address, Net: "unixgram"})
+		if err != nil {
+			t.Fatalf("listen unixgram %s error: %+v", address, err)
+		}
+
+		for _, c := range []struct {
+			Framing UnixgramFraming
+			Prefix  string
+			Suffix  string
+		}{
+			{FramingNewline, `{"time":`, "\"hello unixgram\"}\n"},
+			{FramingNone, `{"time":`, "\"hello unixgram\"}"},
+			{FramingOctetCounting, ` {"time":`, "\"hello unixgram\"}"},
+		} {
+			w := &UnixgramWriter{Address: address, Framing: c.Framing}
+			logger := Logger{Writer: w}
+			logger.Info().Msg("hello unixgram")
+			w.Close()
+
+			buf := make([]byte, 4096)
+			n, err := conn.Read(buf)
+			if err != nil {
+				t.Fatalf("read unixgram error: %+v", err)
+			}
+			s := string(buf[:n])
+			if c.Framing == FramingOctetCounting {
+				i := strings.IndexByte(s, ' ')
+				if size, _ := strconv.Atoi(s[:i]); size != n-i-1 {
+					t.Errorf("unixgram octet counting mismatch: %q", s)
+				}
+				s = s[i:]
+			}
+			if !strings.HasPrefix(s, c.Prefix) || !strings.HasSuffix(s, c.Suffix) {
+				t.Errorf("unixgram writer output mismatch: %q", s)
+			}
+		}
+		conn.Close()
+	}
+}
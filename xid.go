@@ -12,7 +12,9 @@ type XID [12]byte
 
 var nilXID XID
 
-// NewXID generates a globally unique XID
+// NewXID generates a globally unique XID, e.g. for request ids. XIDs are sortable by time
+// and collision-resistant across hosts, it consists of the unix seconds, 3 bytes of the
+// machine id, the pid and a counter starting at random.
 func NewXID() XID {
 	sec, _ := walltime()
 	return NewXIDWithTime(sec)
@@ -42,7 +44,7 @@ func NewXIDWithTime(timestamp int64) (x XID) {
 
 // Time returns the timestamp part of the id.
 func (x XID) Time() time.Time {
-	return time.Unix(int64(x[0])<<32|int64(x[1])<<16|int64(x[2])<<8|int64(x[3]), 0)
+	return time.Unix(int64(x[0])<<24|int64(x[1])<<16|int64(x[2])<<8|int64(x[3]), 0)
 }
 
 // Machine returns the 3-byte machine id part of the id.
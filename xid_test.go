@@ -1,8 +1,10 @@
 package log
 
 import (
+	"bytes"
 	"encoding"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -50,6 +52,33 @@ func TestXIDTime(t *testing.T) {
 	}
 }
 
+func TestXIDTimeNow(t *testing.T) {
+	now := time.Now()
+	if d := NewXID().Time().Sub(now); d < -time.Second || d > time.Second {
+		t.Errorf("XID.Time mismatch: %s", d)
+	}
+	if ts := NewXIDWithTime(0xfedcba98).Time().Unix(); ts != 0xfedcba98 {
+		t.Errorf("XID.Time mismatch: %x", ts)
+	}
+}
+
+func TestEntryXid(t *testing.T) {
+	var buf bytes.Buffer
+	logger := Logger{Writer: IOWriter{&buf}}
+	x := NewXID()
+	logger.Info().Xid("request_id", x).Msg("")
+
+	if s := buf.String(); !strings.Contains(s, `"request_id":"`+x.String()+`"`) {
+		t.Errorf("Entry.Xid mismatch: %s", s)
+	}
+	if n := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		logger.Info().Xid("request_id", x).Msg("")
+	}); n != 0 {
+		t.Errorf("Entry.Xid should not allocate: %v", n)
+	}
+}
+
 func TestXIDMarshalJSON(t *testing.T) {
 	s := struct {
 		XID XID `json:"id"`